@@ -3,21 +3,61 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type AppConfig struct {
-	SimServiceAPIKey     string
-	SimUrlDefault        string
-	AppPort              string
-	JWTSecretKey         string
-	AccessTokenDuration  time.Duration
-	RefreshTokenDuration time.Duration
-	DatabaseURL          string
-	RedisURL             string
-	RedisPassword        string
+	SimServiceAPIKey      string
+	SimUrlDefault         string
+	AppPort               string
+	JWTSecretKey          string
+	JWTKeys               map[string]string
+	JWTActiveKeyId        string
+	JWTIssuer             string
+	JWTAudience           string
+	AccessTokenDuration   time.Duration
+	RefreshTokenDuration  time.Duration
+	DatabaseURL           string
+	RedisURL              string
+	RedisPassword         string
+	ExchangeRateSource    string
+	ExchangeRateAPIURL    string
+	ExchangeRateFile      string
+	ExchangeRateTTL       time.Duration
+	CatalogSyncInterval   time.Duration
+	CatalogSyncLockTTL    time.Duration
+	CacheProductTTL       time.Duration
+	StockHistoryRetention time.Duration
+	LiveStockInterval     time.Duration
+	AppBaseURL            string
+	MailDriver            string
+	MailFrom              string
+	MailOutputDir         string
+	PasswordResetTTL      time.Duration
+	PasswordResetLimit    int
+	PasswordResetWindow   time.Duration
+	EmailVerificationTTL  time.Duration
+	VerificationLimit     int
+	VerificationWindow    time.Duration
+	APIKeyRateLimit       int
+	APIKeyMaxRateLimit    int
+	APIKeyRateWindow      time.Duration
+	APIKeyMaxPerUser      int
+	WebhookMaxPerUser     int
+	WebhookTimeout        time.Duration
+	WebhookMaxAttempts    int
+	WebhookRetryBackoff   time.Duration
+	WebhookRetryInterval  time.Duration
+	WebhookAllowHTTP      bool
+	OrderRecheckInterval  time.Duration
+	OrderRecheckLockTTL   time.Duration
+	BulkOrderMaxItems     int
+	BulkOrderMaxQuantity  int
+	BulkOrderWorkers      int
 }
 
 func LoadConfig() *AppConfig {
@@ -34,6 +74,58 @@ func LoadConfig() *AppConfig {
 		DatabaseURL:      os.Getenv("DATABASE_URL"),
 		RedisURL:         os.Getenv("REDIS_URL"),
 		RedisPassword:    os.Getenv("REDIS_PASSWORD"),
+
+		JWTKeys:              parseJWTKeys(os.Getenv("JWT_KEYS")),
+		JWTActiveKeyId:       os.Getenv("JWT_ACTIVE_KID"),
+		JWTIssuer:            getEnv("JWT_ISSUER", "sim-service"),
+		JWTAudience:          getEnv("JWT_AUDIENCE", "sim-service-api"),
+		AccessTokenDuration:  getEnvDuration("ACCESS_TOKEN_DURATION", 15*time.Minute),
+		RefreshTokenDuration: getEnvDuration("REFRESH_TOKEN_DURATION", 7*24*time.Hour),
+
+		ExchangeRateSource: os.Getenv("EXCHANGE_RATE_SOURCE"),
+		ExchangeRateAPIURL: os.Getenv("EXCHANGE_RATE_API_URL"),
+		ExchangeRateFile:   os.Getenv("EXCHANGE_RATE_FILE"),
+		ExchangeRateTTL:    getEnvDuration("EXCHANGE_RATE_TTL", time.Hour),
+
+		CatalogSyncInterval: getEnvDuration("CATALOG_SYNC_INTERVAL", time.Hour),
+		CatalogSyncLockTTL:  getEnvDuration("CATALOG_SYNC_LOCK_TTL", 10*time.Minute),
+
+		CacheProductTTL:       getEnvDuration("CACHE_PRODUCT_TTL", 5*time.Minute),
+		StockHistoryRetention: getEnvDuration("STOCK_HISTORY_RETENTION", 7*24*time.Hour),
+		LiveStockInterval:     getEnvDuration("LIVE_STOCK_INTERVAL", 15*time.Minute),
+
+		AppBaseURL:    getEnv("APP_BASE_URL", "http://localhost:3000"),
+		MailDriver:    getEnv("MAIL_DRIVER", "log"),
+		MailFrom:      getEnv("MAIL_FROM", "no-reply@sim-service.local"),
+		MailOutputDir: getEnv("MAIL_OUTPUT_DIR", "storage/mail"),
+
+		PasswordResetTTL:    getEnvDuration("PASSWORD_RESET_TTL", time.Hour),
+		PasswordResetLimit:  getEnvInt("PASSWORD_RESET_LIMIT", 3),
+		PasswordResetWindow: getEnvDuration("PASSWORD_RESET_WINDOW", time.Hour),
+
+		EmailVerificationTTL: getEnvDuration("EMAIL_VERIFICATION_TTL", 24*time.Hour),
+		VerificationLimit:    getEnvInt("EMAIL_VERIFICATION_LIMIT", 3),
+		VerificationWindow:   getEnvDuration("EMAIL_VERIFICATION_WINDOW", time.Hour),
+
+		APIKeyRateLimit:    getEnvInt("API_KEY_RATE_LIMIT", 60),
+		APIKeyMaxRateLimit: getEnvInt("API_KEY_MAX_RATE_LIMIT", 600),
+		APIKeyRateWindow:   getEnvDuration("API_KEY_RATE_WINDOW", time.Minute),
+		APIKeyMaxPerUser:   getEnvInt("API_KEY_MAX_PER_USER", 10),
+
+		WebhookMaxPerUser:    getEnvInt("WEBHOOK_MAX_PER_USER", 5),
+		WebhookTimeout:       getEnvDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+		WebhookMaxAttempts:   getEnvInt("WEBHOOK_MAX_ATTEMPTS", 6),
+		WebhookRetryBackoff:  getEnvDuration("WEBHOOK_RETRY_BACKOFF", 30*time.Second),
+		WebhookRetryInterval: getEnvDuration("WEBHOOK_RETRY_INTERVAL", 15*time.Second),
+		// hanya untuk development, di production endpoint webhook wajib https
+		WebhookAllowHTTP: getEnv("WEBHOOK_ALLOW_HTTP", "false") == "true",
+
+		OrderRecheckInterval: getEnvDuration("ORDER_RECHECK_INTERVAL", 30*time.Second),
+		OrderRecheckLockTTL:  getEnvDuration("ORDER_RECHECK_LOCK_TTL", 2*time.Minute),
+
+		BulkOrderMaxItems:    getEnvInt("BULK_ORDER_MAX_ITEMS", 20),
+		BulkOrderMaxQuantity: getEnvInt("BULK_ORDER_MAX_QUANTITY", 100),
+		BulkOrderWorkers:     getEnvInt("BULK_ORDER_WORKERS", 5),
 	}
 
 	if cfg.DatabaseURL == "" {
@@ -49,5 +141,68 @@ func LoadConfig() *AppConfig {
 		log.Fatal("REDIS_URL environment variable not set")
 	}
 
+	// JWT_SECRET_KEY lama tetap didukung sebagai key dengan kid "default"
+	if len(cfg.JWTKeys) == 0 && cfg.JWTSecretKey != "" {
+		cfg.JWTKeys = map[string]string{"default": cfg.JWTSecretKey}
+		if cfg.JWTActiveKeyId == "" {
+			cfg.JWTActiveKeyId = "default"
+		}
+	}
+	if len(cfg.JWTKeys) == 0 {
+		log.Fatal("JWT_KEYS or JWT_SECRET_KEY environment variable not set")
+	}
+	if _, ok := cfg.JWTKeys[cfg.JWTActiveKeyId]; !ok {
+		log.Fatalf("JWT_ACTIVE_KID %q is not one of JWT_KEYS", cfg.JWTActiveKeyId)
+	}
+
 	return cfg
 }
+
+// getEnvDuration membaca durasi (contoh: "15m", "1h") dari env, atau default jika kosong/tidak valid
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("invalid duration for %s: %v, using default %s", key, err, defaultValue)
+		return defaultValue
+	}
+	return duration
+}
+
+// getEnvInt membaca angka dari env, atau default jika kosong/tidak valid
+func getEnvInt(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("invalid number for %s: %v, using default %d", key, err, defaultValue)
+		return defaultValue
+	}
+	return number
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// parseJWTKeys membaca JWT_KEYS dengan format "kid1:secret1,kid2:secret2".
+// Semua key dipakai untuk verifikasi, hanya JWT_ACTIVE_KID yang dipakai untuk sign.
+func parseJWTKeys(value string) map[string]string {
+	keys := map[string]string{}
+	for _, pair := range strings.Split(value, ",") {
+		kid, secret, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok || kid == "" || secret == "" {
+			continue
+		}
+		keys[kid] = secret
+	}
+	return keys
+}
@@ -0,0 +1,4 @@
+-- invoice_id diisi setelah row dibuat (berisi order id), jadi kolom harus nullable
+ALTER TABLE sim_orders MODIFY invoice_id VARCHAR(64) NULL;
+
+ALTER TABLE sim_orders ADD UNIQUE INDEX uq_sim_orders_invoice_id (invoice_id);
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    base_currency CHAR(3) NOT NULL,
+    quote_currency CHAR(3) NOT NULL,
+    rate DECIMAL(18, 6) NOT NULL,
+    source VARCHAR(32) NOT NULL,
+    active_override TINYINT(1) NOT NULL DEFAULT 0,
+    fetched_at DATETIME NOT NULL,
+    INDEX idx_exchange_rates_pair (base_currency, quote_currency, fetched_at)
+);
+
+-- kurs yang dipakai saat order dibuat
+ALTER TABLE sim_orders
+    ADD COLUMN exchange_rate DECIMAL(18, 6) NULL,
+    ADD COLUMN exchange_rate_id INT NULL;
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS pricing_rules (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    service VARCHAR(100) NOT NULL DEFAULT '',
+    country VARCHAR(100) NOT NULL DEFAULT '',
+    operator VARCHAR(100) NOT NULL DEFAULT '',
+    priority INT NOT NULL DEFAULT 0,
+    markup_percent DECIMAL(8, 4) NOT NULL DEFAULT 0,
+    markup_fixed DECIMAL(18, 2) NOT NULL DEFAULT 0,
+    min_margin DECIMAL(18, 2) NOT NULL DEFAULT 0,
+    round_to DECIMAL(18, 2) NOT NULL DEFAULT 0,
+    active TINYINT(1) NOT NULL DEFAULT 1,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+);
+
+-- rule default menggantikan markup flat 2000 IDR
+INSERT INTO pricing_rules(name, priority, markup_fixed) VALUES('default', 0, 2000);
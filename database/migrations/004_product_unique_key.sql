@@ -0,0 +1,9 @@
+-- hapus duplikat hasil sync lama (INSERT biasa), sisakan row terbaru
+DELETE p1 FROM product p1
+JOIN product p2
+    ON p1.service = p2.service AND p1.country = p2.country AND p1.operator = p2.operator AND p1.id < p2.id;
+
+ALTER TABLE product
+    ADD COLUMN available TINYINT(1) NOT NULL DEFAULT 1,
+    ADD COLUMN updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    ADD UNIQUE INDEX uq_product_key (service, country, operator);
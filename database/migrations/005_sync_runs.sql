@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS sync_runs (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    trigger_type VARCHAR(16) NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    added INT NOT NULL DEFAULT 0,
+    updated INT NOT NULL DEFAULT 0,
+    removed INT NOT NULL DEFAULT 0,
+    unchanged INT NOT NULL DEFAULT 0,
+    errors INT NOT NULL DEFAULT 0,
+    error_message TEXT NULL,
+    started_at DATETIME NOT NULL,
+    finished_at DATETIME NULL,
+    INDEX idx_sync_runs_started_at (started_at)
+);
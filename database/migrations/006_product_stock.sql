@@ -0,0 +1,13 @@
+ALTER TABLE product ADD COLUMN stock INT NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS product_stock_snapshots (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    service VARCHAR(100) NOT NULL,
+    country VARCHAR(100) NOT NULL,
+    operator VARCHAR(100) NOT NULL,
+    qty INT NOT NULL,
+    source VARCHAR(16) NOT NULL,
+    recorded_at DATETIME NOT NULL,
+    INDEX idx_stock_snapshots_key (service, country, operator, recorded_at),
+    INDEX idx_stock_snapshots_recorded_at (recorded_at)
+);
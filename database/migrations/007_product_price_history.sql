@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS product_price_history (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    service VARCHAR(100) NOT NULL,
+    country VARCHAR(100) NOT NULL,
+    operator VARCHAR(100) NOT NULL,
+    old_price_default DECIMAL(18, 4) NULL,
+    new_price_default DECIMAL(18, 4) NOT NULL,
+    old_price_sell DECIMAL(18, 2) NULL,
+    new_price_sell DECIMAL(18, 2) NOT NULL,
+    exchange_rate DECIMAL(18, 6) NOT NULL,
+    exchange_rate_id INT NULL,
+    pricing_rule_id INT NULL,
+    pricing_rule_name VARCHAR(100) NULL,
+    changed_at DATETIME NOT NULL,
+    INDEX idx_price_history_key (service, country, operator, changed_at)
+);
@@ -0,0 +1,15 @@
+-- country/operator kosong berarti override berlaku untuk semua country/operator dari service tersebut.
+-- Kolom NULL berarti tidak di-override.
+CREATE TABLE IF NOT EXISTS product_overrides (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    service VARCHAR(100) NOT NULL,
+    country VARCHAR(100) NOT NULL DEFAULT '',
+    operator VARCHAR(100) NOT NULL DEFAULT '',
+    enabled TINYINT(1) NULL,
+    pinned_price DECIMAL(18, 2) NULL,
+    display_name VARCHAR(100) NULL,
+    icon_url VARCHAR(255) NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE INDEX uq_product_overrides_key (service, country, operator)
+);
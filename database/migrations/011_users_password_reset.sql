@@ -0,0 +1,4 @@
+ALTER TABLE users
+    ADD COLUMN password_reset_token_hash CHAR(64) NULL,
+    ADD COLUMN password_reset_expires_at DATETIME NULL,
+    ADD UNIQUE INDEX uq_users_password_reset_token (password_reset_token_hash);
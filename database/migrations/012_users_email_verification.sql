@@ -0,0 +1,8 @@
+ALTER TABLE users
+    ADD COLUMN email_verified_at DATETIME NULL,
+    ADD COLUMN email_verification_token_hash CHAR(64) NULL,
+    ADD COLUMN email_verification_expires_at DATETIME NULL,
+    ADD UNIQUE INDEX uq_users_email_verification_token (email_verification_token_hash);
+
+-- user lama dianggap sudah terverifikasi agar tidak tiba-tiba terblokir order
+UPDATE users SET email_verified_at = NOW() WHERE email_verified_at IS NULL;
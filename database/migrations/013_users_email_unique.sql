@@ -0,0 +1,13 @@
+-- Register sebelumnya tidak menolak email ganda. Akun tertua dipertahankan,
+-- duplikat lainnya diganti email placeholder agar unique index bisa dibuat
+-- tanpa menghapus data order milik akun tersebut.
+UPDATE users u
+JOIN (
+    SELECT email, MIN(id) AS keep_id
+    FROM users
+    GROUP BY email
+    HAVING COUNT(*) > 1
+) d ON d.email = u.email AND u.id <> d.keep_id
+SET u.email = CONCAT('duplicate+', u.id, '@invalid.local');
+
+ALTER TABLE users ADD UNIQUE INDEX uq_users_email (email);
@@ -0,0 +1,3 @@
+ALTER TABLE users
+    ADD COLUMN pending_email VARCHAR(255) NULL,
+    ADD COLUMN deleted_at DATETIME NULL;
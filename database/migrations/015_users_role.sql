@@ -0,0 +1,3 @@
+-- role: customer, reseller, support, admin.
+-- Admin pertama diberikan manual: UPDATE users SET role = 'admin' WHERE email = '...';
+ALTER TABLE users ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'customer';
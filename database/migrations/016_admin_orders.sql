@@ -0,0 +1,58 @@
+CREATE TABLE IF NOT EXISTS sim_order_status_history (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    order_id INT NOT NULL,
+    status VARCHAR(32) NOT NULL,
+    source VARCHAR(32) NOT NULL,
+    note VARCHAR(500) NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_sim_order_status_history_order (order_id, id)
+);
+
+-- order yang sudah ada sebelum tabel ini dibuat diberi satu baris riwayat dari status saat ini.
+-- Order yang sudah punya nomor 5sim pasti sudah dibayar, jadi PAID ikut dicatat.
+INSERT INTO sim_order_status_history (order_id, status, source, note, created_at)
+SELECT o.id, 'PAID', 'system', 'backfill', o.created_at
+FROM sim_orders o
+WHERE (o.status = 'PAID' OR o.sim_order_service_id IS NOT NULL)
+  AND NOT EXISTS (SELECT 1 FROM sim_order_status_history h WHERE h.order_id = o.id);
+
+INSERT INTO sim_order_status_history (order_id, status, source, note, created_at)
+SELECT o.id, o.status, 'system', 'backfill', o.updated_at
+FROM sim_orders o
+WHERE o.status <> 'PAID'
+  AND NOT EXISTS (
+      SELECT 1 FROM sim_order_status_history h
+      WHERE h.order_id = o.id
+        AND (h.note IS NULL OR h.note <> 'backfill' OR h.status = o.status)
+  );
+
+-- response mentah dari provider (5sim, xendit) untuk investigasi support
+CREATE TABLE IF NOT EXISTS sim_order_provider_responses (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    order_id INT NOT NULL,
+    provider VARCHAR(32) NOT NULL,
+    action VARCHAR(32) NOT NULL,
+    success TINYINT(1) NOT NULL,
+    body TEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_sim_order_provider_responses_order (order_id, id)
+);
+
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    actor_user_id INT NULL,
+    action VARCHAR(64) NOT NULL,
+    target_type VARCHAR(32) NOT NULL,
+    target_id VARCHAR(64) NOT NULL,
+    reason VARCHAR(500) NULL,
+    metadata TEXT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_audit_logs_target (target_type, target_id),
+    INDEX idx_audit_logs_actor (actor_user_id)
+);
+
+ALTER TABLE sim_orders
+    ADD COLUMN refunded_at DATETIME NULL,
+    ADD COLUMN refund_reason VARCHAR(500) NULL,
+    ADD INDEX idx_sim_orders_phone_number (phone_number),
+    ADD INDEX idx_sim_orders_sim_order_service_id (sim_order_service_id);
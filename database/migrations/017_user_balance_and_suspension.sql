@@ -0,0 +1,19 @@
+ALTER TABLE users
+    ADD COLUMN balance DECIMAL(15,2) NOT NULL DEFAULT 0,
+    ADD COLUMN suspended_at DATETIME NULL,
+    ADD COLUMN suspended_reason VARCHAR(500) NULL;
+
+-- ledger saldo, balance_after memudahkan rekonsiliasi tanpa menjumlahkan ulang
+CREATE TABLE IF NOT EXISTS balance_transactions (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NOT NULL,
+    type VARCHAR(32) NOT NULL,
+    amount DECIMAL(15,2) NOT NULL,
+    balance_after DECIMAL(15,2) NOT NULL,
+    reason_code VARCHAR(32) NULL,
+    note VARCHAR(500) NULL,
+    reference VARCHAR(64) NULL,
+    actor_user_id INT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_balance_transactions_user (user_id, id)
+);
@@ -0,0 +1,27 @@
+ALTER TABLE audit_logs
+    ADD COLUMN before_data TEXT NULL,
+    ADD COLUMN after_data TEXT NULL,
+    ADD COLUMN ip_address VARCHAR(64) NULL,
+    ADD COLUMN user_agent VARCHAR(255) NULL,
+    ADD COLUMN prev_hash CHAR(64) NULL,
+    ADD COLUMN hash CHAR(64) NULL,
+    ADD INDEX idx_audit_logs_created_at (created_at),
+    ADD INDEX idx_audit_logs_action (action);
+
+-- ujung hash chain, baris ini dikunci (FOR UPDATE) setiap kali audit log ditulis
+-- sehingga penulisan audit log berurutan dan prev_hash selalu menunjuk ke baris sebelumnya.
+-- Audit log lama (sebelum migrasi ini) tidak punya hash dan tidak ikut chain.
+CREATE TABLE IF NOT EXISTS audit_log_chain (
+    id TINYINT PRIMARY KEY,
+    last_id BIGINT NULL,
+    last_hash CHAR(64) NOT NULL DEFAULT ''
+);
+
+INSERT IGNORE INTO audit_log_chain(id, last_hash) VALUES(1, '');
+
+-- audit log hanya boleh ditambah
+CREATE TRIGGER audit_logs_no_update BEFORE UPDATE ON audit_logs
+    FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'audit_logs is append-only';
+
+CREATE TRIGGER audit_logs_no_delete BEFORE DELETE ON audit_logs
+    FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'audit_logs is append-only';
@@ -0,0 +1,18 @@
+-- API key untuk order server-to-server oleh reseller.
+-- Hanya hash key yang disimpan, prefix dipakai untuk menampilkan key di daftar.
+CREATE TABLE IF NOT EXISTS api_keys (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    prefix VARCHAR(16) NOT NULL,
+    key_hash CHAR(64) NOT NULL,
+    scopes VARCHAR(255) NOT NULL,
+    rate_limit INT NOT NULL,
+    last_used_at DATETIME NULL,
+    last_used_ip VARCHAR(64) NULL,
+    expires_at DATETIME NULL,
+    revoked_at DATETIME NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE INDEX idx_api_keys_key_hash (key_hash),
+    INDEX idx_api_keys_user (user_id)
+);
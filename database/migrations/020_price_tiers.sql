@@ -0,0 +1,20 @@
+-- tier harga reseller, diskon diterapkan di atas harga jual katalog.
+-- monthly_volume_threshold NULL berarti tier hanya bisa di-assign manual oleh admin.
+CREATE TABLE IF NOT EXISTS price_tiers (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(64) NOT NULL,
+    discount_percent DECIMAL(5,2) NOT NULL DEFAULT 0,
+    monthly_volume_threshold DECIMAL(15,2) NULL,
+    active TINYINT(1) NOT NULL DEFAULT 1,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE INDEX idx_price_tiers_name (name)
+);
+
+ALTER TABLE users
+    ADD COLUMN price_tier_id INT NULL,
+    ADD INDEX idx_users_price_tier (price_tier_id);
+
+-- volume bulanan dihitung dari riwayat status PAID
+ALTER TABLE sim_order_status_history
+    ADD INDEX idx_sim_order_status_history_status_created (status, created_at);
@@ -0,0 +1,23 @@
+-- bulk order reseller: satu kali potong saldo untuk banyak nomor.
+-- status: processing saat nomor sedang dibeli, lalu completed, partial (sebagian di-refund) atau failed.
+CREATE TABLE IF NOT EXISTS bulk_orders (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    quantity INT NOT NULL,
+    total_amount DECIMAL(15,2) NOT NULL,
+    refunded_amount DECIMAL(15,2) NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at DATETIME NULL,
+    INDEX idx_bulk_orders_user (user_id, created_at)
+);
+
+ALTER TABLE sim_orders
+    ADD COLUMN bulk_order_id INT NULL,
+    ADD INDEX idx_sim_orders_bulk_order (bulk_order_id);
+
+-- satu order hanya bisa di-refund ke saldo sekali: refund order memakai reference "order:<id>".
+-- penyesuaian manual memakai reference "adjust:<user_id>:<idempotency_key>", reference NULL
+-- (penyesuaian lama) tidak dibatasi.
+ALTER TABLE balance_transactions
+    ADD UNIQUE INDEX idx_balance_transactions_type_reference (type, reference);
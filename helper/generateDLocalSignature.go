@@ -8,6 +8,17 @@ import (
 
 func generateDLocalSignature(secretKey, method, path string, body []byte, xDate string) string {
 	message := method + "\n" + path + "\n" + string(body) + "\n" + xDate
+	return hmacSHA256Hex(secretKey, message)
+}
+
+// GenerateWebhookSignature menandatangani payload webhook keluar dengan format yang sama seperti dLocal:
+// HMAC-SHA256 hex dari "<timestamp>\n<body>". Timestamp ikut di-sign agar payload lama tidak bisa di-replay.
+func GenerateWebhookSignature(secretKey, timestamp string, body []byte) string {
+	message := timestamp + "\n" + string(body)
+	return hmacSHA256Hex(secretKey, message)
+}
+
+func hmacSHA256Hex(secretKey, message string) string {
 	mac := hmac.New(sha256.New, []byte(secretKey))
 	mac.Write([]byte(message))
 
@@ -0,0 +1,17 @@
+package helper
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+)
+
+// GenerateInvoiceId membuat external id invoice yang berisi order id dan komponen acak,
+// sehingga tidak bisa ditebak dan tidak bentrok antar instance
+func GenerateInvoiceId(orderId int) (string, error) {
+	random := make([]byte, 8)
+	if _, err := rand.Read(random); err != nil {
+		return "", fmt.Errorf("failed to generate random invoice component: %w", err)
+	}
+	return fmt.Sprintf("INV-%d-%s", orderId, hex.EncodeToString(random)), nil
+}
@@ -0,0 +1,208 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/imnzr/sim-service-project/internal/repository"
+	"github.com/imnzr/sim-service-project/models"
+)
+
+// Target audit log
+const (
+	TargetUser      = "user"
+	TargetOrder     = "order"
+	TargetPayment   = "payment"
+	TargetAPIKey    = "api_key"
+	TargetBulkOrder = "bulk_order"
+)
+
+const (
+	defaultQueryLimit  = 50
+	maxQueryLimit      = 200
+	verifyBatchSize    = 500
+	maxUserAgentLength = 255
+)
+
+// Entry adalah input untuk mencatat satu aksi ke audit log.
+// Before/After adalah snapshot data sebelum dan sesudah aksi, disimpan sebagai JSON.
+type Entry struct {
+	ActorUserId uint
+	Action      string
+	TargetType  string
+	TargetId    string
+	Reason      string
+	Before      any
+	After       any
+	Metadata    any
+}
+
+type Logger interface {
+	Record(ctx context.Context, entry Entry) error
+	FindByTarget(ctx context.Context, targetType, targetId string) ([]models.AuditLog, error)
+	Search(ctx context.Context, query models.AuditLogQuery) (*models.CatalogPage[models.AuditLog], error)
+	Verify(ctx context.Context) (*models.AuditChainReport, error)
+}
+
+type LoggerImplementation struct {
+	repo repository.AuditLogRepository
+}
+
+func NewLogger(repo repository.AuditLogRepository) Logger {
+	return &LoggerImplementation{
+		repo: repo,
+	}
+}
+
+// encodeJSON mengubah snapshot menjadi JSON, nil tetap nil
+func encodeJSON(value any) (*string, error) {
+	if value == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	encoded := string(data)
+	return &encoded, nil
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+// Record implements Logger.
+// IP dan user agent diambil dari RequestInfo di context jika ada.
+func (l *LoggerImplementation) Record(ctx context.Context, entry Entry) error {
+	auditLog := &models.AuditLog{
+		Action:     entry.Action,
+		TargetType: entry.TargetType,
+		TargetId:   entry.TargetId,
+		Reason:     optionalString(entry.Reason),
+		// DATETIME hanya menyimpan detik, hash harus dihitung dari nilai yang sama dengan yang tersimpan
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+	}
+	if entry.ActorUserId != 0 {
+		auditLog.ActorUserId = &entry.ActorUserId
+	}
+
+	var err error
+	if auditLog.Before, err = encodeJSON(entry.Before); err != nil {
+		return fmt.Errorf("failed to encode audit before snapshot: %w", err)
+	}
+	if auditLog.After, err = encodeJSON(entry.After); err != nil {
+		return fmt.Errorf("failed to encode audit after snapshot: %w", err)
+	}
+	if auditLog.Metadata, err = encodeJSON(entry.Metadata); err != nil {
+		return fmt.Errorf("failed to encode audit metadata: %w", err)
+	}
+
+	if info, ok := RequestInfoFrom(ctx); ok {
+		userAgent := info.UserAgent
+		if len(userAgent) > maxUserAgentLength {
+			userAgent = userAgent[:maxUserAgentLength]
+		}
+		auditLog.IPAddress = optionalString(info.IPAddress)
+		auditLog.UserAgent = optionalString(userAgent)
+	}
+
+	if err := l.repo.Append(ctx, auditLog); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
+// FindByTarget implements Logger.
+func (l *LoggerImplementation) FindByTarget(ctx context.Context, targetType string, targetId string) ([]models.AuditLog, error) {
+	return l.repo.FindByTarget(ctx, targetType, targetId)
+}
+
+// Search implements Logger.
+func (l *LoggerImplementation) Search(ctx context.Context, query models.AuditLogQuery) (*models.CatalogPage[models.AuditLog], error) {
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.Limit < 1 {
+		query.Limit = defaultQueryLimit
+	}
+	if query.Limit > maxQueryLimit {
+		query.Limit = maxQueryLimit
+	}
+
+	entries, total, err := l.repo.Search(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search audit logs: %w", err)
+	}
+	return &models.CatalogPage[models.AuditLog]{Items: entries, Page: query.Page, Limit: query.Limit, Total: total}, nil
+}
+
+// Verify implements Logger.
+// Menghitung ulang hash setiap audit log dan memastikan prev_hash menunjuk ke hash baris sebelumnya.
+// Baris yang diubah, dihapus atau disisipkan di tengah membuat chain putus,
+// penghapusan baris terakhir terdeteksi dari ujung chain di audit_log_chain.
+func (l *LoggerImplementation) Verify(ctx context.Context) (*models.AuditChainReport, error) {
+	// ujung chain dibaca lebih dulu, audit log yang ditulis selama verifikasi tidak ikut dicek
+	headId, headHash, err := l.repo.ChainHead(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit chain head: %w", err)
+	}
+
+	report := &models.AuditChainReport{Valid: true}
+	broken := func(id int64, reason string) *models.AuditChainReport {
+		report.Valid = false
+		report.BrokenAtId = &id
+		report.Reason = reason
+		return report
+	}
+
+	var afterId int64
+	prevHash := ""
+	chained := false
+walk:
+	for afterId < headId {
+		entries, err := l.repo.FindAfter(ctx, afterId, verifyBatchSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read audit logs: %w", err)
+		}
+		if len(entries) == 0 {
+			break
+		}
+
+		for _, entry := range entries {
+			if entry.Id > headId {
+				break walk
+			}
+			afterId = entry.Id
+
+			// audit log lama sebelum hash chain diaktifkan
+			if entry.Hash == nil {
+				if chained {
+					return broken(entry.Id, "entry has no hash"), nil
+				}
+				report.Unchained++
+				continue
+			}
+			chained = true
+			report.Checked++
+
+			if entry.PrevHash == nil || *entry.PrevHash != prevHash {
+				return broken(entry.Id, "prev_hash does not match the previous entry"), nil
+			}
+			if entry.ChainHash() != *entry.Hash {
+				return broken(entry.Id, "entry content does not match its hash"), nil
+			}
+			prevHash = *entry.Hash
+			report.LastId = entry.Id
+		}
+	}
+
+	if report.LastId != headId || prevHash != headHash {
+		return broken(headId, "chain head does not match the last entry, entries may have been removed"), nil
+	}
+	return report, nil
+}
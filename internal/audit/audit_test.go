@@ -0,0 +1,196 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/imnzr/sim-service-project/internal/repository"
+	"github.com/imnzr/sim-service-project/models"
+)
+
+// fakeAuditLogRepo menyimpan audit log di memori dan membentuk chain seperti AuditLogImplementation.Append
+type fakeAuditLogRepo struct {
+	repository.AuditLogRepository
+	entries  []models.AuditLog
+	lastId   int64
+	headId   int64
+	lastHash string
+}
+
+func (f *fakeAuditLogRepo) Append(ctx context.Context, entry *models.AuditLog) error {
+	prevHash := f.lastHash
+	entry.PrevHash = &prevHash
+	hash := entry.ChainHash()
+	entry.Hash = &hash
+
+	f.lastId++
+	entry.Id = f.lastId
+	f.headId = entry.Id
+	f.lastHash = hash
+	f.entries = append(f.entries, *entry)
+	return nil
+}
+
+// appendUnchained menyisipkan audit log tanpa hash seperti baris lama sebelum hash chain diaktifkan
+func (f *fakeAuditLogRepo) appendUnchained(action string) {
+	f.lastId++
+	f.entries = append(f.entries, models.AuditLog{Id: f.lastId, Action: action, TargetType: TargetUser, TargetId: "1"})
+}
+
+func (f *fakeAuditLogRepo) FindAfter(ctx context.Context, afterId int64, limit int) ([]models.AuditLog, error) {
+	entries := []models.AuditLog{}
+	for _, entry := range f.entries {
+		if entry.Id > afterId && len(entries) < limit {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+func (f *fakeAuditLogRepo) ChainHead(ctx context.Context) (int64, string, error) {
+	return f.headId, f.lastHash, nil
+}
+
+func (f *fakeAuditLogRepo) remove(id int64) {
+	for i, entry := range f.entries {
+		if entry.Id == id {
+			f.entries = append(f.entries[:i], f.entries[i+1:]...)
+			return
+		}
+	}
+}
+
+func (f *fakeAuditLogRepo) find(id int64) *models.AuditLog {
+	for i := range f.entries {
+		if f.entries[i].Id == id {
+			return &f.entries[i]
+		}
+	}
+	return nil
+}
+
+func newChainedLogger(t *testing.T, count int) (Logger, *fakeAuditLogRepo) {
+	t.Helper()
+	repo := &fakeAuditLogRepo{}
+	logger := NewLogger(repo)
+	for i := 1; i <= count; i++ {
+		err := logger.Record(context.Background(), Entry{
+			ActorUserId: 99,
+			Action:      "user.balance_adjust",
+			TargetType:  TargetUser,
+			TargetId:    "1",
+			Reason:      fmt.Sprintf("adjustment %d", i),
+			Metadata:    map[string]any{"amount": i * 1000},
+		})
+		if err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+	return logger, repo
+}
+
+func verify(t *testing.T, logger Logger) *models.AuditChainReport {
+	t.Helper()
+	report, err := logger.Verify(context.Background())
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	return report
+}
+
+func expectBroken(t *testing.T, report *models.AuditChainReport, id int64) {
+	t.Helper()
+	if report.Valid {
+		t.Fatal("expected chain to be broken")
+	}
+	if report.BrokenAtId == nil || *report.BrokenAtId != id {
+		t.Fatalf("expected chain broken at %d, got %v (%s)", id, report.BrokenAtId, report.Reason)
+	}
+}
+
+func TestVerifyIntactChain(t *testing.T) {
+	logger, _ := newChainedLogger(t, 5)
+
+	report := verify(t, logger)
+	if !report.Valid || report.Checked != 5 || report.LastId != 5 {
+		t.Fatalf("expected valid chain of 5 entries, got %+v", report)
+	}
+}
+
+func TestVerifyEmptyChain(t *testing.T) {
+	logger := NewLogger(&fakeAuditLogRepo{})
+
+	if report := verify(t, logger); !report.Valid || report.Checked != 0 {
+		t.Fatalf("empty audit log must be valid, got %+v", report)
+	}
+}
+
+func TestVerifyChainAcrossBatches(t *testing.T) {
+	logger, _ := newChainedLogger(t, verifyBatchSize*2+3)
+
+	report := verify(t, logger)
+	if !report.Valid || report.Checked != verifyBatchSize*2+3 {
+		t.Fatalf("expected valid chain across batches, got %+v", report)
+	}
+}
+
+func TestVerifyDetectsModifiedEntry(t *testing.T) {
+	logger, repo := newChainedLogger(t, 5)
+	reason := "adjustment 3 (edited)"
+	repo.find(3).Reason = &reason
+
+	expectBroken(t, verify(t, logger), 3)
+}
+
+func TestVerifyDetectsRehashedEntry(t *testing.T) {
+	logger, repo := newChainedLogger(t, 5)
+	// pelaku mengubah isi dan menghitung ulang hash baris itu, baris berikutnya tetap menunjuk hash lama
+	entry := repo.find(3)
+	metadata := `{"amount":1}`
+	entry.Metadata = &metadata
+	hash := entry.ChainHash()
+	entry.Hash = &hash
+
+	expectBroken(t, verify(t, logger), 4)
+}
+
+func TestVerifyDetectsDeletedEntry(t *testing.T) {
+	logger, repo := newChainedLogger(t, 5)
+	repo.remove(3)
+
+	expectBroken(t, verify(t, logger), 4)
+}
+
+func TestVerifyDetectsDeletedLastEntry(t *testing.T) {
+	logger, repo := newChainedLogger(t, 5)
+	repo.remove(5)
+
+	expectBroken(t, verify(t, logger), 5)
+}
+
+func TestVerifyAllowsUnchainedLegacyEntries(t *testing.T) {
+	repo := &fakeAuditLogRepo{}
+	repo.appendUnchained("user.suspend")
+	repo.appendUnchained("user.unsuspend")
+	logger := NewLogger(repo)
+	if err := logger.Record(context.Background(), Entry{Action: "user.role_change", TargetType: TargetUser, TargetId: "1"}); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+
+	report := verify(t, logger)
+	if !report.Valid || report.Unchained != 2 || report.Checked != 1 {
+		t.Fatalf("legacy entries before the chain must be reported as unchained, got %+v", report)
+	}
+}
+
+func TestVerifyDetectsUnchainedEntryInsideChain(t *testing.T) {
+	logger, repo := newChainedLogger(t, 2)
+	// baris tanpa hash disisipkan setelah chain aktif
+	repo.appendUnchained("user.balance_adjust")
+	if err := logger.Record(context.Background(), Entry{Action: "user.role_change", TargetType: TargetUser, TargetId: "1"}); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+
+	expectBroken(t, verify(t, logger), 3)
+}
@@ -0,0 +1,26 @@
+package audit
+
+import "context"
+
+type contextKey string
+
+// RequestInfoKey adalah key RequestInfo di context request.
+// Untuk fiber diisi lewat fasthttp user value (lihat middleware.AuditRequestInfo).
+const RequestInfoKey contextKey = "audit.request_info"
+
+// RequestInfo adalah asal request yang ikut dicatat di setiap audit log
+type RequestInfo struct {
+	IPAddress string
+	UserAgent string
+}
+
+// WithRequestInfo menambahkan RequestInfo ke context di luar request fiber (mis. job/scheduler)
+func WithRequestInfo(ctx context.Context, info RequestInfo) context.Context {
+	return context.WithValue(ctx, RequestInfoKey, info)
+}
+
+// RequestInfoFrom membaca RequestInfo dari context, ok false jika tidak ada
+func RequestInfoFrom(ctx context.Context) (RequestInfo, bool) {
+	info, ok := ctx.Value(RequestInfoKey).(RequestInfo)
+	return info, ok
+}
@@ -0,0 +1,38 @@
+package audit
+
+import "github.com/imnzr/sim-service-project/models"
+
+// OrderSnapshot adalah field order yang dicatat sebagai before/after di audit log
+func OrderSnapshot(order *models.SimOrder) map[string]any {
+	if order == nil {
+		return nil
+	}
+	return map[string]any{
+		"status":               order.Status,
+		"price_sell":           order.PriceSell,
+		"invoice_id":           order.InvoiceId,
+		"sim_order_service_id": order.SimOrderServiceId,
+		"phone_number":         order.PhoneNumber,
+		"refunded_at":          order.RefundedAt,
+		"refund_reason":        order.RefundReason,
+	}
+}
+
+// UserSnapshot adalah field user yang dicatat sebagai before/after di audit log.
+// Password dan token tidak pernah ikut dicatat.
+func UserSnapshot(user *models.User) map[string]any {
+	if user == nil {
+		return nil
+	}
+	return map[string]any{
+		"username":          user.Username,
+		"email":             user.Email,
+		"pending_email":     user.PendingEmail,
+		"role":              user.Role,
+		"email_verified_at": user.EmailVerifiedAt,
+		"balance":           user.Balance,
+		"suspended_at":      user.SuspendedAt,
+		"suspended_reason":  user.SuspendedReason,
+		"price_tier_id":     user.PriceTierId,
+	}
+}
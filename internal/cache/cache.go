@@ -0,0 +1,125 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrUnavailable dikembalikan jika redis tidak bisa diakses, pemanggil sebaiknya fallback ke database
+var ErrUnavailable = errors.New("cache unavailable")
+
+// Cache membungkus satu redis client yang dibuat sekali di main.go
+type Cache struct {
+	client *redis.Client
+	group  singleFlight
+}
+
+func NewCache(client *redis.Client) *Cache {
+	return &Cache{
+		client: client,
+	}
+}
+
+// Client mengembalikan redis client untuk komponen lain (lock, rate limit, dll)
+func (c *Cache) Client() *redis.Client {
+	return c.client
+}
+
+// Get membaca value JSON dari cache. found bernilai false jika key tidak ada.
+func Get[T any](ctx context.Context, c *Cache, key string) (value T, found bool, err error) {
+	data, err := c.client.Get(ctx, key).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return value, false, nil
+		}
+		return value, false, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+
+	if err := json.Unmarshal(data, &value); err != nil {
+		return value, false, fmt.Errorf("failed to decode cache %s: %w", key, err)
+	}
+	return value, true, nil
+}
+
+// Set menyimpan value sebagai JSON dengan TTL
+func Set[T any](ctx context.Context, c *Cache, key string, value T, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache %s: %w", key, err)
+	}
+	if err := c.client.Set(ctx, key, data, ttl).Err(); err != nil {
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	return nil
+}
+
+// GetOrLoad membaca cache, dan jika miss menjalankan loader satu kali untuk semua request
+// bersamaan pada key yang sama (single-flight), lalu menyimpan hasilnya.
+// Jika redis down, ErrUnavailable dikembalikan tanpa memanggil loader.
+func GetOrLoad[T any](ctx context.Context, c *Cache, key string, ttl time.Duration, loader func(ctx context.Context) (T, error)) (T, error) {
+	value, found, err := Get[T](ctx, c, key)
+	if err != nil && errors.Is(err, ErrUnavailable) {
+		return value, err
+	}
+	if found {
+		return value, nil
+	}
+
+	result, err := c.group.do(key, func() (any, error) {
+		loaded, err := loader(ctx)
+		if err != nil {
+			return loaded, err
+		}
+		if err := Set(ctx, c, key, loaded, ttl); err != nil {
+			log.Printf("failed to write cache %s: %v", key, err)
+		}
+		return loaded, nil
+	})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return result.(T), nil
+}
+
+// DeleteByPrefix menghapus semua key dengan prefix tertentu (invalidasi)
+func (c *Cache) DeleteByPrefix(ctx context.Context, prefix string) (int, error) {
+	var deleted int
+	iter := c.client.Scan(ctx, 0, prefix+"*", 500).Iterator()
+
+	keys := make([]string, 0, 500)
+	flush := func() error {
+		if len(keys) == 0 {
+			return nil
+		}
+		n, err := c.client.Del(ctx, keys...).Result()
+		if err != nil {
+			return err
+		}
+		deleted += int(n)
+		keys = keys[:0]
+		return nil
+	}
+
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+		if len(keys) == cap(keys) {
+			if err := flush(); err != nil {
+				return deleted, fmt.Errorf("%w: %v", ErrUnavailable, err)
+			}
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return deleted, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	if err := flush(); err != nil {
+		return deleted, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	return deleted, nil
+}
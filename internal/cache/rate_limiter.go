@@ -0,0 +1,41 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const rateLimitKeyPrefix = "ratelimit:"
+
+// incrWithExpire menaikkan counter dan memasang TTL hanya saat key pertama kali dibuat,
+// dalam satu script agar key tidak pernah tertinggal tanpa TTL
+var incrWithExpire = redis.NewScript(`
+local count = redis.call("INCR", KEYS[1])
+if count == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return count
+`)
+
+// RateLimiter membatasi jumlah aksi per key dalam satu window tetap (fixed window counter)
+type RateLimiter struct {
+	client *redis.Client
+}
+
+func NewRateLimiter(c *Cache) *RateLimiter {
+	return &RateLimiter{
+		client: c.Client(),
+	}
+}
+
+// Allow menambah counter key dan mengembalikan false jika sudah melebihi limit dalam window
+func (r *RateLimiter) Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, error) {
+	count, err := incrWithExpire.Run(ctx, r.client, []string{rateLimitKeyPrefix + key}, window.Milliseconds()).Int64()
+	if err != nil {
+		return false, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	return count <= int64(limit), nil
+}
@@ -0,0 +1,44 @@
+package cache
+
+import "sync"
+
+// call adalah satu pemanggilan loader yang sedang berjalan
+type call struct {
+	wg    sync.WaitGroup
+	value any
+	err   error
+}
+
+// singleFlight memastikan hanya satu loader berjalan untuk key yang sama,
+// pemanggil lain menunggu dan memakai hasil yang sama
+type singleFlight struct {
+	mu    sync.Mutex
+	calls map[string]*call
+}
+
+func (g *singleFlight) do(key string, fn func() (any, error)) (any, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*call)
+	}
+	if c, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		c.wg.Wait()
+		return c.value, c.err
+	}
+
+	c := &call{}
+	c.wg.Add(1)
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	defer func() {
+		c.wg.Done()
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+	}()
+
+	c.value, c.err = fn()
+	return c.value, c.err
+}
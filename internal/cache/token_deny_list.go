@@ -0,0 +1,100 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	deniedTokenKeyPrefix = "auth:denied_jti:"
+	userRevokedKeyPrefix = "auth:revoked_before:"
+	suspendedKeyPrefix   = "auth:suspended:"
+)
+
+// TokenDenyList menyimpan access token yang sudah dicabut sebelum expired.
+// TTL setiap key disamakan dengan sisa umur token sehingga redis membersihkannya sendiri.
+type TokenDenyList struct {
+	client *redis.Client
+}
+
+func NewTokenDenyList(c *Cache) *TokenDenyList {
+	return &TokenDenyList{
+		client: c.Client(),
+	}
+}
+
+// Deny mencabut satu token berdasarkan jti sampai waktu expired-nya
+func (d *TokenDenyList) Deny(ctx context.Context, jti string, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+	if err := d.client.Set(ctx, deniedTokenKeyPrefix+jti, 1, ttl).Err(); err != nil {
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	return nil
+}
+
+// IsDenied mengecek apakah jti sudah dicabut
+func (d *TokenDenyList) IsDenied(ctx context.Context, jti string) (bool, error) {
+	n, err := d.client.Exists(ctx, deniedTokenKeyPrefix+jti).Result()
+	if err != nil {
+		return false, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	return n > 0, nil
+}
+
+// RevokeUserBefore mencabut semua token user yang diterbitkan sebelum waktu tertentu.
+// ttl cukup sepanjang umur access token terlama.
+func (d *TokenDenyList) RevokeUserBefore(ctx context.Context, userId uint, before time.Time, ttl time.Duration) error {
+	key := userRevokedKeyPrefix + strconv.FormatUint(uint64(userId), 10)
+	if err := d.client.Set(ctx, key, before.Unix(), ttl).Err(); err != nil {
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	return nil
+}
+
+// UserRevokedBefore mengembalikan batas waktu pencabutan token user, zero time jika tidak ada
+func (d *TokenDenyList) UserRevokedBefore(ctx context.Context, userId uint) (time.Time, error) {
+	key := userRevokedKeyPrefix + strconv.FormatUint(uint64(userId), 10)
+	unix, err := d.client.Get(ctx, key).Int64()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return time.Time{}, nil
+		}
+		return time.Time{}, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	return time.Unix(unix, 0), nil
+}
+
+// SuspendUser menandai user sebagai suspended sampai di-unsuspend, tanpa TTL
+func (d *TokenDenyList) SuspendUser(ctx context.Context, userId uint) error {
+	key := suspendedKeyPrefix + strconv.FormatUint(uint64(userId), 10)
+	if err := d.client.Set(ctx, key, 1, 0).Err(); err != nil {
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	return nil
+}
+
+// UnsuspendUser menghapus tanda suspended user
+func (d *TokenDenyList) UnsuspendUser(ctx context.Context, userId uint) error {
+	key := suspendedKeyPrefix + strconv.FormatUint(uint64(userId), 10)
+	if err := d.client.Del(ctx, key).Err(); err != nil {
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	return nil
+}
+
+// IsUserSuspended mengecek apakah user sedang di-suspend
+func (d *TokenDenyList) IsUserSuspended(ctx context.Context, userId uint) (bool, error) {
+	n, err := d.client.Exists(ctx, suspendedKeyPrefix+strconv.FormatUint(uint64(userId), 10)).Result()
+	if err != nil {
+		return false, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	return n > 0, nil
+}
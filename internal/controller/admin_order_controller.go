@@ -0,0 +1,158 @@
+package controller
+
+import (
+	"errors"
+	"log"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/imnzr/sim-service-project/internal/service"
+	"github.com/imnzr/sim-service-project/models"
+)
+
+type AdminOrderController interface {
+	SearchOrders(controller *fiber.Ctx) error
+	GetOrder(controller *fiber.Ctx) error
+	RecheckOrder(controller *fiber.Ctx) error
+	RetryFulfilment(controller *fiber.Ctx) error
+	RefundOrder(controller *fiber.Ctx) error
+}
+
+type AdminOrderControllerImplementation struct {
+	AdminOrderService service.AdminOrderService
+}
+
+func NewAdminOrderController(adminOrderService service.AdminOrderService) AdminOrderController {
+	return &AdminOrderControllerImplementation{
+		AdminOrderService: adminOrderService,
+	}
+}
+
+// adminOrderError memetakan error admin order ke status HTTP
+func adminOrderError(controller *fiber.Ctx, err error) error {
+	if body, ok := validationErrorBody(err); ok {
+		return controller.Status(fiber.StatusUnprocessableEntity).JSON(body)
+	}
+	switch {
+	case errors.Is(err, service.ErrOrderNotFound):
+		return controller.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, service.ErrRefundReasonRequired):
+		return controller.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, service.ErrOrderNotPaid),
+		errors.Is(err, service.ErrOrderAlreadyFulfilled),
+		errors.Is(err, service.ErrOrderAlreadyRefunded),
+		errors.Is(err, service.ErrNoProviderOrder),
+		errors.Is(err, service.ErrFulfilmentInProgress):
+		return controller.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	log.Printf("admin order error: %v", err)
+	return controller.Status(500).JSON(fiber.Map{
+		"error": err.Error(),
+	})
+}
+
+// orderIdParam membaca :id dari path
+func orderIdParam(controller *fiber.Ctx) (int, bool) {
+	id, err := strconv.Atoi(controller.Params("id"))
+	return id, err == nil && id > 0
+}
+
+// SearchOrders implements AdminOrderController.
+// Filter: ?email=, ?invoice_id=, ?phone=, ?provider_id= (id order 5sim), ?status=, ?page=, ?limit=
+func (a *AdminOrderControllerImplementation) SearchOrders(controller *fiber.Ctx) error {
+	query := models.AdminOrderQuery{
+		Email:           controller.Query("email"),
+		InvoiceId:       controller.Query("invoice_id"),
+		PhoneNumber:     controller.Query("phone"),
+		ProviderOrderId: controller.QueryInt("provider_id", 0),
+		Status:          controller.Query("status"),
+		Page:            controller.QueryInt("page", 1),
+		Limit:           controller.QueryInt("limit", 0),
+	}
+
+	page, err := a.AdminOrderService.Search(controller.Context(), query)
+	if err != nil {
+		return adminOrderError(controller, err)
+	}
+	return controller.Status(200).JSON(page)
+}
+
+// GetOrder implements AdminOrderController.
+func (a *AdminOrderControllerImplementation) GetOrder(controller *fiber.Ctx) error {
+	orderId, ok := orderIdParam(controller)
+	if !ok {
+		return controller.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid order id",
+		})
+	}
+
+	detail, err := a.AdminOrderService.Detail(controller.Context(), orderId)
+	if err != nil {
+		return adminOrderError(controller, err)
+	}
+	return controller.Status(200).JSON(detail)
+}
+
+// RecheckOrder implements AdminOrderController.
+func (a *AdminOrderControllerImplementation) RecheckOrder(controller *fiber.Ctx) error {
+	orderId, ok := orderIdParam(controller)
+	if !ok {
+		return controller.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid order id",
+		})
+	}
+	actorId, _ := controller.Locals("userID").(uint)
+
+	detail, err := a.AdminOrderService.Recheck(controller.Context(), actorId, orderId)
+	if err != nil {
+		return adminOrderError(controller, err)
+	}
+	return controller.Status(200).JSON(detail)
+}
+
+// RetryFulfilment implements AdminOrderController.
+func (a *AdminOrderControllerImplementation) RetryFulfilment(controller *fiber.Ctx) error {
+	orderId, ok := orderIdParam(controller)
+	if !ok {
+		return controller.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid order id",
+		})
+	}
+	actorId, _ := controller.Locals("userID").(uint)
+
+	detail, err := a.AdminOrderService.RetryFulfilment(controller.Context(), actorId, orderId)
+	if err != nil {
+		return adminOrderError(controller, err)
+	}
+	return controller.Status(200).JSON(detail)
+}
+
+// RefundOrder implements AdminOrderController.
+func (a *AdminOrderControllerImplementation) RefundOrder(controller *fiber.Ctx) error {
+	orderId, ok := orderIdParam(controller)
+	if !ok {
+		return controller.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid order id",
+		})
+	}
+	actorId, _ := controller.Locals("userID").(uint)
+
+	var req models.RefundOrderRequest
+	if err := controller.BodyParser(&req); err != nil {
+		return controller.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request",
+		})
+	}
+
+	detail, err := a.AdminOrderService.Refund(controller.Context(), actorId, orderId, req.Reason)
+	if err != nil {
+		return adminOrderError(controller, err)
+	}
+	return controller.Status(200).JSON(detail)
+}
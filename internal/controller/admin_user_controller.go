@@ -0,0 +1,208 @@
+package controller
+
+import (
+	"errors"
+	"log"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/imnzr/sim-service-project/internal/service"
+	"github.com/imnzr/sim-service-project/models"
+)
+
+type AdminUserController interface {
+	SearchUsers(controller *fiber.Ctx) error
+	GetUser(controller *fiber.Ctx) error
+	GetUserOrders(controller *fiber.Ctx) error
+	GetUserPayments(controller *fiber.Ctx) error
+	SuspendUser(controller *fiber.Ctx) error
+	UnsuspendUser(controller *fiber.Ctx) error
+	AdjustBalance(controller *fiber.Ctx) error
+}
+
+type AdminUserControllerImplementation struct {
+	AdminUserService service.AdminUserService
+}
+
+func NewAdminUserController(adminUserService service.AdminUserService) AdminUserController {
+	return &AdminUserControllerImplementation{
+		AdminUserService: adminUserService,
+	}
+}
+
+// adminUserError memetakan error admin user ke status HTTP
+func adminUserError(controller *fiber.Ctx, err error) error {
+	if body, ok := validationErrorBody(err); ok {
+		return controller.Status(fiber.StatusUnprocessableEntity).JSON(body)
+	}
+	switch {
+	case errors.Is(err, service.ErrUserNotFound):
+		return controller.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, service.ErrSuspendReasonRequired),
+		errors.Is(err, service.ErrCannotSuspendSelf):
+		return controller.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, service.ErrUserAlreadySuspended),
+		errors.Is(err, service.ErrUserNotSuspended),
+		errors.Is(err, service.ErrInsufficientBalance),
+		errors.Is(err, service.ErrIdempotencyKeyReused):
+		return controller.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	log.Printf("admin user error: %v", err)
+	return controller.Status(500).JSON(fiber.Map{
+		"error": err.Error(),
+	})
+}
+
+// userIdParam membaca :id dari path
+func userIdParam(controller *fiber.Ctx) (uint, bool) {
+	id, err := strconv.ParseUint(controller.Params("id"), 10, 32)
+	return uint(id), err == nil && id > 0
+}
+
+func invalidUserId(controller *fiber.Ctx) error {
+	return controller.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		"error": "invalid user id",
+	})
+}
+
+// SearchUsers implements AdminUserController.
+// Filter: ?q= (email/username), ?role=, ?suspended=true|false, ?page=, ?limit=
+func (a *AdminUserControllerImplementation) SearchUsers(controller *fiber.Ctx) error {
+	query := models.AdminUserQuery{
+		Search: controller.Query("q"),
+		Role:   controller.Query("role"),
+		Page:   controller.QueryInt("page", 1),
+		Limit:  controller.QueryInt("limit", 0),
+	}
+	if raw := controller.Query("suspended"); raw != "" {
+		suspended, err := strconv.ParseBool(raw)
+		if err != nil {
+			return controller.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "suspended must be true or false",
+			})
+		}
+		query.Suspended = &suspended
+	}
+
+	page, err := a.AdminUserService.Search(controller.Context(), query)
+	if err != nil {
+		return adminUserError(controller, err)
+	}
+	return controller.Status(200).JSON(page)
+}
+
+// GetUser implements AdminUserController.
+func (a *AdminUserControllerImplementation) GetUser(controller *fiber.Ctx) error {
+	userId, ok := userIdParam(controller)
+	if !ok {
+		return invalidUserId(controller)
+	}
+
+	detail, err := a.AdminUserService.Detail(controller.Context(), userId)
+	if err != nil {
+		return adminUserError(controller, err)
+	}
+	return controller.Status(200).JSON(detail)
+}
+
+// GetUserOrders implements AdminUserController.
+func (a *AdminUserControllerImplementation) GetUserOrders(controller *fiber.Ctx) error {
+	userId, ok := userIdParam(controller)
+	if !ok {
+		return invalidUserId(controller)
+	}
+
+	page, err := a.AdminUserService.Orders(controller.Context(), userId, controller.QueryInt("page", 1), controller.QueryInt("limit", 0))
+	if err != nil {
+		return adminUserError(controller, err)
+	}
+	return controller.Status(200).JSON(page)
+}
+
+// GetUserPayments implements AdminUserController.
+func (a *AdminUserControllerImplementation) GetUserPayments(controller *fiber.Ctx) error {
+	userId, ok := userIdParam(controller)
+	if !ok {
+		return invalidUserId(controller)
+	}
+
+	payments, err := a.AdminUserService.Payments(controller.Context(), userId)
+	if err != nil {
+		return adminUserError(controller, err)
+	}
+	return controller.Status(200).JSON(fiber.Map{
+		"payments": payments,
+	})
+}
+
+// SuspendUser implements AdminUserController.
+func (a *AdminUserControllerImplementation) SuspendUser(controller *fiber.Ctx) error {
+	userId, ok := userIdParam(controller)
+	if !ok {
+		return invalidUserId(controller)
+	}
+	actorId, _ := controller.Locals("userID").(uint)
+
+	var req models.SuspendUserRequest
+	if err := controller.BodyParser(&req); err != nil {
+		return controller.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request",
+		})
+	}
+
+	detail, err := a.AdminUserService.Suspend(controller.Context(), actorId, userId, req.Reason)
+	if err != nil {
+		return adminUserError(controller, err)
+	}
+	return controller.Status(200).JSON(detail)
+}
+
+// UnsuspendUser implements AdminUserController.
+func (a *AdminUserControllerImplementation) UnsuspendUser(controller *fiber.Ctx) error {
+	userId, ok := userIdParam(controller)
+	if !ok {
+		return invalidUserId(controller)
+	}
+	actorId, _ := controller.Locals("userID").(uint)
+
+	var req models.SuspendUserRequest
+	if err := controller.BodyParser(&req); err != nil {
+		return controller.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request",
+		})
+	}
+
+	detail, err := a.AdminUserService.Unsuspend(controller.Context(), actorId, userId, req.Reason)
+	if err != nil {
+		return adminUserError(controller, err)
+	}
+	return controller.Status(200).JSON(detail)
+}
+
+// AdjustBalance implements AdminUserController.
+func (a *AdminUserControllerImplementation) AdjustBalance(controller *fiber.Ctx) error {
+	userId, ok := userIdParam(controller)
+	if !ok {
+		return invalidUserId(controller)
+	}
+	actorId, _ := controller.Locals("userID").(uint)
+
+	var req models.BalanceAdjustmentRequest
+	if err := controller.BodyParser(&req); err != nil {
+		return controller.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request",
+		})
+	}
+
+	transaction, err := a.AdminUserService.AdjustBalance(controller.Context(), actorId, userId, &req)
+	if err != nil {
+		return adminUserError(controller, err)
+	}
+	return controller.Status(fiber.StatusCreated).JSON(transaction)
+}
@@ -0,0 +1,99 @@
+package controller
+
+import (
+	"errors"
+	"log"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/imnzr/sim-service-project/internal/service"
+	"github.com/imnzr/sim-service-project/models"
+)
+
+type APIKeyController interface {
+	CreateAPIKey(controller *fiber.Ctx) error
+	ListAPIKeys(controller *fiber.Ctx) error
+	RevokeAPIKey(controller *fiber.Ctx) error
+}
+
+type APIKeyControllerImplementation struct {
+	APIKeyService service.APIKeyService
+}
+
+func NewAPIKeyController(apiKeyService service.APIKeyService) APIKeyController {
+	return &APIKeyControllerImplementation{
+		APIKeyService: apiKeyService,
+	}
+}
+
+// CreateAPIKey implements APIKeyController.
+// Key asli hanya ada di response ini dan tidak bisa ditampilkan lagi.
+func (a *APIKeyControllerImplementation) CreateAPIKey(controller *fiber.Ctx) error {
+	userId, _ := controller.Locals("userID").(uint)
+
+	var req models.CreateAPIKeyRequest
+	if err := controller.BodyParser(&req); err != nil {
+		return controller.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request",
+		})
+	}
+
+	key, err := a.APIKeyService.Create(controller.Context(), userId, &req)
+	if err != nil {
+		if body, ok := validationErrorBody(err); ok {
+			return controller.Status(fiber.StatusUnprocessableEntity).JSON(body)
+		}
+		if errors.Is(err, service.ErrTooManyAPIKeys) {
+			return controller.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		log.Printf("error creating API key: %v", err)
+		return controller.Status(500).JSON(fiber.Map{
+			"error": "failed to create API key",
+		})
+	}
+	return controller.Status(fiber.StatusCreated).JSON(key)
+}
+
+// ListAPIKeys implements APIKeyController.
+func (a *APIKeyControllerImplementation) ListAPIKeys(controller *fiber.Ctx) error {
+	userId, _ := controller.Locals("userID").(uint)
+
+	keys, err := a.APIKeyService.List(controller.Context(), userId)
+	if err != nil {
+		log.Printf("error listing API keys: %v", err)
+		return controller.Status(500).JSON(fiber.Map{
+			"error": "failed to list API keys",
+		})
+	}
+	return controller.Status(200).JSON(fiber.Map{
+		"api_keys": keys,
+	})
+}
+
+// RevokeAPIKey implements APIKeyController.
+func (a *APIKeyControllerImplementation) RevokeAPIKey(controller *fiber.Ctx) error {
+	userId, _ := controller.Locals("userID").(uint)
+	keyId, err := strconv.ParseInt(controller.Params("id"), 10, 64)
+	if err != nil || keyId <= 0 {
+		return controller.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid API key id",
+		})
+	}
+
+	if err := a.APIKeyService.Revoke(controller.Context(), userId, keyId); err != nil {
+		if errors.Is(err, service.ErrAPIKeyNotFound) {
+			return controller.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		log.Printf("error revoking API key: %v", err)
+		return controller.Status(500).JSON(fiber.Map{
+			"error": "failed to revoke API key",
+		})
+	}
+	return controller.Status(200).JSON(fiber.Map{
+		"message": "API key revoked",
+	})
+}
@@ -0,0 +1,87 @@
+package controller
+
+import (
+	"log"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/imnzr/sim-service-project/internal/audit"
+	"github.com/imnzr/sim-service-project/models"
+)
+
+type AuditLogController interface {
+	SearchAuditLogs(controller *fiber.Ctx) error
+	VerifyAuditChain(controller *fiber.Ctx) error
+}
+
+type AuditLogControllerImplementation struct {
+	AuditLogger audit.Logger
+}
+
+func NewAuditLogController(auditLogger audit.Logger) AuditLogController {
+	return &AuditLogControllerImplementation{
+		AuditLogger: auditLogger,
+	}
+}
+
+// SearchAuditLogs implements AuditLogController.
+// Filter: ?actor_id=, ?action=, ?target_type=, ?target_id=, ?from=, ?to= (YYYY-MM-DD atau RFC3339), ?page=, ?limit=
+func (a *AuditLogControllerImplementation) SearchAuditLogs(controller *fiber.Ctx) error {
+	query := models.AuditLogQuery{
+		Action:     controller.Query("action"),
+		TargetType: controller.Query("target_type"),
+		TargetId:   controller.Query("target_id"),
+		Page:       controller.QueryInt("page", 1),
+		Limit:      controller.QueryInt("limit", 0),
+	}
+	if raw := controller.Query("actor_id"); raw != "" {
+		actorId, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil || actorId == 0 {
+			return controller.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid actor_id",
+			})
+		}
+		query.ActorUserId = uint(actorId)
+	}
+
+	from, err := parseDateQuery(controller.Query("from"))
+	if err != nil {
+		return controller.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid from date",
+		})
+	}
+	to, err := parseDateQuery(controller.Query("to"))
+	if err != nil {
+		return controller.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid to date",
+		})
+	}
+	query.From = from
+	query.To = to
+
+	page, err := a.AuditLogger.Search(controller.Context(), query)
+	if err != nil {
+		log.Printf("error searching audit logs: %v", err)
+		return controller.Status(500).JSON(fiber.Map{
+			"error": "failed to search audit logs",
+		})
+	}
+	return controller.Status(200).JSON(page)
+}
+
+// VerifyAuditChain implements AuditLogController.
+// Mengembalikan 409 jika hash chain putus (ada audit log yang diubah atau dihapus).
+func (a *AuditLogControllerImplementation) VerifyAuditChain(controller *fiber.Ctx) error {
+	report, err := a.AuditLogger.Verify(controller.Context())
+	if err != nil {
+		log.Printf("error verifying audit chain: %v", err)
+		return controller.Status(500).JSON(fiber.Map{
+			"error": "failed to verify audit log chain",
+		})
+	}
+	if !report.Valid {
+		log.Printf("AUDIT CHAIN BROKEN at id %d: %s", *report.BrokenAtId, report.Reason)
+		return controller.Status(fiber.StatusConflict).JSON(report)
+	}
+	return controller.Status(200).JSON(report)
+}
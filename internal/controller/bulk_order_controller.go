@@ -0,0 +1,54 @@
+package controller
+
+import (
+	"errors"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/imnzr/sim-service-project/internal/service"
+	"github.com/imnzr/sim-service-project/models"
+)
+
+type BulkOrderController interface {
+	CreateBulkOrder(controller *fiber.Ctx) error
+}
+
+type BulkOrderControllerImplementation struct {
+	BulkOrderService service.BulkOrderService
+}
+
+func NewBulkOrderController(bulkOrderService service.BulkOrderService) BulkOrderController {
+	return &BulkOrderControllerImplementation{
+		BulkOrderService: bulkOrderService,
+	}
+}
+
+// CreateBulkOrder implements BulkOrderController.
+// Response berisi hasil per nomor, nomor yang gagal dibeli sudah otomatis di-refund ke saldo.
+func (b *BulkOrderControllerImplementation) CreateBulkOrder(controller *fiber.Ctx) error {
+	userId, _ := controller.Locals("userID").(uint)
+
+	var req models.CreateBulkOrderRequest
+	if err := controller.BodyParser(&req); err != nil {
+		return controller.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request",
+		})
+	}
+
+	result, err := b.BulkOrderService.Create(controller.Context(), userId, &req)
+	if err != nil {
+		if body, ok := validationErrorBody(err); ok {
+			return controller.Status(fiber.StatusUnprocessableEntity).JSON(body)
+		}
+		if errors.Is(err, service.ErrWalletBalanceTooLow) {
+			return controller.Status(fiber.StatusPaymentRequired).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		log.Printf("error creating bulk order: %v", err)
+		return controller.Status(500).JSON(fiber.Map{
+			"error": "failed to create bulk order",
+		})
+	}
+	return controller.Status(fiber.StatusCreated).JSON(result)
+}
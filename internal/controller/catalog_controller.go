@@ -0,0 +1,112 @@
+package controller
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/imnzr/sim-service-project/internal/service"
+	"github.com/imnzr/sim-service-project/models"
+)
+
+type CatalogController interface {
+	ListCountries(controller *fiber.Ctx) error
+	ListServices(controller *fiber.Ctx) error
+	ListOperators(controller *fiber.Ctx) error
+	SearchProducts(controller *fiber.Ctx) error
+	AvailabilityTrend(controller *fiber.Ctx) error
+}
+
+type CatalogControllerImplementation struct {
+	CatalogService service.CatalogService
+}
+
+func NewCatalogController(catalogService service.CatalogService) CatalogController {
+	return &CatalogControllerImplementation{
+		CatalogService: catalogService,
+	}
+}
+
+// catalogQuery membaca ?q=, ?sort=, ?page= dan ?limit=.
+// userID hanya ada jika caller terautentikasi, dipakai untuk harga efektif tier.
+func catalogQuery(controller *fiber.Ctx) models.CatalogQuery {
+	userId, _ := controller.Locals("userID").(uint)
+	return models.CatalogQuery{
+		Search: controller.Query("q"),
+		Sort:   controller.Query("sort"),
+		Page:   controller.QueryInt("page", 1),
+		Limit:  controller.QueryInt("limit", 0),
+		UserId: userId,
+	}
+}
+
+// ListCountries implements CatalogController.
+func (c *CatalogControllerImplementation) ListCountries(controller *fiber.Ctx) error {
+	page, err := c.CatalogService.ListCountries(controller.Context(), catalogQuery(controller))
+	if err != nil {
+		return controller.Status(500).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return controller.Status(200).JSON(page)
+}
+
+// ListServices implements CatalogController.
+func (c *CatalogControllerImplementation) ListServices(controller *fiber.Ctx) error {
+	page, err := c.CatalogService.ListServices(controller.Context(), controller.Params("country"), catalogQuery(controller))
+	if err != nil {
+		return controller.Status(500).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return controller.Status(200).JSON(page)
+}
+
+// ListOperators implements CatalogController.
+func (c *CatalogControllerImplementation) ListOperators(controller *fiber.Ctx) error {
+	page, err := c.CatalogService.ListOperators(controller.Context(), controller.Params("country"), controller.Params("service"), catalogQuery(controller))
+	if err != nil {
+		return controller.Status(500).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return controller.Status(200).JSON(page)
+}
+
+// SearchProducts implements CatalogController.
+func (c *CatalogControllerImplementation) SearchProducts(controller *fiber.Ctx) error {
+	page, err := c.CatalogService.SearchProducts(controller.Context(), catalogQuery(controller))
+	if err != nil {
+		return controller.Status(500).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return controller.Status(200).JSON(page)
+}
+
+// AvailabilityTrend implements CatalogController.
+// ?hours= menentukan rentang waktu (default 24, maksimal 168).
+func (c *CatalogControllerImplementation) AvailabilityTrend(controller *fiber.Ctx) error {
+	hours := controller.QueryInt("hours", 24)
+	if hours < 1 || hours > 168 {
+		return controller.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "hours must be between 1 and 168",
+		})
+	}
+
+	trend, err := c.CatalogService.AvailabilityTrend(controller.Context(),
+		controller.Params("service"), controller.Params("country"), controller.Params("operator"),
+		time.Duration(hours)*time.Hour,
+	)
+	if err != nil {
+		if errors.Is(err, service.ErrProductNotFound) {
+			return controller.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return controller.Status(500).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return controller.Status(200).JSON(trend)
+}
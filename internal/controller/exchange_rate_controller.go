@@ -0,0 +1,110 @@
+package controller
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/imnzr/sim-service-project/internal/service"
+	"github.com/imnzr/sim-service-project/models"
+)
+
+type ExchangeRateController interface {
+	GetCurrentRate(controller *fiber.Ctx) error
+	RefreshRate(controller *fiber.Ctx) error
+	SetManualRate(controller *fiber.Ctx) error
+	ClearManualRate(controller *fiber.Ctx) error
+}
+
+type ExchangeRateControllerImplementation struct {
+	ExchangeRateService service.ExchangeRateService
+}
+
+func NewExchangeRateController(exchangeRateService service.ExchangeRateService) ExchangeRateController {
+	return &ExchangeRateControllerImplementation{
+		ExchangeRateService: exchangeRateService,
+	}
+}
+
+// pair membaca pasangan mata uang dari query, default RUB/IDR.
+// Pasangan lain ditolak agar endpoint publik tidak memicu fetch ke source untuk kurs sembarang.
+func pair(controller *fiber.Ctx) (string, string, error) {
+	base := strings.ToUpper(controller.Query("base", service.CurrencyRUB))
+	quote := strings.ToUpper(controller.Query("quote", service.CurrencyIDR))
+	if !service.SupportedCurrencyPair(base, quote) {
+		return "", "", service.ErrUnsupportedCurrencyPair
+	}
+	return base, quote, nil
+}
+
+// GetCurrentRate implements ExchangeRateController.
+func (e *ExchangeRateControllerImplementation) GetCurrentRate(controller *fiber.Ctx) error {
+	base, quote, err := pair(controller)
+	if err != nil {
+		return controller.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	rate, err := e.ExchangeRateService.CurrentRate(controller.Context(), base, quote)
+	if err != nil {
+		return controller.Status(500).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return controller.Status(200).JSON(rate)
+}
+
+// RefreshRate implements ExchangeRateController.
+func (e *ExchangeRateControllerImplementation) RefreshRate(controller *fiber.Ctx) error {
+	base, quote, err := pair(controller)
+	if err != nil {
+		return controller.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	rate, err := e.ExchangeRateService.Refresh(controller.Context(), base, quote)
+	if err != nil {
+		return controller.Status(fiber.StatusBadGateway).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return controller.Status(200).JSON(rate)
+}
+
+// SetManualRate implements ExchangeRateController.
+func (e *ExchangeRateControllerImplementation) SetManualRate(controller *fiber.Ctx) error {
+	var req models.ManualExchangeRateRequest
+	if err := controller.BodyParser(&req); err != nil {
+		return controller.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request",
+		})
+	}
+
+	rate, err := e.ExchangeRateService.SetManualRate(controller.Context(), &req)
+	if err != nil {
+		return controller.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return controller.Status(200).JSON(rate)
+}
+
+// ClearManualRate implements ExchangeRateController.
+func (e *ExchangeRateControllerImplementation) ClearManualRate(controller *fiber.Ctx) error {
+	base, quote, err := pair(controller)
+	if err != nil {
+		return controller.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	if err := e.ExchangeRateService.ClearManualRate(controller.Context(), base, quote); err != nil {
+		return controller.Status(500).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return controller.Status(200).JSON(fiber.Map{
+		"message": "override kurs manual dihapus",
+	})
+}
@@ -2,9 +2,13 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"log"
+	"strconv"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/imnzr/sim-service-project/internal/audit"
+	"github.com/imnzr/sim-service-project/internal/lock"
 	xenditpayment "github.com/imnzr/sim-service-project/internal/payment_gateway/xendit_payment"
 	"github.com/imnzr/sim-service-project/internal/repository"
 	"github.com/imnzr/sim-service-project/internal/service"
@@ -20,14 +24,36 @@ type OrderController interface {
 type OrderControllerImplement struct {
 	simOrderRepo         repository.SimOrderRepository
 	simOrderService      service.OrderService
+	productService       service.ProductService
+	priceTierService     service.PriceTierService
 	XenditPaymentService xenditpayment.XenditPayment
+	auditLogger          audit.Logger
+	orderLock            *lock.RedisLock
 }
 
-func NewOrderController(simOrderRepository repository.SimOrderRepository, simOrderService service.OrderService, xenditPaymentService xenditpayment.XenditPayment) OrderController {
+func NewOrderController(simOrderRepository repository.SimOrderRepository, simOrderService service.OrderService, productService service.ProductService, priceTierService service.PriceTierService, xenditPaymentService xenditpayment.XenditPayment, auditLogger audit.Logger, orderLock *lock.RedisLock) OrderController {
 	return &OrderControllerImplement{
 		simOrderRepo:         simOrderRepository,
 		XenditPaymentService: xenditPaymentService,
 		simOrderService:      simOrderService,
+		productService:       productService,
+		priceTierService:     priceTierService,
+		auditLogger:          auditLogger,
+		orderLock:            orderLock,
+	}
+}
+
+// Aksi audit log untuk order dan pembayaran
+const (
+	auditActionOrderCreate = "order.create"
+	auditActionOrderFulfil = "order.fulfil"
+	auditActionPaymentPaid = "payment.paid"
+)
+
+// audit mencatat aksi order/pembayaran, kegagalan hanya di-log agar checkout dan webhook tetap jalan
+func (o *OrderControllerImplement) audit(ctx context.Context, entry audit.Entry) {
+	if err := o.auditLogger.Record(ctx, entry); err != nil {
+		log.Printf("failed to write audit log %s for %s %s: %v", entry.Action, entry.TargetType, entry.TargetId, err)
 	}
 }
 
@@ -56,10 +82,9 @@ func (o *OrderControllerImplement) CreateOrder(ctx *fiber.Ctx) error {
 	userID := ctx.Locals("userID").(uint)
 
 	var req struct {
-		Service   string  `json:"service"`
-		Country   string  `json:"country"`
-		Operator  string  `json:"operator"`
-		PriceSell float64 `json:"price_sell"`
+		Service  string `json:"service"`
+		Country  string `json:"country"`
+		Operator string `json:"operator"`
 	}
 
 	if err := ctx.BodyParser(&req); err != nil {
@@ -67,12 +92,29 @@ func (o *OrderControllerImplement) CreateOrder(ctx *fiber.Ctx) error {
 			"error": err.Error(),
 		})
 	}
+
+	// harga diambil di server dari katalog, sama dengan yang ditampilkan ke customer
+	quote, err := o.productService.QuotePrice(ctx.Context(), req.Service, req.Country, req.Operator)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	// diskon tier reseller diterapkan di atas harga katalog
+	if err := o.priceTierService.ApplyToQuote(ctx.Context(), userID, quote); err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
 	order := &models.SimOrder{
-		UserId:    int(userID),
-		Service:   req.Service,
-		Country:   req.Country,
-		Operator:  req.Operator,
-		PriceSell: req.PriceSell,
+		UserId:         int(userID),
+		Service:        req.Service,
+		Country:        req.Country,
+		Operator:       req.Operator,
+		PriceSell:      quote.PriceSell,
+		ExchangeRate:   &quote.ExchangeRate,
+		ExchangeRateId: &quote.ExchangeRateId,
 	}
 
 	createOrder, err := o.XenditPaymentService.CreateOrderAndStartPayment(ctx, order)
@@ -82,6 +124,14 @@ func (o *OrderControllerImplement) CreateOrder(ctx *fiber.Ctx) error {
 		})
 	}
 
+	o.audit(ctx.Context(), audit.Entry{
+		ActorUserId: userID,
+		Action:      auditActionOrderCreate,
+		TargetType:  audit.TargetOrder,
+		TargetId:    strconv.Itoa(order.Id),
+		After:       audit.OrderSnapshot(order),
+	})
+
 	responseWeb := xenditpayment.ResponsePayment{
 		Success: true,
 		Data: xenditpayment.DataResponsePayment{
@@ -131,13 +181,14 @@ func (o *OrderControllerImplement) HandleWebhook(ctx *fiber.Ctx) error {
 		})
 	}
 
-	if order.Status == "PAID" {
+	// nomor yang sudah dibeli mengubah status order mengikuti 5sim, jadi cek juga sim_order_service_id
+	if order.Status == models.OrderStatusPaid || order.SimOrderServiceId != nil {
 		log.Println("ℹ️ Order sudah PAID sebelumnya, skip update.")
 		return ctx.SendStatus(fiber.StatusOK)
 	}
 
 	// 2. Update status ke PAID
-	err = o.XenditPaymentService.UpdateStatusByInvoiceId(ctx, payload.ExternalId, "PAID")
+	err = o.XenditPaymentService.UpdateStatusByInvoiceId(ctx, payload.ExternalId, models.OrderStatusPaid)
 	if err != nil {
 		log.Println("❌ Gagal update status:", err)
 		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -146,33 +197,82 @@ func (o *OrderControllerImplement) HandleWebhook(ctx *fiber.Ctx) error {
 	}
 	log.Println("✅ Status order diupdate ke PAID")
 
-	// 3. Pesan nomor ke 5sim
-	resultOrder, err := o.simOrderService.BuyNumberFromService(context.Background(), order.Service, order.Country, order.Operator)
+	// webhook dipanggil xendit, tidak ada actor user
+	paidOrder := *order
+	paidOrder.Status = models.OrderStatusPaid
+	o.audit(ctx.Context(), audit.Entry{
+		Action:     auditActionPaymentPaid,
+		TargetType: audit.TargetPayment,
+		TargetId:   payload.ExternalId,
+		Before:     audit.OrderSnapshot(order),
+		After:      audit.OrderSnapshot(&paidOrder),
+		Metadata:   map[string]any{"order_id": order.Id, "xendit_invoice_id": payload.Id},
+	})
+
+	// volume bulanan bertambah, reseller bisa naik tier. Gagal di sini tidak menghentikan fulfilment
+	if err := o.priceTierService.EvaluateUpgrade(ctx.Context(), uint(order.UserId)); err != nil {
+		log.Printf("failed to evaluate price tier upgrade for user %d: %v", order.UserId, err)
+	}
+
+	// 3. Pesan nomor ke 5sim dengan lock yang sama seperti retry admin. Cek "sudah PAID" di atas bukan
+	// jaminan, webhook Xendit yang dikirim ulang atau retry admin bisa berjalan bersamaan.
+	lockKey := service.OrderFulfilLockKey(order.Id)
+	token, err := o.orderLock.Acquire(ctx.Context(), lockKey, service.OrderFulfilLockTTL)
 	if err != nil {
-		log.Println("❌ Gagal beli nomor dari 5sim:", err)
-		return ctx.Status(fiber.StatusBadGateway).JSON(fiber.Map{
-			"error": "Gagal beli nomor dari 5sim",
+		if errors.Is(err, lock.ErrNotAcquired) {
+			log.Printf("ℹ️ Order %d sedang diproses, skip fulfilment", order.Id)
+			return ctx.SendStatus(fiber.StatusOK)
+		}
+		log.Println("❌ Gagal ambil lock fulfilment:", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Gagal memproses order",
 		})
 	}
-	log.Println("✅ Nomor berhasil dipesan dari 5sim")
+	defer func() {
+		if err := o.orderLock.Release(context.Background(), lockKey, token); err != nil {
+			log.Printf("failed to release fulfilment lock for order %d: %v", order.Id, err)
+		}
+	}()
 
-	if resultOrder == nil {
-		log.Println("⚠️ Tidak ada data untuk disimpan (resultOrder nil)")
-		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Data 5sim tidak tersedia",
+	// order dibaca ulang setelah lock didapat, proses lain mungkin sudah membeli nomornya
+	current, err := o.simOrderRepo.GetById(ctx.Context(), order.Id)
+	if err != nil || current == nil {
+		log.Println("❌ Gagal baca ulang order:", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Gagal memproses order",
 		})
 	}
-	log.Printf("✅ Menyimpan data SIM: OrderID=%v (%T), SimServiceID=%d", order.Id, order.Id, resultOrder.Id)
+	if current.SimOrderServiceId != nil {
+		log.Println("ℹ️ Nomor untuk order ini sudah dibeli, skip fulfilment.")
+		return ctx.SendStatus(fiber.StatusOK)
+	}
+	if current.RefundedAt != nil {
+		log.Printf("ℹ️ Order %d sudah di-refund, skip fulfilment", order.Id)
+		return ctx.SendStatus(fiber.StatusOK)
+	}
 
-	// 4. Simpan hasil pembelian ke database
-	err = o.simOrderRepo.AttachSimDataService(context.Background(), order.Id, resultOrder)
+	// Jika gagal, admin bisa retry lewat /admin/orders/:id/retry
+	resultOrder, err := o.simOrderService.FulfillOrder(context.Background(), current)
+	fulfilEntry := audit.Entry{
+		Action:     auditActionOrderFulfil,
+		TargetType: audit.TargetOrder,
+		TargetId:   strconv.Itoa(order.Id),
+		Before:     audit.OrderSnapshot(&paidOrder),
+		Metadata:   map[string]any{"success": true},
+	}
 	if err != nil {
-		log.Println("❌ Gagal simpan data SIM:", err)
-		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Gagal simpan hasil dari 5sim",
+		fulfilEntry.Metadata = map[string]any{"success": false, "error": err.Error()}
+	} else if fulfilled, getErr := o.simOrderRepo.GetById(ctx.Context(), order.Id); getErr == nil {
+		fulfilEntry.After = audit.OrderSnapshot(fulfilled)
+	}
+	o.audit(ctx.Context(), fulfilEntry)
+	if err != nil {
+		log.Println("❌ Gagal beli nomor dari 5sim:", err)
+		return ctx.Status(fiber.StatusBadGateway).JSON(fiber.Map{
+			"error": "Gagal beli nomor dari 5sim",
 		})
 	}
-	log.Println("✅ Data 5sim berhasil disimpan ke database")
+	log.Printf("✅ Data 5sim berhasil disimpan: OrderID=%d, SimServiceID=%d", order.Id, resultOrder.Id)
 
 	return ctx.Status(200).JSON(fiber.Map{
 		"message": "Webhook processed successfully",
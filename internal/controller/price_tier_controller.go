@@ -0,0 +1,176 @@
+package controller
+
+import (
+	"errors"
+	"log"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/imnzr/sim-service-project/internal/service"
+	"github.com/imnzr/sim-service-project/models"
+)
+
+type PriceTierController interface {
+	ListTiers(controller *fiber.Ctx) error
+	GetTier(controller *fiber.Ctx) error
+	CreateTier(controller *fiber.Ctx) error
+	UpdateTier(controller *fiber.Ctx) error
+	DeleteTier(controller *fiber.Ctx) error
+	AssignUserTier(controller *fiber.Ctx) error
+	GetMyTier(controller *fiber.Ctx) error
+}
+
+type PriceTierControllerImplementation struct {
+	PriceTierService service.PriceTierService
+}
+
+func NewPriceTierController(priceTierService service.PriceTierService) PriceTierController {
+	return &PriceTierControllerImplementation{
+		PriceTierService: priceTierService,
+	}
+}
+
+// priceTierError memetakan error tier harga ke status HTTP
+func priceTierError(controller *fiber.Ctx, err error) error {
+	if body, ok := validationErrorBody(err); ok {
+		return controller.Status(fiber.StatusUnprocessableEntity).JSON(body)
+	}
+	switch {
+	case errors.Is(err, service.ErrPriceTierNotFound),
+		errors.Is(err, service.ErrUserNotFound):
+		return controller.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, service.ErrPriceTierInactive),
+		errors.Is(err, service.ErrDuplicatePriceTier):
+		return controller.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	log.Printf("price tier error: %v", err)
+	return controller.Status(500).JSON(fiber.Map{
+		"error": err.Error(),
+	})
+}
+
+func tierIdParam(controller *fiber.Ctx) (int, bool) {
+	id, err := strconv.Atoi(controller.Params("id"))
+	return id, err == nil && id > 0
+}
+
+func invalidTierId(controller *fiber.Ctx) error {
+	return controller.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		"error": "invalid tier id",
+	})
+}
+
+// ListTiers implements PriceTierController.
+func (p *PriceTierControllerImplementation) ListTiers(controller *fiber.Ctx) error {
+	tiers, err := p.PriceTierService.List(controller.Context())
+	if err != nil {
+		return priceTierError(controller, err)
+	}
+	return controller.Status(200).JSON(fiber.Map{
+		"tiers": tiers,
+	})
+}
+
+// GetTier implements PriceTierController.
+func (p *PriceTierControllerImplementation) GetTier(controller *fiber.Ctx) error {
+	id, ok := tierIdParam(controller)
+	if !ok {
+		return invalidTierId(controller)
+	}
+
+	tier, err := p.PriceTierService.Get(controller.Context(), id)
+	if err != nil {
+		return priceTierError(controller, err)
+	}
+	return controller.Status(200).JSON(tier)
+}
+
+// CreateTier implements PriceTierController.
+func (p *PriceTierControllerImplementation) CreateTier(controller *fiber.Ctx) error {
+	var req models.PriceTierRequest
+	if err := controller.BodyParser(&req); err != nil {
+		return controller.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request",
+		})
+	}
+
+	tier, err := p.PriceTierService.Create(controller.Context(), &req)
+	if err != nil {
+		return priceTierError(controller, err)
+	}
+	return controller.Status(fiber.StatusCreated).JSON(tier)
+}
+
+// UpdateTier implements PriceTierController.
+func (p *PriceTierControllerImplementation) UpdateTier(controller *fiber.Ctx) error {
+	id, ok := tierIdParam(controller)
+	if !ok {
+		return invalidTierId(controller)
+	}
+
+	var req models.PriceTierRequest
+	if err := controller.BodyParser(&req); err != nil {
+		return controller.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request",
+		})
+	}
+
+	tier, err := p.PriceTierService.Update(controller.Context(), id, &req)
+	if err != nil {
+		return priceTierError(controller, err)
+	}
+	return controller.Status(200).JSON(tier)
+}
+
+// DeleteTier implements PriceTierController.
+func (p *PriceTierControllerImplementation) DeleteTier(controller *fiber.Ctx) error {
+	id, ok := tierIdParam(controller)
+	if !ok {
+		return invalidTierId(controller)
+	}
+
+	if err := p.PriceTierService.Delete(controller.Context(), id); err != nil {
+		return priceTierError(controller, err)
+	}
+	return controller.Status(200).JSON(fiber.Map{
+		"message": "price tier deleted",
+	})
+}
+
+// AssignUserTier implements PriceTierController.
+// Body {"tier_id": null} mengembalikan user ke harga retail.
+func (p *PriceTierControllerImplementation) AssignUserTier(controller *fiber.Ctx) error {
+	userId, ok := userIdParam(controller)
+	if !ok {
+		return invalidUserId(controller)
+	}
+	actorId, _ := controller.Locals("userID").(uint)
+
+	var req models.AssignPriceTierRequest
+	if err := controller.BodyParser(&req); err != nil {
+		return controller.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request",
+		})
+	}
+
+	user, err := p.PriceTierService.AssignTier(controller.Context(), actorId, userId, req.TierId)
+	if err != nil {
+		return priceTierError(controller, err)
+	}
+	return controller.Status(200).JSON(user)
+}
+
+// GetMyTier implements PriceTierController.
+func (p *PriceTierControllerImplementation) GetMyTier(controller *fiber.Ctx) error {
+	userId := controller.Locals("userID").(uint)
+
+	summary, err := p.PriceTierService.Summary(controller.Context(), userId)
+	if err != nil {
+		return priceTierError(controller, err)
+	}
+	return controller.Status(200).JSON(summary)
+}
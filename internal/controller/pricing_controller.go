@@ -0,0 +1,188 @@
+package controller
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/imnzr/sim-service-project/internal/service"
+	"github.com/imnzr/sim-service-project/models"
+)
+
+type PricingController interface {
+	ListRules(controller *fiber.Ctx) error
+	GetRule(controller *fiber.Ctx) error
+	CreateRule(controller *fiber.Ctx) error
+	UpdateRule(controller *fiber.Ctx) error
+	DeleteRule(controller *fiber.Ctx) error
+	DryRun(controller *fiber.Ctx) error
+}
+
+type PricingControllerImplementation struct {
+	PricingService service.PricingService
+}
+
+func NewPricingController(pricingService service.PricingService) PricingController {
+	return &PricingControllerImplementation{
+		PricingService: pricingService,
+	}
+}
+
+// pricingRuleRequest dipakai agar field active bisa dibedakan antara false dan tidak dikirim
+type pricingRuleRequest struct {
+	Name          string  `json:"name"`
+	Service       string  `json:"service"`
+	Country       string  `json:"country"`
+	Operator      string  `json:"operator"`
+	Priority      int     `json:"priority"`
+	MarkupPercent float64 `json:"markup_percent"`
+	MarkupFixed   float64 `json:"markup_fixed"`
+	MinMargin     float64 `json:"min_margin"`
+	RoundTo       float64 `json:"round_to"`
+	Active        *bool   `json:"active"`
+}
+
+func (r pricingRuleRequest) toRule(id int) models.PricingRule {
+	active := true
+	if r.Active != nil {
+		active = *r.Active
+	}
+	return models.PricingRule{
+		Id:            id,
+		Name:          r.Name,
+		Service:       r.Service,
+		Country:       r.Country,
+		Operator:      r.Operator,
+		Priority:      r.Priority,
+		MarkupPercent: r.MarkupPercent,
+		MarkupFixed:   r.MarkupFixed,
+		MinMargin:     r.MinMargin,
+		RoundTo:       r.RoundTo,
+		Active:        active,
+	}
+}
+
+// ListRules implements PricingController.
+func (p *PricingControllerImplementation) ListRules(controller *fiber.Ctx) error {
+	rules, err := p.PricingService.ListRules(controller.Context())
+	if err != nil {
+		return controller.Status(500).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return controller.Status(200).JSON(fiber.Map{
+		"rules": rules,
+	})
+}
+
+// GetRule implements PricingController.
+func (p *PricingControllerImplementation) GetRule(controller *fiber.Ctx) error {
+	id, err := strconv.Atoi(controller.Params("id"))
+	if err != nil {
+		return controller.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid rule id",
+		})
+	}
+
+	rule, err := p.PricingService.GetRule(controller.Context(), id)
+	if err != nil {
+		return controller.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return controller.Status(200).JSON(rule)
+}
+
+// CreateRule implements PricingController.
+func (p *PricingControllerImplementation) CreateRule(controller *fiber.Ctx) error {
+	var req pricingRuleRequest
+	if err := controller.BodyParser(&req); err != nil {
+		return controller.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request",
+		})
+	}
+
+	rule := req.toRule(0)
+	if err := p.PricingService.CreateRule(controller.Context(), &rule); err != nil {
+		return controller.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return controller.Status(fiber.StatusCreated).JSON(rule)
+}
+
+// UpdateRule implements PricingController.
+func (p *PricingControllerImplementation) UpdateRule(controller *fiber.Ctx) error {
+	id, err := strconv.Atoi(controller.Params("id"))
+	if err != nil {
+		return controller.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid rule id",
+		})
+	}
+
+	var req pricingRuleRequest
+	if err := controller.BodyParser(&req); err != nil {
+		return controller.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request",
+		})
+	}
+
+	rule := req.toRule(id)
+	if err := p.PricingService.UpdateRule(controller.Context(), &rule); err != nil {
+		return controller.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return controller.Status(200).JSON(rule)
+}
+
+// DeleteRule implements PricingController.
+func (p *PricingControllerImplementation) DeleteRule(controller *fiber.Ctx) error {
+	id, err := strconv.Atoi(controller.Params("id"))
+	if err != nil {
+		return controller.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid rule id",
+		})
+	}
+
+	if err := p.PricingService.DeleteRule(controller.Context(), id); err != nil {
+		return controller.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return controller.Status(200).JSON(fiber.Map{
+		"message": "pricing rule dihapus",
+	})
+}
+
+// DryRun implements PricingController.
+// Body: {"rule_id": 3, "rule": {...}, "delete": false, "limit": 100}
+func (p *PricingControllerImplementation) DryRun(controller *fiber.Ctx) error {
+	var req struct {
+		RuleId int                `json:"rule_id"`
+		Rule   pricingRuleRequest `json:"rule"`
+		Delete bool               `json:"delete"`
+		Limit  int                `json:"limit"`
+	}
+	if err := controller.BodyParser(&req); err != nil {
+		return controller.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request",
+		})
+	}
+	if req.Delete && req.RuleId == 0 {
+		return controller.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "rule_id is required when delete is true",
+		})
+	}
+
+	result, err := p.PricingService.DryRun(controller.Context(), &service.PricingDryRunRequest{
+		Rule:   req.Rule.toRule(req.RuleId),
+		Delete: req.Delete,
+		Limit:  req.Limit,
+	})
+	if err != nil {
+		return controller.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return controller.Status(200).JSON(result)
+}
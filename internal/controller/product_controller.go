@@ -1,38 +1,46 @@
 package controller
 
 import (
+	"errors"
+	"time"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/imnzr/sim-service-project/internal/service"
+	"github.com/imnzr/sim-service-project/models"
 )
 
 type ProductController interface {
 	GetProductAvailable(controller *fiber.Ctx) error
 	SyncFromSimServices(controller *fiber.Ctx) error
+	GetLatestSyncRun(controller *fiber.Ctx) error
+	GetPriceHistory(controller *fiber.Ctx) error
 }
 
 type ProductControllerImplementation struct {
-	ProductService service.ProductService
+	ProductService     service.ProductService
+	CatalogSyncService service.CatalogSyncService
 }
 
-func NewProductController(productService service.ProductService) ProductController {
+func NewProductController(productService service.ProductService, catalogSyncService service.CatalogSyncService) ProductController {
 	return &ProductControllerImplementation{
-		ProductService: productService,
+		ProductService:     productService,
+		CatalogSyncService: catalogSyncService,
 	}
 }
 
 // GetProductAvailable implements ProductController.
 func (p *ProductControllerImplementation) GetProductAvailable(controller *fiber.Ctx) error {
-	service := controller.Params("service")
-	country := controller.Params("country")
-	operator := controller.Params("operator")
+	service := controller.Query("service")
+	country := controller.Query("country")
+	operator := controller.Query("operator")
 
 	if service == "" || country == "" || operator == "" {
-		return controller.Status(500).JSON(fiber.Map{
+		return controller.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"message": "service, country, and operator are required",
 		})
 	}
 
-	products, err := p.ProductService.GetProductAvailable(service, country, operator)
+	products, err := p.ProductService.GetProductAvailable(controller.Context(), service, country, operator)
 	if err != nil {
 		return controller.Status(500).JSON(fiber.Map{
 			"error": err.Error(),
@@ -48,13 +56,84 @@ func (p *ProductControllerImplementation) GetProductAvailable(controller *fiber.
 
 // SyncFromSimServices implements ProductController.
 func (p *ProductControllerImplementation) SyncFromSimServices(controller *fiber.Ctx) error {
-	err := p.ProductService.SyncFromSimServices(controller.Context())
+	run, err := p.CatalogSyncService.Run(controller.Context(), service.SyncTriggerManual)
 	if err != nil {
+		if errors.Is(err, service.ErrSyncInProgress) {
+			return controller.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
 		return controller.Status(500).JSON(fiber.Map{
 			"error": err.Error(),
+			"run":   run,
 		})
 	}
 	return controller.Status(200).JSON(fiber.Map{
 		"message": "product berhasil disinkronasi dari service",
+		"run":     run,
+	})
+}
+
+// GetLatestSyncRun implements ProductController.
+func (p *ProductControllerImplementation) GetLatestSyncRun(controller *fiber.Ctx) error {
+	run, err := p.CatalogSyncService.LatestRun(controller.Context())
+	if err != nil {
+		return controller.Status(500).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if run == nil {
+		return controller.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "belum ada sync yang dijalankan",
+		})
+	}
+	return controller.Status(200).JSON(run)
+}
+
+// parseDateQuery membaca query tanggal dengan format YYYY-MM-DD atau RFC3339
+func parseDateQuery(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// GetPriceHistory implements ProductController.
+func (p *ProductControllerImplementation) GetPriceHistory(controller *fiber.Ctx) error {
+	from, err := parseDateQuery(controller.Query("from"))
+	if err != nil {
+		return controller.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid from date",
+		})
+	}
+	to, err := parseDateQuery(controller.Query("to"))
+	if err != nil {
+		return controller.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid to date",
+		})
+	}
+
+	history, err := p.ProductService.GetPriceHistory(controller.Context(), models.PriceHistoryQuery{
+		Service:  controller.Query("service"),
+		Country:  controller.Query("country"),
+		Operator: controller.Query("operator"),
+		From:     from,
+		To:       to,
+		Limit:    controller.QueryInt("limit", 100),
+	})
+	if err != nil {
+		return controller.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return controller.Status(200).JSON(fiber.Map{
+		"history": history,
 	})
 }
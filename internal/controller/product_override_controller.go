@@ -0,0 +1,75 @@
+package controller
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/imnzr/sim-service-project/internal/service"
+	"github.com/imnzr/sim-service-project/models"
+)
+
+type ProductOverrideController interface {
+	ListOverrides(controller *fiber.Ctx) error
+	UpsertOverride(controller *fiber.Ctx) error
+	DeleteOverride(controller *fiber.Ctx) error
+}
+
+type ProductOverrideControllerImplementation struct {
+	OverrideService service.ProductOverrideService
+}
+
+func NewProductOverrideController(overrideService service.ProductOverrideService) ProductOverrideController {
+	return &ProductOverrideControllerImplementation{
+		OverrideService: overrideService,
+	}
+}
+
+// ListOverrides implements ProductOverrideController.
+func (p *ProductOverrideControllerImplementation) ListOverrides(controller *fiber.Ctx) error {
+	overrides, err := p.OverrideService.List(controller.Context(), controller.Query("service"))
+	if err != nil {
+		return controller.Status(500).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return controller.Status(200).JSON(fiber.Map{
+		"overrides": overrides,
+	})
+}
+
+// UpsertOverride implements ProductOverrideController.
+// Body: {"service": "telegram", "country": "", "operator": "", "enabled": false, "pinned_price": 15000, "display_name": "Telegram", "icon_url": "..."}
+func (p *ProductOverrideControllerImplementation) UpsertOverride(controller *fiber.Ctx) error {
+	var override models.ProductOverride
+	if err := controller.BodyParser(&override); err != nil {
+		return controller.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request",
+		})
+	}
+
+	if err := p.OverrideService.Upsert(controller.Context(), &override); err != nil {
+		return controller.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return controller.Status(200).JSON(override)
+}
+
+// DeleteOverride implements ProductOverrideController.
+func (p *ProductOverrideControllerImplementation) DeleteOverride(controller *fiber.Ctx) error {
+	id, err := strconv.Atoi(controller.Params("id"))
+	if err != nil {
+		return controller.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid override id",
+		})
+	}
+
+	if err := p.OverrideService.Delete(controller.Context(), id); err != nil {
+		return controller.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return controller.Status(200).JSON(fiber.Map{
+		"message": "product override dihapus",
+	})
+}
@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"errors"
 	"log"
 
 	"github.com/gofiber/fiber/v2"
@@ -12,6 +13,51 @@ type UserController interface {
 	Register(controller *fiber.Ctx) error
 	Login(controller *fiber.Ctx) error
 	GetProfile(controller *fiber.Ctx) error
+	RefreshToken(controller *fiber.Ctx) error
+	Logout(controller *fiber.Ctx) error
+	LogoutAll(controller *fiber.Ctx) error
+	ListSessions(controller *fiber.Ctx) error
+	ForgotPassword(controller *fiber.Ctx) error
+	ResetPassword(controller *fiber.Ctx) error
+	VerifyEmail(controller *fiber.Ctx) error
+	ResendVerification(controller *fiber.Ctx) error
+	UpdateProfile(controller *fiber.Ctx) error
+	ChangeEmail(controller *fiber.Ctx) error
+	ChangePassword(controller *fiber.Ctx) error
+	DeleteAccount(controller *fiber.Ctx) error
+}
+
+// maxUserAgentLength mengikuti panjang kolom user_sessions.user_agent
+const maxUserAgentLength = 255
+
+// clientInfo mengambil ip dan user agent request untuk dicatat di sesi login
+func clientInfo(controller *fiber.Ctx) models.ClientInfo {
+	userAgent := controller.Get(fiber.HeaderUserAgent)
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+	return models.ClientInfo{
+		IPAddress: controller.IP(),
+		UserAgent: userAgent,
+	}
+}
+
+// validationErrorBody membentuk body response 422 dengan pesan per field jika err adalah ValidationError
+func validationErrorBody(err error) (fiber.Map, bool) {
+	var validationErr *service.ValidationError
+	if !errors.As(err, &validationErr) {
+		return nil, false
+	}
+	return fiber.Map{
+		"error":  validationErr.Error(),
+		"fields": validationErr.Fields,
+	}, true
+}
+
+// tokenClaims mengambil claims access token yang disimpan oleh AuthMiddleware
+func tokenClaims(controller *fiber.Ctx) (*service.TokenClaims, bool) {
+	claims, ok := controller.Locals("tokenClaims").(*service.TokenClaims)
+	return claims, ok
 }
 
 type UserControllerImplement struct {
@@ -50,31 +96,260 @@ func (u *UserControllerImplement) Login(controller *fiber.Ctx) error {
 	var req models.LoginRequest
 
 	if err := controller.BodyParser(&req); err != nil {
-		return controller.Status(500).JSON(fiber.Map{
-			"error": err.Error(),
+		return controller.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request",
 		})
 	}
 
-	resp, err := u.userService.Login(controller.Context(), &req)
+	resp, err := u.userService.Login(controller.Context(), &req, clientInfo(controller))
 	if err != nil {
+		if body, ok := validationErrorBody(err); ok {
+			return controller.Status(fiber.StatusUnprocessableEntity).JSON(body)
+		}
+		if errors.Is(err, service.ErrInvalidCredentials) {
+			return controller.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		if errors.Is(err, service.ErrAccountSuspended) {
+			return controller.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
 		log.Printf("error logging user: %v", err)
 		return controller.Status(500).JSON(fiber.Map{
-			"error": err.Error(),
+			"error": "failed to login",
 		})
 	}
 
 	return controller.Status(200).JSON(resp)
 }
 
+// RefreshToken implements UserController.
+func (u *UserControllerImplement) RefreshToken(controller *fiber.Ctx) error {
+	var req models.RefreshTokenRequest
+	if err := controller.BodyParser(&req); err != nil || req.RefreshToken == "" {
+		return controller.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "refresh_token is required",
+		})
+	}
+
+	resp, err := u.userService.RefreshToken(controller.Context(), req.RefreshToken, clientInfo(controller))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidRefreshToken) || errors.Is(err, service.ErrRefreshTokenReused) {
+			return controller.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		if errors.Is(err, service.ErrAccountSuspended) {
+			return controller.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		log.Printf("error refreshing token: %v", err)
+		return controller.Status(500).JSON(fiber.Map{
+			"error": "failed to refresh token",
+		})
+	}
+
+	return controller.Status(200).JSON(resp)
+}
+
+// Logout implements UserController.
+func (u *UserControllerImplement) Logout(controller *fiber.Ctx) error {
+	claims, ok := tokenClaims(controller)
+	if !ok {
+		return controller.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "token claims not found in context",
+		})
+	}
+
+	if err := u.userService.Logout(controller.Context(), claims); err != nil {
+		log.Printf("error logout user %d: %v", claims.UserId, err)
+		return controller.Status(500).JSON(fiber.Map{
+			"error": "failed to logout",
+		})
+	}
+
+	return controller.Status(200).JSON(fiber.Map{
+		"success": "logged out",
+	})
+}
+
+// LogoutAll implements UserController.
+func (u *UserControllerImplement) LogoutAll(controller *fiber.Ctx) error {
+	claims, ok := tokenClaims(controller)
+	if !ok {
+		return controller.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "token claims not found in context",
+		})
+	}
+
+	if err := u.userService.LogoutAll(controller.Context(), claims.UserId); err != nil {
+		log.Printf("error logout all sessions user %d: %v", claims.UserId, err)
+		return controller.Status(500).JSON(fiber.Map{
+			"error": "failed to logout all sessions",
+		})
+	}
+
+	return controller.Status(200).JSON(fiber.Map{
+		"success": "all sessions logged out",
+	})
+}
+
+// ListSessions implements UserController.
+func (u *UserControllerImplement) ListSessions(controller *fiber.Ctx) error {
+	claims, ok := tokenClaims(controller)
+	if !ok {
+		return controller.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "token claims not found in context",
+		})
+	}
+
+	sessions, err := u.userService.ListSessions(controller.Context(), claims)
+	if err != nil {
+		log.Printf("error list sessions user %d: %v", claims.UserId, err)
+		return controller.Status(500).JSON(fiber.Map{
+			"error": "failed to get sessions",
+		})
+	}
+
+	return controller.Status(200).JSON(fiber.Map{
+		"sessions": sessions,
+	})
+}
+
+// ForgotPassword implements UserController.
+func (u *UserControllerImplement) ForgotPassword(controller *fiber.Ctx) error {
+	var req models.ForgotPasswordRequest
+	if err := controller.BodyParser(&req); err != nil || req.Email == "" {
+		return controller.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "email is required",
+		})
+	}
+
+	if err := u.userService.ForgotPassword(controller.Context(), &req); err != nil {
+		if errors.Is(err, service.ErrTooManyRequests) {
+			return controller.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		log.Printf("error forgot password: %v", err)
+		return controller.Status(500).JSON(fiber.Map{
+			"error": "failed to process password reset",
+		})
+	}
+
+	return controller.Status(200).JSON(fiber.Map{
+		"success": "if the email is registered, a password reset link has been sent",
+	})
+}
+
+// ResetPassword implements UserController.
+func (u *UserControllerImplement) ResetPassword(controller *fiber.Ctx) error {
+	var req models.ResetPasswordRequest
+	if err := controller.BodyParser(&req); err != nil {
+		return controller.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request",
+		})
+	}
+
+	if err := u.userService.ResetPassword(controller.Context(), &req); err != nil {
+		if body, ok := validationErrorBody(err); ok {
+			return controller.Status(fiber.StatusUnprocessableEntity).JSON(body)
+		}
+		if errors.Is(err, service.ErrInvalidResetToken) {
+			return controller.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		log.Printf("error reset password: %v", err)
+		return controller.Status(500).JSON(fiber.Map{
+			"error": "failed to reset password",
+		})
+	}
+
+	return controller.Status(200).JSON(fiber.Map{
+		"success": "password has been reset, please login again",
+	})
+}
+
+// VerifyEmail implements UserController.
+func (u *UserControllerImplement) VerifyEmail(controller *fiber.Ctx) error {
+	var req models.VerifyEmailRequest
+	if err := controller.BodyParser(&req); err != nil || req.Token == "" {
+		return controller.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "token is required",
+		})
+	}
+
+	if err := u.userService.VerifyEmail(controller.Context(), &req); err != nil {
+		if errors.Is(err, service.ErrInvalidVerificationToken) {
+			return controller.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		log.Printf("error verify email: %v", err)
+		return controller.Status(500).JSON(fiber.Map{
+			"error": "failed to verify email",
+		})
+	}
+
+	return controller.Status(200).JSON(fiber.Map{
+		"success": "email verified",
+	})
+}
+
+// ResendVerification implements UserController.
+func (u *UserControllerImplement) ResendVerification(controller *fiber.Ctx) error {
+	userID, ok := controller.Locals("userID").(uint)
+	if !ok {
+		return controller.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "user id not found in context",
+		})
+	}
+
+	if err := u.userService.ResendVerification(controller.Context(), userID); err != nil {
+		switch {
+		case errors.Is(err, service.ErrEmailAlreadyVerified):
+			return controller.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		case errors.Is(err, service.ErrTooManyRequests):
+			return controller.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		log.Printf("error resend verification user %d: %v", userID, err)
+		return controller.Status(500).JSON(fiber.Map{
+			"error": "failed to send verification email",
+		})
+	}
+
+	return controller.Status(200).JSON(fiber.Map{
+		"success": "verification email sent",
+	})
+}
+
 // Register implements UserController.
 func (u *UserControllerImplement) Register(controller *fiber.Ctx) error {
 	var user models.RegisterUser
 	if err := controller.BodyParser(&user); err != nil {
-		return controller.Status(500).JSON(fiber.Map{
+		return controller.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": "invalid request",
 		})
 	}
 	if err := u.userService.Register(controller.Context(), &user); err != nil {
+		if body, ok := validationErrorBody(err); ok {
+			return controller.Status(fiber.StatusUnprocessableEntity).JSON(body)
+		}
+		if errors.Is(err, service.ErrEmailTaken) {
+			return controller.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error":  err.Error(),
+				"fields": fiber.Map{"email": err.Error()},
+			})
+		}
+		log.Printf("error register user: %v", err)
 		return controller.Status(500).JSON(fiber.Map{
 			"error": "error register user",
 		})
@@ -0,0 +1,135 @@
+package controller
+
+import (
+	"errors"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/imnzr/sim-service-project/internal/service"
+	"github.com/imnzr/sim-service-project/models"
+)
+
+// profileErrorResponse memetakan error dari operasi profil ke status HTTP
+func profileErrorResponse(controller *fiber.Ctx, err error, action string) error {
+	if body, ok := validationErrorBody(err); ok {
+		return controller.Status(fiber.StatusUnprocessableEntity).JSON(body)
+	}
+	switch {
+	case errors.Is(err, service.ErrIncorrectPassword):
+		return controller.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, service.ErrEmailTaken):
+		return controller.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":  err.Error(),
+			"fields": fiber.Map{"email": err.Error()},
+		})
+	case errors.Is(err, service.ErrTooManyRequests):
+		return controller.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	log.Printf("error %s: %v", action, err)
+	return controller.Status(500).JSON(fiber.Map{
+		"error": "failed to " + action,
+	})
+}
+
+// UpdateProfile implements UserController.
+func (u *UserControllerImplement) UpdateProfile(controller *fiber.Ctx) error {
+	userID, ok := controller.Locals("userID").(uint)
+	if !ok {
+		return controller.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "user id not found in context",
+		})
+	}
+
+	var req models.UpdateProfileRequest
+	if err := controller.BodyParser(&req); err != nil {
+		return controller.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request",
+		})
+	}
+
+	profile, err := u.userService.UpdateProfile(controller.Context(), userID, &req)
+	if err != nil {
+		return profileErrorResponse(controller, err, "update profile")
+	}
+
+	return controller.Status(200).JSON(profile)
+}
+
+// ChangeEmail implements UserController.
+func (u *UserControllerImplement) ChangeEmail(controller *fiber.Ctx) error {
+	userID, ok := controller.Locals("userID").(uint)
+	if !ok {
+		return controller.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "user id not found in context",
+		})
+	}
+
+	var req models.ChangeEmailRequest
+	if err := controller.BodyParser(&req); err != nil {
+		return controller.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request",
+		})
+	}
+
+	if err := u.userService.ChangeEmail(controller.Context(), userID, &req); err != nil {
+		return profileErrorResponse(controller, err, "change email")
+	}
+
+	return controller.Status(200).JSON(fiber.Map{
+		"success": "verification email sent to the new address",
+	})
+}
+
+// ChangePassword implements UserController.
+func (u *UserControllerImplement) ChangePassword(controller *fiber.Ctx) error {
+	claims, ok := tokenClaims(controller)
+	if !ok {
+		return controller.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "token claims not found in context",
+		})
+	}
+
+	var req models.ChangePasswordRequest
+	if err := controller.BodyParser(&req); err != nil {
+		return controller.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request",
+		})
+	}
+
+	if err := u.userService.ChangePassword(controller.Context(), claims, &req); err != nil {
+		return profileErrorResponse(controller, err, "change password")
+	}
+
+	return controller.Status(200).JSON(fiber.Map{
+		"success": "password changed, other sessions have been logged out",
+	})
+}
+
+// DeleteAccount implements UserController.
+func (u *UserControllerImplement) DeleteAccount(controller *fiber.Ctx) error {
+	userID, ok := controller.Locals("userID").(uint)
+	if !ok {
+		return controller.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "user id not found in context",
+		})
+	}
+
+	var req models.DeleteAccountRequest
+	if err := controller.BodyParser(&req); err != nil {
+		return controller.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request",
+		})
+	}
+
+	if err := u.userService.DeleteAccount(controller.Context(), userID, &req); err != nil {
+		return profileErrorResponse(controller, err, "delete account")
+	}
+
+	return controller.Status(200).JSON(fiber.Map{
+		"success": "account deleted",
+	})
+}
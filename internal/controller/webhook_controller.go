@@ -0,0 +1,147 @@
+package controller
+
+import (
+	"errors"
+	"log"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/imnzr/sim-service-project/internal/service"
+	"github.com/imnzr/sim-service-project/models"
+)
+
+type WebhookController interface {
+	CreateEndpoint(controller *fiber.Ctx) error
+	ListEndpoints(controller *fiber.Ctx) error
+	DeleteEndpoint(controller *fiber.Ctx) error
+	ListDeliveries(controller *fiber.Ctx) error
+	PingEndpoint(controller *fiber.Ctx) error
+}
+
+type WebhookControllerImplementation struct {
+	WebhookService service.WebhookService
+}
+
+func NewWebhookController(webhookService service.WebhookService) WebhookController {
+	return &WebhookControllerImplementation{
+		WebhookService: webhookService,
+	}
+}
+
+// webhookError memetakan error webhook ke status HTTP
+func webhookError(controller *fiber.Ctx, err error) error {
+	if body, ok := validationErrorBody(err); ok {
+		return controller.Status(fiber.StatusUnprocessableEntity).JSON(body)
+	}
+	switch {
+	case errors.Is(err, service.ErrWebhookNotFound):
+		return controller.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, service.ErrTooManyWebhooks):
+		return controller.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	log.Printf("webhook error: %v", err)
+	return controller.Status(500).JSON(fiber.Map{
+		"error": err.Error(),
+	})
+}
+
+func webhookIdParam(controller *fiber.Ctx) (int64, bool) {
+	id, err := strconv.ParseInt(controller.Params("id"), 10, 64)
+	return id, err == nil && id > 0
+}
+
+func invalidWebhookId(controller *fiber.Ctx) error {
+	return controller.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		"error": "invalid webhook id",
+	})
+}
+
+// CreateEndpoint implements WebhookController.
+// Secret hanya ada di response ini dan tidak bisa ditampilkan lagi.
+func (w *WebhookControllerImplementation) CreateEndpoint(controller *fiber.Ctx) error {
+	userId, _ := controller.Locals("userID").(uint)
+
+	var req models.CreateWebhookEndpointRequest
+	if err := controller.BodyParser(&req); err != nil {
+		return controller.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request",
+		})
+	}
+
+	created, err := w.WebhookService.CreateEndpoint(controller.Context(), userId, &req)
+	if err != nil {
+		return webhookError(controller, err)
+	}
+	return controller.Status(fiber.StatusCreated).JSON(created)
+}
+
+// ListEndpoints implements WebhookController.
+func (w *WebhookControllerImplementation) ListEndpoints(controller *fiber.Ctx) error {
+	userId, _ := controller.Locals("userID").(uint)
+
+	endpoints, err := w.WebhookService.ListEndpoints(controller.Context(), userId)
+	if err != nil {
+		return webhookError(controller, err)
+	}
+	return controller.Status(200).JSON(fiber.Map{
+		"webhooks": endpoints,
+	})
+}
+
+// DeleteEndpoint implements WebhookController.
+func (w *WebhookControllerImplementation) DeleteEndpoint(controller *fiber.Ctx) error {
+	userId, _ := controller.Locals("userID").(uint)
+	endpointId, ok := webhookIdParam(controller)
+	if !ok {
+		return invalidWebhookId(controller)
+	}
+
+	if err := w.WebhookService.DeleteEndpoint(controller.Context(), userId, endpointId); err != nil {
+		return webhookError(controller, err)
+	}
+	return controller.Status(200).JSON(fiber.Map{
+		"message": "webhook endpoint deleted",
+	})
+}
+
+// ListDeliveries implements WebhookController.
+// Log pengiriman terbaru lebih dulu, ?page= dan ?limit=
+func (w *WebhookControllerImplementation) ListDeliveries(controller *fiber.Ctx) error {
+	userId, _ := controller.Locals("userID").(uint)
+	endpointId, ok := webhookIdParam(controller)
+	if !ok {
+		return invalidWebhookId(controller)
+	}
+
+	page, err := w.WebhookService.ListDeliveries(controller.Context(), userId, endpointId, controller.QueryInt("page", 1), controller.QueryInt("limit", 0))
+	if err != nil {
+		return webhookError(controller, err)
+	}
+	return controller.Status(200).JSON(page)
+}
+
+// PingEndpoint implements WebhookController.
+// Endpoint yang tidak membalas 2xx dikembalikan sebagai 502 beserta hasil pengirimannya.
+func (w *WebhookControllerImplementation) PingEndpoint(controller *fiber.Ctx) error {
+	userId, _ := controller.Locals("userID").(uint)
+	endpointId, ok := webhookIdParam(controller)
+	if !ok {
+		return invalidWebhookId(controller)
+	}
+
+	delivery, err := w.WebhookService.Ping(controller.Context(), userId, endpointId)
+	if errors.Is(err, service.ErrWebhookPingError) {
+		return controller.Status(fiber.StatusBadGateway).JSON(fiber.Map{
+			"error":    err.Error(),
+			"delivery": delivery,
+		})
+	}
+	if err != nil {
+		return webhookError(controller, err)
+	}
+	return controller.Status(200).JSON(delivery)
+}
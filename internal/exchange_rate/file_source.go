@@ -0,0 +1,43 @@
+package exchangerate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// FileRateSource membaca kurs dari file JSON statis, contoh: {"RUB_IDR": 208.0}
+type FileRateSource struct {
+	Path string
+}
+
+func NewFileRateSource(path string) *FileRateSource {
+	return &FileRateSource{
+		Path: path,
+	}
+}
+
+// Name implements RateSource.
+func (f *FileRateSource) Name() string {
+	return "file"
+}
+
+// FetchRate implements RateSource.
+func (f *FileRateSource) FetchRate(ctx context.Context, base string, quote string) (float64, error) {
+	data, err := os.ReadFile(f.Path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read exchange rate file: %w", err)
+	}
+
+	var rates map[string]float64
+	if err := json.Unmarshal(data, &rates); err != nil {
+		return 0, fmt.Errorf("failed to decode exchange rate file: %w", err)
+	}
+
+	rate, ok := rates[base+"_"+quote]
+	if !ok || rate <= 0 {
+		return 0, fmt.Errorf("rate %s/%s not found in %s", base, quote, f.Path)
+	}
+	return rate, nil
+}
@@ -0,0 +1,64 @@
+package exchangerate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/imnzr/sim-service-project/utils"
+)
+
+// HTTPRateSource mengambil kurs dari API dengan format
+// GET <url>/<BASE> -> {"rates": {"IDR": 208.1, ...}}
+type HTTPRateSource struct {
+	URL    string
+	Client *http.Client
+}
+
+func NewHTTPRateSource(url string, timeout time.Duration) *HTTPRateSource {
+	return &HTTPRateSource{
+		URL:    strings.TrimRight(url, "/"),
+		Client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Name implements RateSource.
+func (h *HTTPRateSource) Name() string {
+	return "http"
+}
+
+// FetchRate implements RateSource.
+func (h *HTTPRateSource) FetchRate(ctx context.Context, base string, quote string) (float64, error) {
+	req, err := utils.NewRequestGuest("GET", fmt.Sprintf("%s/%s", h.URL, base), nil)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := h.Client.Do(req.WithContext(ctx))
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return 0, fmt.Errorf("exchange rate api error: %s", body)
+	}
+
+	var result struct {
+		Rates map[string]float64 `json:"rates"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return 0, fmt.Errorf("failed to decode exchange rate response: %w", err)
+	}
+
+	rate, ok := result.Rates[quote]
+	if !ok || rate <= 0 {
+		return 0, fmt.Errorf("rate %s/%s not found in exchange rate response", base, quote)
+	}
+	return rate, nil
+}
@@ -0,0 +1,34 @@
+package exchangerate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/imnzr/sim-service-project/config"
+)
+
+// RateSource adalah sumber kurs yang bisa diganti-ganti (HTTP API, file statis, dll)
+type RateSource interface {
+	Name() string
+	FetchRate(ctx context.Context, base, quote string) (float64, error)
+}
+
+// NewRateSource memilih source berdasarkan EXCHANGE_RATE_SOURCE
+func NewRateSource(cfg config.AppConfig) (RateSource, error) {
+	switch strings.ToLower(cfg.ExchangeRateSource) {
+	case "", "http":
+		if cfg.ExchangeRateAPIURL == "" {
+			return nil, fmt.Errorf("EXCHANGE_RATE_API_URL environment variable not set")
+		}
+		return NewHTTPRateSource(cfg.ExchangeRateAPIURL, 10*time.Second), nil
+	case "file":
+		if cfg.ExchangeRateFile == "" {
+			return nil, fmt.Errorf("EXCHANGE_RATE_FILE environment variable not set")
+		}
+		return NewFileRateSource(cfg.ExchangeRateFile), nil
+	default:
+		return nil, fmt.Errorf("unknown exchange rate source: %s", cfg.ExchangeRateSource)
+	}
+}
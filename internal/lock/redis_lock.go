@@ -0,0 +1,56 @@
+package lock
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrNotAcquired dikembalikan jika lock sedang dipegang instance lain
+var ErrNotAcquired = errors.New("lock is held by another instance")
+
+// releaseScript hanya menghapus key jika token masih milik pemegang lock
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// RedisLock adalah distributed lock sederhana berbasis SET NX dengan TTL
+type RedisLock struct {
+	client *redis.Client
+}
+
+func NewRedisLock(client *redis.Client) *RedisLock {
+	return &RedisLock{
+		client: client,
+	}
+}
+
+// Acquire mengambil lock dan mengembalikan token yang dipakai untuk Release
+func (l *RedisLock) Acquire(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(random)
+
+	ok, err := l.client.SetNX(ctx, key, token, ttl).Result()
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", ErrNotAcquired
+	}
+	return token, nil
+}
+
+// Release melepas lock jika masih dipegang oleh token yang sama
+func (l *RedisLock) Release(ctx context.Context, key, token string) error {
+	return releaseScript.Run(ctx, l.client, []string{key}, token).Err()
+}
@@ -0,0 +1,56 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// FileMailer menyimpan setiap email sebagai file .eml di satu folder,
+// berguna untuk development dan pengujian manual tanpa SMTP
+type FileMailer struct {
+	From string
+	Dir  string
+}
+
+func NewFileMailer(from, dir string) *FileMailer {
+	return &FileMailer{
+		From: from,
+		Dir:  dir,
+	}
+}
+
+// Send implements Mailer.
+func (f *FileMailer) Send(ctx context.Context, message Message) error {
+	if message.From == "" {
+		message.From = f.From
+	}
+	if err := os.MkdirAll(f.Dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create mail dir: %w", err)
+	}
+
+	now := time.Now()
+	name := fmt.Sprintf("%s_%s.eml", now.Format("20060102T150405.000000000"), sanitizeFileName(message.To))
+	content := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nDate: %s\r\n\r\n%s\r\n",
+		message.From, message.To, message.Subject, now.Format(time.RFC1123Z), message.Body)
+
+	if err := os.WriteFile(filepath.Join(f.Dir, name), []byte(content), 0o644); err != nil {
+		return fmt.Errorf("failed to write mail file: %w", err)
+	}
+	return nil
+}
+
+// sanitizeFileName membuang karakter yang tidak aman untuk nama file
+func sanitizeFileName(value string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_', r == '@':
+			return r
+		default:
+			return '_'
+		}
+	}, value)
+}
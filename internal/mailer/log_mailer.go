@@ -0,0 +1,26 @@
+package mailer
+
+import (
+	"context"
+	"log"
+)
+
+// LogMailer hanya menulis email ke log, dipakai untuk development
+type LogMailer struct {
+	From string
+}
+
+func NewLogMailer(from string) *LogMailer {
+	return &LogMailer{
+		From: from,
+	}
+}
+
+// Send implements Mailer.
+func (l *LogMailer) Send(ctx context.Context, message Message) error {
+	if message.From == "" {
+		message.From = l.From
+	}
+	log.Printf("mail from=%s to=%s subject=%q\n%s", message.From, message.To, message.Subject, message.Body)
+	return nil
+}
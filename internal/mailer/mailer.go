@@ -0,0 +1,37 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/imnzr/sim-service-project/config"
+)
+
+// Message adalah email sederhana berbentuk teks
+type Message struct {
+	From    string
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer adalah pengirim email yang bisa diganti-ganti (log, file, SMTP, dll)
+type Mailer interface {
+	Send(ctx context.Context, message Message) error
+}
+
+// NewMailer memilih mailer berdasarkan MAIL_DRIVER
+func NewMailer(cfg config.AppConfig) (Mailer, error) {
+	switch strings.ToLower(cfg.MailDriver) {
+	case "", "log":
+		return NewLogMailer(cfg.MailFrom), nil
+	case "file":
+		if cfg.MailOutputDir == "" {
+			return nil, fmt.Errorf("MAIL_OUTPUT_DIR environment variable not set")
+		}
+		return NewFileMailer(cfg.MailFrom, cfg.MailOutputDir), nil
+	default:
+		return nil, fmt.Errorf("unknown mail driver: %s", cfg.MailDriver)
+	}
+}
@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/imnzr/sim-service-project/internal/audit"
+)
+
+// AuditRequestInfo menyimpan IP dan user agent request agar ikut tercatat di audit log.
+// Disimpan sebagai fasthttp user value sehingga terbaca dari controller.Context() di service.
+func AuditRequestInfo() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Context().SetUserValue(audit.RequestInfoKey, audit.RequestInfo{
+			IPAddress: c.IP(),
+			UserAgent: c.Get(fiber.HeaderUserAgent),
+		})
+		return c.Next()
+	}
+}
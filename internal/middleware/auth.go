@@ -1,8 +1,10 @@
 package middleware
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"strconv"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
@@ -10,9 +12,16 @@ import (
 	"github.com/imnzr/sim-service-project/internal/service"
 )
 
-// Authmiddleware memverifikasi JWT dan menambahkan user ID ke konteks
-func AuthMiddleware(userService service.UserService, cfg config.AppConfig) fiber.Handler {
+// Authmiddleware memverifikasi JWT dan menambahkan user ID ke konteks.
+// Header X-API-Key diterima sebagai pengganti Bearer JWT hanya jika apiKeyScopes diisi,
+// dan key tersebut harus punya semua scope yang diminta. Tanpa scope, API key ditolak
+// sehingga endpoint akun dan admin tidak bisa diakses dengan API key.
+func AuthMiddleware(userService service.UserService, apiKeyService service.APIKeyService, cfg config.AppConfig, apiKeyScopes ...string) fiber.Handler {
 	return func(c *fiber.Ctx) error {
+		if rawKey := c.Get(HeaderAPIKey); rawKey != "" {
+			return authenticateAPIKey(c, apiKeyService, cfg, rawKey, apiKeyScopes)
+		}
+
 		authHeader := c.Get("Authorization")
 		if authHeader == "" {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
@@ -38,20 +47,105 @@ func AuthMiddleware(userService service.UserService, cfg config.AppConfig) fiber
 			})
 		}
 
-		// ekstrak user_id dari claims
-		userIdFloat, ok := claims["user_id"].(float64)
-		if !ok {
-			log.Printf("user id not found or invalid type in token claims")
+		// refresh token hanya boleh dipakai di /auth/refresh
+		if claims.Type != service.TokenTypeAccess {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "refresh token cannot be used as access token",
+			})
+		}
+
+		// token yang sudah logout ditolak. Saat redis down IsTokenRevoked mengecek sesi di database,
+		// error di sini berarti pencabutan tidak bisa dipastikan dan request ditolak.
+		revoked, err := userService.IsTokenRevoked(c.Context(), claims)
+		if err != nil {
+			log.Printf("token revocation check failed: %v", err)
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"error": "failed to verify token",
+			})
+		}
+		if revoked {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "invalid user ID in token claimms",
+				"error": "token has been revoked",
 			})
 		}
-		userID := uint(userIdFloat)
+
+		// user yang di-suspend admin ditolak. IsUserSuspended sudah fallback ke database saat redis down,
+		// jadi error di sini berarti status suspend tidak bisa dipastikan dan request ditolak.
+		suspended, err := userService.IsUserSuspended(c.Context(), claims.UserId)
+		if err != nil {
+			log.Printf("suspension check failed: %v", err)
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"error": "failed to verify account status",
+			})
+		}
+		if suspended {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": service.ErrAccountSuspended.Error(),
+			})
+		}
+
+		userID := claims.UserId
 
 		// simpan user id di local fiber context agar bisa di akses di handler
 		c.Locals("userID", userID)
+		c.Locals("tokenClaims", claims)
+		c.Locals("userRole", claims.Role)
 
 		// lanjutkan ke handler berikutnya jika token valid
 		return c.Next()
 	}
 }
+
+// HeaderAPIKey adalah header untuk autentikasi server-to-server dengan API key
+const HeaderAPIKey = "X-API-Key"
+
+func authenticateAPIKey(c *fiber.Ctx, apiKeyService service.APIKeyService, cfg config.AppConfig, rawKey string, scopes []string) error {
+	if len(scopes) == 0 {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "API keys are not accepted on this endpoint",
+		})
+	}
+
+	key, user, err := apiKeyService.Authenticate(c.Context(), rawKey, c.IP())
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidAPIKey):
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		case errors.Is(err, service.ErrAccountSuspended):
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		log.Printf("API key authentication failed: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to authenticate API key",
+		})
+	}
+
+	for _, scope := range scopes {
+		if !key.HasScope(scope) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": fmt.Sprintf("API key is missing scope %s", scope),
+			})
+		}
+	}
+
+	// sama seperti cek revocation, redis yang down tidak ikut mematikan API
+	allowed, err := apiKeyService.AllowRequest(c.Context(), key)
+	if err != nil {
+		log.Printf("API key rate limit check failed: %v", err)
+	} else if !allowed {
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(cfg.APIKeyRateWindow.Seconds())))
+		return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+			"error": "API key rate limit exceeded",
+		})
+	}
+	c.Set("X-RateLimit-Limit", strconv.Itoa(key.RateLimit))
+
+	c.Locals("userID", user.ID)
+	c.Locals("userRole", user.Role)
+	c.Locals("apiKey", key)
+	return c.Next()
+}
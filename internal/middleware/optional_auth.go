@@ -0,0 +1,15 @@
+package middleware
+
+import "github.com/gofiber/fiber/v2"
+
+// OptionalAuth menjalankan authMiddleware hanya jika request membawa kredensial.
+// Request tanpa Authorization maupun X-API-Key diteruskan sebagai anonim,
+// sedangkan kredensial yang tidak valid tetap ditolak.
+func OptionalAuth(authMiddleware fiber.Handler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if c.Get(fiber.HeaderAuthorization) == "" && c.Get(HeaderAPIKey) == "" {
+			return c.Next()
+		}
+		return authMiddleware(c)
+	}
+}
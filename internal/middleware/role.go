@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"slices"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequireRole membatasi route hanya untuk role tertentu, dipasang setelah AuthMiddleware.
+// Role dibaca dari claims access token sehingga perubahan role berlaku setelah token diperbarui.
+func RequireRole(roles ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		role, ok := c.Locals("userRole").(string)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "user role not found in context",
+			})
+		}
+		if !slices.Contains(roles, role) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "insufficient role",
+			})
+		}
+		return c.Next()
+	}
+}
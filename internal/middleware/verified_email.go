@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/imnzr/sim-service-project/internal/service"
+)
+
+// RequireVerifiedEmail menolak user yang belum verifikasi email, dipasang setelah AuthMiddleware
+func RequireVerifiedEmail(userService service.UserService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID, ok := c.Locals("userID").(uint)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "user id not found in context",
+			})
+		}
+
+		verified, err := userService.IsEmailVerified(c.Context(), userID)
+		if err != nil {
+			log.Printf("failed to check email verification for user %d: %v", userID, err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to check email verification",
+			})
+		}
+		if !verified {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": service.ErrEmailNotVerified.Error(),
+			})
+		}
+		return c.Next()
+	}
+}
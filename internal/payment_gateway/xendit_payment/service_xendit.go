@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"log"
 	"os"
-	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/imnzr/sim-service-project/internal/repository"
@@ -43,7 +42,6 @@ func (x *XenditPaymentImplement) CreateOrderAndStartPayment(ctx *fiber.Ctx, orde
 	}
 
 	order.Status = "PENDING"
-	order.InvoiceId = fmt.Sprintf("INV-%d", time.Now().UnixNano())
 
 	xenditKey := os.Getenv("XENDIT_API_KEY")
 	if xenditKey == "" {
@@ -52,11 +50,10 @@ func (x *XenditPaymentImplement) CreateOrderAndStartPayment(ctx *fiber.Ctx, orde
 
 	xenditClient := xendit.NewClient(xenditKey)
 
-	orderId, err := x.simOrderRepo.Create(context.Background(), order)
-	if err != nil {
+	// Create mengisi order.Id dan order.InvoiceId
+	if _, err := x.simOrderRepo.Create(context.Background(), order); err != nil {
 		return "", fmt.Errorf("failed to create order in repository: %w", err)
 	}
-	order.Id = orderId
 
 	// Build invoice data
 	email := user.Email
@@ -77,6 +74,12 @@ func (x *XenditPaymentImplement) CreateOrderAndStartPayment(ctx *fiber.Ctx, orde
 		if res != nil {
 			fmt.Fprintf(os.Stderr, "HTTP response: %v\n", res)
 		}
+
+		// Jangan tinggalkan order PENDING tanpa invoice
+		order.Status = "FAILED"
+		if err := x.simOrderRepo.MarkFailed(context.Background(), order.Id, errXendit.Error()); err != nil {
+			log.Printf("failed to mark order %d as FAILED: %v", order.Id, err)
+		}
 		return "", fmt.Errorf("failed to create invoice: %w", errXendit)
 	}
 
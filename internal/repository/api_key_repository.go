@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/imnzr/sim-service-project/models"
+)
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *models.APIKey) error
+	FindByHash(ctx context.Context, keyHash string) (*models.APIKey, error)
+	FindByUser(ctx context.Context, userId uint) ([]models.APIKey, error)
+	CountActiveByUser(ctx context.Context, userId uint) (int, error)
+	Revoke(ctx context.Context, userId uint, keyId int64) (bool, error)
+	TouchLastUsed(ctx context.Context, keyId int64, ipAddress string) error
+}
+
+type APIKeyImplementation struct {
+	db *sql.DB
+}
+
+func NewAPIKeyRepository(db *sql.DB) APIKeyRepository {
+	return &APIKeyImplementation{
+		db: db,
+	}
+}
+
+const apiKeyColumns = "id, user_id, name, prefix, key_hash, scopes, rate_limit, last_used_at, last_used_ip, expires_at, revoked_at, created_at"
+
+// scope disimpan sebagai string dipisah koma
+func scanAPIKey(row rowScanner, key *models.APIKey) error {
+	var scopes string
+	err := row.Scan(
+		&key.Id,
+		&key.UserId,
+		&key.Name,
+		&key.Prefix,
+		&key.KeyHash,
+		&scopes,
+		&key.RateLimit,
+		&key.LastUsedAt,
+		&key.LastUsedIP,
+		&key.ExpiresAt,
+		&key.RevokedAt,
+		&key.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	key.Scopes = []string{}
+	if scopes != "" {
+		key.Scopes = strings.Split(scopes, ",")
+	}
+	return nil
+}
+
+// Create implements APIKeyRepository.
+func (a *APIKeyImplementation) Create(ctx context.Context, key *models.APIKey) error {
+	query := "INSERT INTO api_keys(user_id, name, prefix, key_hash, scopes, rate_limit, expires_at) VALUES(?,?,?,?,?,?,?)"
+
+	result, err := a.db.ExecContext(ctx, query, key.UserId, key.Name, key.Prefix, key.KeyHash, strings.Join(key.Scopes, ","), key.RateLimit, key.ExpiresAt)
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	key.Id = id
+	return nil
+}
+
+// FindByHash implements APIKeyRepository.
+// Key yang sudah dicabut tetap dikembalikan, pengecekan status dilakukan di service.
+func (a *APIKeyImplementation) FindByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	query := "SELECT " + apiKeyColumns + " FROM api_keys WHERE key_hash = ?"
+
+	key := &models.APIKey{}
+	if err := scanAPIKey(a.db.QueryRowContext(ctx, query, keyHash), key); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return key, nil
+}
+
+// FindByUser implements APIKeyRepository.
+func (a *APIKeyImplementation) FindByUser(ctx context.Context, userId uint) ([]models.APIKey, error) {
+	query := "SELECT " + apiKeyColumns + " FROM api_keys WHERE user_id = ? ORDER BY id DESC"
+
+	rows, err := a.db.QueryContext(ctx, query, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []models.APIKey{}
+	for rows.Next() {
+		var key models.APIKey
+		if err := scanAPIKey(rows, &key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// CountActiveByUser implements APIKeyRepository.
+func (a *APIKeyImplementation) CountActiveByUser(ctx context.Context, userId uint) (int, error) {
+	query := "SELECT COUNT(*) FROM api_keys WHERE user_id = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > NOW())"
+
+	var total int
+	err := a.db.QueryRowContext(ctx, query, userId).Scan(&total)
+	return total, err
+}
+
+// Revoke implements APIKeyRepository.
+// Mengembalikan false jika key tidak ditemukan, bukan milik user atau sudah dicabut.
+func (a *APIKeyImplementation) Revoke(ctx context.Context, userId uint, keyId int64) (bool, error) {
+	query := "UPDATE api_keys SET revoked_at = NOW() WHERE id = ? AND user_id = ? AND revoked_at IS NULL"
+
+	result, err := a.db.ExecContext(ctx, query, keyId, userId)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows == 1, nil
+}
+
+// TouchLastUsed implements APIKeyRepository.
+// Paling sering sekali per menit agar request beruntun tidak selalu menulis ke database.
+func (a *APIKeyImplementation) TouchLastUsed(ctx context.Context, keyId int64, ipAddress string) error {
+	query := `
+		UPDATE api_keys SET last_used_at = NOW(), last_used_ip = ?
+		WHERE id = ? AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL 1 MINUTE)
+	`
+	_, err := a.db.ExecContext(ctx, query, ipAddress, keyId)
+	return err
+}
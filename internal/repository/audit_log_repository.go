@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/imnzr/sim-service-project/models"
+)
+
+// AuditLogRepository sengaja tidak punya method update/delete, tabel audit_logs append-only
+type AuditLogRepository interface {
+	Append(ctx context.Context, entry *models.AuditLog) error
+	FindByTarget(ctx context.Context, targetType, targetId string) ([]models.AuditLog, error)
+	Search(ctx context.Context, query models.AuditLogQuery) ([]models.AuditLog, int, error)
+	FindAfter(ctx context.Context, afterId int64, limit int) ([]models.AuditLog, error)
+	ChainHead(ctx context.Context) (int64, string, error)
+}
+
+type AuditLogImplementation struct {
+	db *sql.DB
+}
+
+func NewAuditLogRepository(db *sql.DB) AuditLogRepository {
+	return &AuditLogImplementation{
+		db: db,
+	}
+}
+
+const auditLogColumns = "id, actor_user_id, action, target_type, target_id, reason, before_data, after_data, metadata, ip_address, user_agent, prev_hash, hash, created_at"
+
+func scanAuditLog(row rowScanner, entry *models.AuditLog) error {
+	return row.Scan(
+		&entry.Id,
+		&entry.ActorUserId,
+		&entry.Action,
+		&entry.TargetType,
+		&entry.TargetId,
+		&entry.Reason,
+		&entry.Before,
+		&entry.After,
+		&entry.Metadata,
+		&entry.IPAddress,
+		&entry.UserAgent,
+		&entry.PrevHash,
+		&entry.Hash,
+		&entry.CreatedAt,
+	)
+}
+
+func (a *AuditLogImplementation) queryAuditLogs(ctx context.Context, query string, args ...any) ([]models.AuditLog, error) {
+	rows, err := a.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []models.AuditLog{}
+	for rows.Next() {
+		var entry models.AuditLog
+		if err := scanAuditLog(rows, &entry); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// Append implements AuditLogRepository.
+// Ujung chain dikunci lebih dulu sehingga penulisan paralel tetap membentuk satu rantai.
+// entry.CreatedAt harus sudah diisi pemanggil, PrevHash dan Hash diisi di sini.
+func (a *AuditLogImplementation) Append(ctx context.Context, entry *models.AuditLog) error {
+	tx, err := a.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var prevHash string
+	if err := tx.QueryRowContext(ctx, "SELECT last_hash FROM audit_log_chain WHERE id = 1 FOR UPDATE").Scan(&prevHash); err != nil {
+		return err
+	}
+	entry.PrevHash = &prevHash
+	hash := entry.ChainHash()
+	entry.Hash = &hash
+
+	query := `
+		INSERT INTO audit_logs(actor_user_id, action, target_type, target_id, reason, before_data, after_data, metadata, ip_address, user_agent, prev_hash, hash, created_at)
+		VALUES(?,?,?,?,?,?,?,?,?,?,?,?,?)
+	`
+	result, err := tx.ExecContext(ctx, query,
+		entry.ActorUserId,
+		entry.Action,
+		entry.TargetType,
+		entry.TargetId,
+		entry.Reason,
+		entry.Before,
+		entry.After,
+		entry.Metadata,
+		entry.IPAddress,
+		entry.UserAgent,
+		entry.PrevHash,
+		entry.Hash,
+		entry.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE audit_log_chain SET last_id = ?, last_hash = ? WHERE id = 1", id, hash); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	entry.Id = id
+	return nil
+}
+
+// FindByTarget implements AuditLogRepository.
+func (a *AuditLogImplementation) FindByTarget(ctx context.Context, targetType string, targetId string) ([]models.AuditLog, error) {
+	query := "SELECT " + auditLogColumns + " FROM audit_logs WHERE target_type = ? AND target_id = ? ORDER BY id ASC"
+	return a.queryAuditLogs(ctx, query, targetType, targetId)
+}
+
+// Search implements AuditLogRepository.
+func (a *AuditLogImplementation) Search(ctx context.Context, query models.AuditLogQuery) ([]models.AuditLog, int, error) {
+	conditions := []string{"1 = 1"}
+	args := []any{}
+	if query.ActorUserId != 0 {
+		conditions = append(conditions, "actor_user_id = ?")
+		args = append(args, query.ActorUserId)
+	}
+	if query.Action != "" {
+		conditions = append(conditions, "action = ?")
+		args = append(args, query.Action)
+	}
+	if query.TargetType != "" {
+		conditions = append(conditions, "target_type = ?")
+		args = append(args, query.TargetType)
+	}
+	if query.TargetId != "" {
+		conditions = append(conditions, "target_id = ?")
+		args = append(args, query.TargetId)
+	}
+	if query.From != nil {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, *query.From)
+	}
+	if query.To != nil {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, *query.To)
+	}
+	from := "FROM audit_logs WHERE " + strings.Join(conditions, " AND ")
+
+	var total int
+	if err := a.db.QueryRowContext(ctx, "SELECT COUNT(*) "+from, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	args = append(args, query.Limit, (query.Page-1)*query.Limit)
+	entries, err := a.queryAuditLogs(ctx, "SELECT "+auditLogColumns+" "+from+" ORDER BY id DESC LIMIT ? OFFSET ?", args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	return entries, total, nil
+}
+
+// FindAfter implements AuditLogRepository.
+// Dipakai untuk membaca audit log secara berurutan per batch saat verifikasi chain.
+func (a *AuditLogImplementation) FindAfter(ctx context.Context, afterId int64, limit int) ([]models.AuditLog, error) {
+	query := "SELECT " + auditLogColumns + " FROM audit_logs WHERE id > ? ORDER BY id ASC LIMIT ?"
+	return a.queryAuditLogs(ctx, query, afterId, limit)
+}
+
+// ChainHead implements AuditLogRepository.
+// Mengembalikan id dan hash audit log terakhir yang tercatat di ujung chain.
+func (a *AuditLogImplementation) ChainHead(ctx context.Context) (int64, string, error) {
+	var lastId sql.NullInt64
+	var lastHash string
+	err := a.db.QueryRowContext(ctx, "SELECT last_id, last_hash FROM audit_log_chain WHERE id = 1").Scan(&lastId, &lastHash)
+	if err != nil {
+		return 0, "", err
+	}
+	return lastId.Int64, lastHash, nil
+}
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/imnzr/sim-service-project/models"
+)
+
+// ErrInsufficientBalance dikembalikan jika transaksi membuat saldo user negatif
+var ErrInsufficientBalance = errors.New("insufficient balance")
+
+type BalanceRepository interface {
+	Apply(ctx context.Context, transaction *models.BalanceTransaction) error
+	FindByUser(ctx context.Context, userId uint, limit int) ([]models.BalanceTransaction, error)
+	FindByReference(ctx context.Context, txType, reference string) (*models.BalanceTransaction, error)
+}
+
+type BalanceImplementation struct {
+	db *sql.DB
+}
+
+func NewBalanceRepository(db *sql.DB) BalanceRepository {
+	return &BalanceImplementation{
+		db: db,
+	}
+}
+
+// Apply implements BalanceRepository.
+// Saldo user dikunci (FOR UPDATE), diubah dan dicatat di ledger dalam satu transaksi.
+// transaction.BalanceAfter dan transaction.Id diisi setelah berhasil.
+// ErrDuplicateEntry dikembalikan jika type + reference sudah pernah dicatat.
+func (b *BalanceImplementation) Apply(ctx context.Context, transaction *models.BalanceTransaction) error {
+	tx, err := b.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var balance float64
+	err = tx.QueryRowContext(ctx, "SELECT balance FROM users WHERE id = ? AND deleted_at IS NULL FOR UPDATE", transaction.UserId).Scan(&balance)
+	if err != nil {
+		return err
+	}
+
+	balanceAfter := balance + transaction.Amount
+	if balanceAfter < 0 {
+		return ErrInsufficientBalance
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE users SET balance = ? WHERE id = ?", balanceAfter, transaction.UserId); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO balance_transactions(user_id, type, amount, balance_after, reason_code, note, reference, actor_user_id)
+		VALUES(?,?,?,?,?,?,?,?)
+	`
+	result, err := tx.ExecContext(ctx, query,
+		transaction.UserId,
+		transaction.Type,
+		transaction.Amount,
+		balanceAfter,
+		transaction.ReasonCode,
+		transaction.Note,
+		transaction.Reference,
+		transaction.ActorUserId,
+	)
+	if err != nil {
+		if isDuplicateEntry(err) {
+			return ErrDuplicateEntry
+		}
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	transaction.Id = id
+	transaction.BalanceAfter = balanceAfter
+	return nil
+}
+
+const balanceTransactionColumns = "id, user_id, type, amount, balance_after, reason_code, note, reference, actor_user_id, created_at"
+
+func scanBalanceTransaction(row rowScanner, item *models.BalanceTransaction) error {
+	return row.Scan(
+		&item.Id,
+		&item.UserId,
+		&item.Type,
+		&item.Amount,
+		&item.BalanceAfter,
+		&item.ReasonCode,
+		&item.Note,
+		&item.Reference,
+		&item.ActorUserId,
+		&item.CreatedAt,
+	)
+}
+
+// FindByUser implements BalanceRepository.
+func (b *BalanceImplementation) FindByUser(ctx context.Context, userId uint, limit int) ([]models.BalanceTransaction, error) {
+	query := "SELECT " + balanceTransactionColumns + " FROM balance_transactions WHERE user_id = ? ORDER BY id DESC LIMIT ?"
+	rows, err := b.db.QueryContext(ctx, query, userId, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	transactions := []models.BalanceTransaction{}
+	for rows.Next() {
+		var item models.BalanceTransaction
+		if err := scanBalanceTransaction(rows, &item); err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, item)
+	}
+	return transactions, rows.Err()
+}
+
+// FindByReference implements BalanceRepository.
+func (b *BalanceImplementation) FindByReference(ctx context.Context, txType string, reference string) (*models.BalanceTransaction, error) {
+	query := "SELECT " + balanceTransactionColumns + " FROM balance_transactions WHERE type = ? AND reference = ?"
+
+	var item models.BalanceTransaction
+	err := scanBalanceTransaction(b.db.QueryRowContext(ctx, query, txType, reference), &item)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
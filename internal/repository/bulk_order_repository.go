@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/imnzr/sim-service-project/models"
+)
+
+type BulkOrderRepository interface {
+	Create(ctx context.Context, bulk *models.BulkOrder) error
+	Complete(ctx context.Context, bulk *models.BulkOrder) error
+}
+
+type BulkOrderImplementation struct {
+	db *sql.DB
+}
+
+func NewBulkOrderRepository(db *sql.DB) BulkOrderRepository {
+	return &BulkOrderImplementation{
+		db: db,
+	}
+}
+
+// Create implements BulkOrderRepository.
+func (b *BulkOrderImplementation) Create(ctx context.Context, bulk *models.BulkOrder) error {
+	query := "INSERT INTO bulk_orders(user_id, status, quantity, total_amount) VALUES(?,?,?,?)"
+
+	result, err := b.db.ExecContext(ctx, query, bulk.UserId, bulk.Status, bulk.Quantity, bulk.TotalAmount)
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	bulk.Id = int(id)
+	return nil
+}
+
+// Complete implements BulkOrderRepository.
+// Menyimpan status akhir dan jumlah yang di-refund.
+func (b *BulkOrderImplementation) Complete(ctx context.Context, bulk *models.BulkOrder) error {
+	query := "UPDATE bulk_orders SET status = ?, refunded_amount = ?, completed_at = ? WHERE id = ?"
+
+	_, err := b.db.ExecContext(ctx, query, bulk.Status, bulk.RefundedAmount, bulk.CompletedAt, bulk.Id)
+	return err
+}
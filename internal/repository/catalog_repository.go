@@ -0,0 +1,222 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/imnzr/sim-service-project/models"
+)
+
+// CatalogRepository membaca katalog dari tabel product untuk kebutuhan browse customer
+type CatalogRepository interface {
+	ListCountries(ctx context.Context, query models.CatalogQuery) ([]models.CatalogCountry, int, error)
+	ListServices(ctx context.Context, country string, query models.CatalogQuery) ([]models.CatalogService, int, error)
+	ListOperators(ctx context.Context, country, service string, query models.CatalogQuery) ([]models.CatalogOperator, int, error)
+	SearchProducts(ctx context.Context, query models.CatalogQuery) ([]models.CatalogProduct, int, error)
+}
+
+type CatalogImplementation struct {
+	db *sql.DB
+}
+
+func NewCatalogRepository(db *sql.DB) CatalogRepository {
+	return &CatalogImplementation{
+		db: db,
+	}
+}
+
+// catalogSource menggabungkan product dengan product_overrides level operator (op), country (oc) dan service (os).
+// Nilai override yang lebih spesifik didahulukan lewat COALESCE.
+const catalogSource = `
+	product p
+	LEFT JOIN product_overrides op ON op.service = p.service AND op.country = p.country AND op.operator = p.operator
+	LEFT JOIN product_overrides oc ON oc.service = p.service AND oc.country = p.country AND oc.operator = ''
+	LEFT JOIN product_overrides os ON os.service = p.service AND os.country = '' AND os.operator = ''`
+
+const (
+	catalogEnabled     = "COALESCE(op.enabled, oc.enabled, os.enabled, 1) = 1"
+	catalogPrice       = "COALESCE(op.pinned_price, oc.pinned_price, os.pinned_price, p.price_sell)"
+	catalogDisplayName = "COALESCE(op.display_name, oc.display_name, os.display_name, p.service)"
+	catalogIconURL     = "COALESCE(op.icon_url, oc.icon_url, os.icon_url)"
+	catalogSellable    = "p.available = 1 AND p.stock > 0 AND " + catalogEnabled
+)
+
+// orderBy memetakan sort dari request ke klausa ORDER BY yang aman (whitelist)
+func orderBy(sort, nameColumn, priceColumn string) string {
+	switch sort {
+	case "price_asc":
+		return priceColumn + " ASC, " + nameColumn + " ASC"
+	case "price_desc":
+		return priceColumn + " DESC, " + nameColumn + " ASC"
+	default:
+		return nameColumn + " ASC"
+	}
+}
+
+func offset(query models.CatalogQuery) int {
+	return (query.Page - 1) * query.Limit
+}
+
+// likePattern meng-escape wildcard LIKE dari input user
+func likePattern(search string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+	return "%" + replacer.Replace(search) + "%"
+}
+
+// ListCountries implements CatalogRepository.
+func (c *CatalogImplementation) ListCountries(ctx context.Context, query models.CatalogQuery) ([]models.CatalogCountry, int, error) {
+	where := "WHERE " + catalogSellable + " AND p.country LIKE ?"
+	args := []any{likePattern(query.Search)}
+
+	var total int
+	err := c.db.QueryRowContext(ctx, "SELECT COUNT(DISTINCT p.country) FROM "+catalogSource+" "+where, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := c.db.QueryContext(ctx, `
+		SELECT p.country, COUNT(DISTINCT p.service), MIN(`+catalogPrice+`) AS min_price
+		FROM `+catalogSource+` `+where+`
+		GROUP BY p.country
+		ORDER BY `+orderBy(query.Sort, "p.country", "min_price")+`
+		LIMIT ? OFFSET ?`,
+		append(args, query.Limit, offset(query))...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	countries := []models.CatalogCountry{}
+	for rows.Next() {
+		var country models.CatalogCountry
+		if err := rows.Scan(&country.Country, &country.ServiceCount, &country.MinPrice); err != nil {
+			return nil, 0, err
+		}
+		countries = append(countries, country)
+	}
+	return countries, total, rows.Err()
+}
+
+// ListServices implements CatalogRepository.
+// Pencarian dilakukan pada nama service dari 5sim maupun display name dari override.
+func (c *CatalogImplementation) ListServices(ctx context.Context, country string, query models.CatalogQuery) ([]models.CatalogService, int, error) {
+	where := "WHERE " + catalogSellable + " AND p.country = ? AND (p.service LIKE ? OR " + catalogDisplayName + " LIKE ?)"
+	pattern := likePattern(query.Search)
+	args := []any{country, pattern, pattern}
+
+	var total int
+	err := c.db.QueryRowContext(ctx, "SELECT COUNT(DISTINCT p.service) FROM "+catalogSource+" "+where, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := c.db.QueryContext(ctx, `
+		SELECT p.service, MAX(`+catalogDisplayName+`) AS display_name, MAX(`+catalogIconURL+`),
+			COUNT(*), MIN(`+catalogPrice+`) AS min_price
+		FROM `+catalogSource+` `+where+`
+		GROUP BY p.service
+		ORDER BY `+orderBy(query.Sort, "display_name", "min_price")+`
+		LIMIT ? OFFSET ?`,
+		append(args, query.Limit, offset(query))...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	services := []models.CatalogService{}
+	for rows.Next() {
+		var service models.CatalogService
+		if err := rows.Scan(&service.Service, &service.DisplayName, &service.IconURL, &service.OperatorCount, &service.MinPrice); err != nil {
+			return nil, 0, err
+		}
+		services = append(services, service)
+	}
+	return services, total, rows.Err()
+}
+
+// ListOperators implements CatalogRepository.
+// Operator yang tidak tersedia atau stoknya habis tetap ditampilkan dengan flag available/in_stock,
+// sedangkan operator yang dinonaktifkan admin disembunyikan.
+func (c *CatalogImplementation) ListOperators(ctx context.Context, country string, service string, query models.CatalogQuery) ([]models.CatalogOperator, int, error) {
+	where := "WHERE " + catalogEnabled + " AND p.country = ? AND p.service = ? AND p.operator LIKE ?"
+	args := []any{country, service, likePattern(query.Search)}
+
+	var total int
+	err := c.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+catalogSource+" "+where, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	sort := query.Sort
+	if sort == "" {
+		sort = "price_asc"
+	}
+	rows, err := c.db.QueryContext(ctx, `
+		SELECT p.operator, `+catalogPrice+` AS price, p.available, p.stock
+		FROM `+catalogSource+` `+where+`
+		ORDER BY p.available DESC, p.stock > 0 DESC, `+orderBy(sort, "p.operator", "price")+`
+		LIMIT ? OFFSET ?`,
+		append(args, query.Limit, offset(query))...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	operators := []models.CatalogOperator{}
+	for rows.Next() {
+		var operator models.CatalogOperator
+		if err := rows.Scan(&operator.Operator, &operator.PriceSell, &operator.Available, &operator.Stock); err != nil {
+			return nil, 0, err
+		}
+		operator.InStock = operator.Available && operator.Stock > 0
+		operators = append(operators, operator)
+	}
+	return operators, total, rows.Err()
+}
+
+// SearchProducts implements CatalogRepository.
+func (c *CatalogImplementation) SearchProducts(ctx context.Context, query models.CatalogQuery) ([]models.CatalogProduct, int, error) {
+	where := "WHERE " + catalogSellable + " AND (p.service LIKE ? OR " + catalogDisplayName + " LIKE ?)"
+	pattern := likePattern(query.Search)
+	args := []any{pattern, pattern}
+
+	var total int
+	err := c.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+catalogSource+" "+where, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := c.db.QueryContext(ctx, `
+		SELECT p.service, `+catalogDisplayName+` AS display_name, `+catalogIconURL+`, p.country, p.operator, `+catalogPrice+` AS price
+		FROM `+catalogSource+` `+where+`
+		ORDER BY `+orderBy(query.Sort, "display_name", "price")+`, p.country ASC, p.operator ASC
+		LIMIT ? OFFSET ?`,
+		append(args, query.Limit, offset(query))...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	products := []models.CatalogProduct{}
+	for rows.Next() {
+		var product models.CatalogProduct
+		err := rows.Scan(
+			&product.Service,
+			&product.DisplayName,
+			&product.IconURL,
+			&product.Country,
+			&product.Operator,
+			&product.PriceSell,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		products = append(products, product)
+	}
+	return products, total, rows.Err()
+}
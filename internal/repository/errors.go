@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// ErrDuplicateEntry dikembalikan jika insert/update melanggar unique index
+var ErrDuplicateEntry = errors.New("duplicate entry")
+
+// mysqlErrDuplicateEntry adalah kode error MySQL ER_DUP_ENTRY
+const mysqlErrDuplicateEntry = 1062
+
+func isDuplicateEntry(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrDuplicateEntry
+}
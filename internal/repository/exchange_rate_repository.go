@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/imnzr/sim-service-project/models"
+)
+
+type ExchangeRateRepository interface {
+	Save(ctx context.Context, rate *models.ExchangeRate) error
+	GetLatest(ctx context.Context, base, quote string) (*models.ExchangeRate, error)
+	GetActiveOverride(ctx context.Context, base, quote string) (*models.ExchangeRate, error)
+	SetOverride(ctx context.Context, rate *models.ExchangeRate) error
+	ClearOverride(ctx context.Context, base, quote string) error
+}
+
+type ExchangeRateImplementation struct {
+	db *sql.DB
+}
+
+func NewExchangeRateRepository(db *sql.DB) ExchangeRateRepository {
+	return &ExchangeRateImplementation{
+		db: db,
+	}
+}
+
+const exchangeRateColumns = "id, base_currency, quote_currency, rate, source, active_override, fetched_at"
+
+func scanExchangeRate(row *sql.Row) (*models.ExchangeRate, error) {
+	var rate models.ExchangeRate
+	err := row.Scan(
+		&rate.Id,
+		&rate.BaseCurrency,
+		&rate.QuoteCurrency,
+		&rate.Rate,
+		&rate.Source,
+		&rate.ActiveOverride,
+		&rate.FetchedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &rate, nil
+}
+
+// Save implements ExchangeRateRepository.
+func (e *ExchangeRateImplementation) Save(ctx context.Context, rate *models.ExchangeRate) error {
+	query := `
+		INSERT INTO exchange_rates(base_currency, quote_currency, rate, source, active_override, fetched_at)
+		VALUES(?,?,?,?,?,?)
+	`
+	result, err := e.db.ExecContext(ctx, query,
+		rate.BaseCurrency,
+		rate.QuoteCurrency,
+		rate.Rate,
+		rate.Source,
+		rate.ActiveOverride,
+		rate.FetchedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	rate.Id = int(id)
+	return nil
+}
+
+// GetLatest implements ExchangeRateRepository.
+// Hanya kurs dari source otomatis, override manual diambil lewat GetActiveOverride.
+func (e *ExchangeRateImplementation) GetLatest(ctx context.Context, base string, quote string) (*models.ExchangeRate, error) {
+	query := "SELECT " + exchangeRateColumns + ` FROM exchange_rates
+		WHERE base_currency = ? AND quote_currency = ? AND source <> 'manual'
+		ORDER BY fetched_at DESC, id DESC LIMIT 1`
+	return scanExchangeRate(e.db.QueryRowContext(ctx, query, base, quote))
+}
+
+// GetActiveOverride implements ExchangeRateRepository.
+func (e *ExchangeRateImplementation) GetActiveOverride(ctx context.Context, base string, quote string) (*models.ExchangeRate, error) {
+	query := "SELECT " + exchangeRateColumns + ` FROM exchange_rates
+		WHERE base_currency = ? AND quote_currency = ? AND active_override = 1
+		ORDER BY fetched_at DESC, id DESC LIMIT 1`
+	return scanExchangeRate(e.db.QueryRowContext(ctx, query, base, quote))
+}
+
+// SetOverride implements ExchangeRateRepository.
+// Override sebelumnya dinonaktifkan sehingga hanya ada satu override aktif per pasangan mata uang.
+func (e *ExchangeRateImplementation) SetOverride(ctx context.Context, rate *models.ExchangeRate) error {
+	tx, err := e.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE exchange_rates SET active_override = 0 WHERE base_currency = ? AND quote_currency = ? AND active_override = 1",
+		rate.BaseCurrency, rate.QuoteCurrency,
+	)
+	if err != nil {
+		return err
+	}
+
+	rate.ActiveOverride = true
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO exchange_rates(base_currency, quote_currency, rate, source, active_override, fetched_at)
+		VALUES(?,?,?,?,1,?)
+	`, rate.BaseCurrency, rate.QuoteCurrency, rate.Rate, rate.Source, rate.FetchedAt)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	rate.Id = int(id)
+
+	return tx.Commit()
+}
+
+// ClearOverride implements ExchangeRateRepository.
+func (e *ExchangeRateImplementation) ClearOverride(ctx context.Context, base string, quote string) error {
+	_, err := e.db.ExecContext(ctx,
+		"UPDATE exchange_rates SET active_override = 0 WHERE base_currency = ? AND quote_currency = ? AND active_override = 1",
+		base, quote,
+	)
+	return err
+}
@@ -3,7 +3,10 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"strings"
+	"time"
 
+	"github.com/imnzr/sim-service-project/helper"
 	"github.com/imnzr/sim-service-project/models"
 )
 
@@ -14,6 +17,17 @@ type SimOrderRepository interface {
 	GetByInvoiceId(ctx context.Context, invoiceId string) (*models.SimOrder, error)
 	GetById(ctx context.Context, id int) (*models.SimOrder, error)
 	AttachSimDataService(ctx context.Context, orderId int, data *models.ResponsOrderFromService) error
+	MarkFailed(ctx context.Context, orderId int, reason string) error
+	UpdateFromProvider(ctx context.Context, orderId int, status string, otp *string) error
+	MarkRefunded(ctx context.Context, orderId int, source, reason string) (bool, error)
+	HasStatus(ctx context.Context, orderId int, status string) (bool, error)
+	GetStatusHistory(ctx context.Context, orderId int) ([]models.OrderStatusHistory, error)
+	RecordProviderResponse(ctx context.Context, response *models.OrderProviderResponse) error
+	GetProviderResponses(ctx context.Context, orderId int) ([]models.OrderProviderResponse, error)
+	Search(ctx context.Context, query models.AdminOrderQuery) ([]models.SimOrder, int, error)
+	FindPaymentsByUser(ctx context.Context, userId int, limit int) ([]models.UserPayment, error)
+	SumPaidVolume(ctx context.Context, userId int, since time.Time) (float64, error)
+	FindActiveProviderOrders(ctx context.Context, since time.Time, limit int) ([]models.SimOrder, error)
 }
 
 type SimOrderImplement struct {
@@ -26,13 +40,37 @@ func NewOrderRepository(db *sql.DB) SimOrderRepository {
 	}
 }
 
-// GetById implements SimOrderRepository.
-func (s *SimOrderImplement) GetById(ctx context.Context, id int) (*models.SimOrder, error) {
-	query := "SELECT * FROM sim_orders WHERE id = ?"
-	row := s.db.QueryRowContext(ctx, query, id)
+const simOrderColumns = `id, user_id, service, country, operator, price, exchange_rate, exchange_rate_id, invoice_id,
+	sim_order_service_id, phone_number, otp, status, error_message, refunded_at, refund_reason, bulk_order_id, created_at, updated_at`
 
-	var order models.SimOrder
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// extraScanner menambahkan kolom tambahan di akhir Scan, dipakai untuk query yang memilih kolom selain simOrderColumns
+type extraScanner struct {
+	row   rowScanner
+	extra []any
+}
+
+func (e extraScanner) Scan(dest ...any) error {
+	return e.row.Scan(append(dest, e.extra...)...)
+}
+
+// execer dipenuhi oleh *sql.DB dan *sql.Tx
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// insertStatusHistory mencatat status baru order, dipanggil dalam transaksi yang sama dengan update status
+func insertStatusHistory(ctx context.Context, exec execer, orderId int, status, source string, note *string) error {
+	query := "INSERT INTO sim_order_status_history(order_id, status, source, note) VALUES(?,?,?,?)"
+	_, err := exec.ExecContext(ctx, query, orderId, status, source, note)
+	return err
+}
 
+func scanSimOrder(row rowScanner, order *models.SimOrder) error {
+	var invoiceId sql.NullString
 	err := row.Scan(
 		&order.Id,
 		&order.UserId,
@@ -40,15 +78,32 @@ func (s *SimOrderImplement) GetById(ctx context.Context, id int) (*models.SimOrd
 		&order.Country,
 		&order.Operator,
 		&order.PriceSell,
-		&order.InvoiceId,
+		&order.ExchangeRate,
+		&order.ExchangeRateId,
+		&invoiceId,
 		&order.SimOrderServiceId,
 		&order.PhoneNumber,
 		&order.OTP,
 		&order.Status,
 		&order.ErrorMessage,
+		&order.RefundedAt,
+		&order.RefundReason,
+		&order.BulkOrderId,
 		&order.CreatedAt,
 		&order.UpdatedAt,
 	)
+	order.InvoiceId = invoiceId.String
+	return err
+}
+
+// GetById implements SimOrderRepository.
+func (s *SimOrderImplement) GetById(ctx context.Context, id int) (*models.SimOrder, error) {
+	query := "SELECT " + simOrderColumns + " FROM sim_orders WHERE id = ?"
+	row := s.db.QueryRowContext(ctx, query, id)
+
+	var order models.SimOrder
+
+	err := scanSimOrder(row, &order)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -61,67 +116,114 @@ func (s *SimOrderImplement) GetById(ctx context.Context, id int) (*models.SimOrd
 
 // AttachSimDataService implements SimOrderRepository.
 func (s *SimOrderImplement) AttachSimDataService(ctx context.Context, orderId int, data *models.ResponsOrderFromService) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
 	query := `
 		UPDATE sim_orders
 		SET sim_order_service_id = ?, phone_number = ?, status = ?, updated_at = NOW()
 		WHERE id = ?
 	`
-	_, err := s.db.ExecContext(ctx, query,
+	_, err = tx.ExecContext(ctx, query,
 		data.Id,
 		data.Phone,
 		data.Status,
 		orderId,
 	)
-	return err
+	if err != nil {
+		return err
+	}
+	if err := insertStatusHistory(ctx, tx, orderId, data.Status, models.OrderSourceProvider, nil); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 // CreateOrder implements SimOrderRepository.
+// Row dibuat dan invoice_id (yang berisi order id) diisi dalam satu transaksi,
+// lalu order.Id dan order.InvoiceId diperbarui.
 func (s *SimOrderImplement) Create(ctx context.Context, order *models.SimOrder) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
 	query := `
-		INSERT INTO sim_orders(user_id, service, country, operator, price, invoice_id, status)
-		VALUES(?,?,?,?,?,?,?)
+		INSERT INTO sim_orders(user_id, service, country, operator, price, exchange_rate, exchange_rate_id, invoice_id, status, bulk_order_id)
+		VALUES(?,?,?,?,?,?,?,NULL,?,?)
 	`
-	result, err := s.db.ExecContext(ctx, query,
+	result, err := tx.ExecContext(ctx, query,
 		order.UserId,
 		order.Service,
 		order.Country,
 		order.Operator,
 		order.PriceSell,
-		order.InvoiceId,
+		order.ExchangeRate,
+		order.ExchangeRateId,
 		order.Status,
+		order.BulkOrderId,
 	)
 	if err != nil {
 		return 0, err
 	}
 
 	id, err := result.LastInsertId()
-	return int(id), err
+	if err != nil {
+		return 0, err
+	}
+
+	invoiceId, err := helper.GenerateInvoiceId(int(id))
+	if err != nil {
+		return 0, err
+	}
+	if _, err := tx.ExecContext(ctx, "UPDATE sim_orders SET invoice_id = ? WHERE id = ?", invoiceId, id); err != nil {
+		return 0, err
+	}
+	if err := insertStatusHistory(ctx, tx, int(id), order.Status, models.OrderSourceCheckout, nil); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	order.Id = int(id)
+	order.InvoiceId = invoiceId
+	return int(id), nil
 }
 
-// GetByInvoiceId implements SimOrderRepository.
-func (s *SimOrderImplement) GetByInvoiceId(ctx context.Context, invoiceId string) (*models.SimOrder, error) {
+// MarkFailed implements SimOrderRepository.
+func (s *SimOrderImplement) MarkFailed(ctx context.Context, orderId int, reason string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
 	query := `
-		SELECT * FROM sim_orders WHERE invoice_id = ?
+		UPDATE sim_orders SET status = ?, error_message = ?, updated_at = NOW()
+		WHERE id = ?
 	`
+	if _, err := tx.ExecContext(ctx, query, models.OrderStatusFailed, reason, orderId); err != nil {
+		return err
+	}
+	if err := insertStatusHistory(ctx, tx, orderId, models.OrderStatusFailed, models.OrderSourceSystem, &reason); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetByInvoiceId implements SimOrderRepository.
+func (s *SimOrderImplement) GetByInvoiceId(ctx context.Context, invoiceId string) (*models.SimOrder, error) {
+	query := "SELECT " + simOrderColumns + " FROM sim_orders WHERE invoice_id = ?"
 	row := s.db.QueryRowContext(ctx, query, invoiceId)
 
 	var order models.SimOrder
-	err := row.Scan(
-		&order.Id,
-		&order.UserId,
-		&order.Service,
-		&order.Country,
-		&order.Operator,
-		&order.PriceSell,
-		&order.InvoiceId,
-		&order.SimOrderServiceId,
-		&order.PhoneNumber,
-		&order.OTP,
-		&order.Status,
-		&order.ErrorMessage,
-		&order.CreatedAt,
-		&order.UpdatedAt,
-	)
+	err := scanSimOrder(row, &order)
 	if err != nil {
 		return nil, err
 	}
@@ -130,20 +232,316 @@ func (s *SimOrderImplement) GetByInvoiceId(ctx context.Context, invoiceId string
 
 // UpdateAfterPayment implements SimOrderRepository.
 func (s *SimOrderImplement) UpdateAfterPayment(ctx context.Context, invoiceId string, simServiceId int, phoneNumber string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
 	query := `
 		UPDATE sim_orders SET sim_order_service_id = ?, phone_number = ?, status = 'ACTIVE'
 		WHERE invoice_id = ?
 	`
-	_, err := s.db.ExecContext(ctx, query, simServiceId, phoneNumber, invoiceId)
-	return err
+	if _, err := tx.ExecContext(ctx, query, simServiceId, phoneNumber, invoiceId); err != nil {
+		return err
+	}
+	history := `
+		INSERT INTO sim_order_status_history(order_id, status, source)
+		SELECT id, 'ACTIVE', ? FROM sim_orders WHERE invoice_id = ?
+	`
+	if _, err := tx.ExecContext(ctx, history, models.OrderSourcePayment, invoiceId); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 // UpdateStatusByInvoiceId implements SimOrderRepository.
 func (s *SimOrderImplement) UpdateStatusByInvoiceId(ctx context.Context, invoiceId string, status string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
 	query := `
-		UPDATE sim_orders SET status = ? WHERE invoice_id = ?
+		UPDATE sim_orders SET status = ?, updated_at = NOW() WHERE invoice_id = ?
 	`
-	_, err := s.db.ExecContext(ctx, query, status, invoiceId)
+	if _, err := tx.ExecContext(ctx, query, status, invoiceId); err != nil {
+		return err
+	}
+	history := `
+		INSERT INTO sim_order_status_history(order_id, status, source)
+		SELECT id, ?, ? FROM sim_orders WHERE invoice_id = ?
+	`
+	if _, err := tx.ExecContext(ctx, history, status, models.OrderSourcePayment, invoiceId); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
 
-	return err
+// UpdateFromProvider implements SimOrderRepository.
+// Dipakai saat re-check ke 5sim, history hanya dicatat jika status berubah.
+func (s *SimOrderImplement) UpdateFromProvider(ctx context.Context, orderId int, status string, otp *string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var current string
+	if err := tx.QueryRowContext(ctx, "SELECT status FROM sim_orders WHERE id = ? FOR UPDATE", orderId).Scan(&current); err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE sim_orders SET status = ?, otp = COALESCE(?, otp), updated_at = NOW()
+		WHERE id = ?
+	`
+	if _, err := tx.ExecContext(ctx, query, status, otp, orderId); err != nil {
+		return err
+	}
+	if current != status {
+		if err := insertStatusHistory(ctx, tx, orderId, status, models.OrderSourceProvider, nil); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// MarkRefunded implements SimOrderRepository.
+// Mengembalikan false jika order sudah pernah di-refund.
+func (s *SimOrderImplement) MarkRefunded(ctx context.Context, orderId int, source string, reason string) (bool, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE sim_orders SET status = ?, refunded_at = NOW(), refund_reason = ?, updated_at = NOW()
+		WHERE id = ? AND refunded_at IS NULL
+	`
+	result, err := tx.ExecContext(ctx, query, models.OrderStatusRefunded, reason, orderId)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if rows == 0 {
+		return false, nil
+	}
+
+	if err := insertStatusHistory(ctx, tx, orderId, models.OrderStatusRefunded, source, &reason); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// HasStatus implements SimOrderRepository.
+// Mengecek apakah order pernah berada di status tertentu, misalnya untuk memastikan order sudah dibayar.
+func (s *SimOrderImplement) HasStatus(ctx context.Context, orderId int, status string) (bool, error) {
+	query := "SELECT EXISTS(SELECT 1 FROM sim_order_status_history WHERE order_id = ? AND status = ?)"
+
+	var exists bool
+	err := s.db.QueryRowContext(ctx, query, orderId, status).Scan(&exists)
+	return exists, err
+}
+
+// GetStatusHistory implements SimOrderRepository.
+func (s *SimOrderImplement) GetStatusHistory(ctx context.Context, orderId int) ([]models.OrderStatusHistory, error) {
+	query := `
+		SELECT id, order_id, status, source, note, created_at
+		FROM sim_order_status_history WHERE order_id = ? ORDER BY id ASC
+	`
+	rows, err := s.db.QueryContext(ctx, query, orderId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := []models.OrderStatusHistory{}
+	for rows.Next() {
+		var item models.OrderStatusHistory
+		if err := rows.Scan(&item.Id, &item.OrderId, &item.Status, &item.Source, &item.Note, &item.CreatedAt); err != nil {
+			return nil, err
+		}
+		history = append(history, item)
+	}
+	return history, rows.Err()
+}
+
+// RecordProviderResponse implements SimOrderRepository.
+func (s *SimOrderImplement) RecordProviderResponse(ctx context.Context, response *models.OrderProviderResponse) error {
+	query := "INSERT INTO sim_order_provider_responses(order_id, provider, action, success, body) VALUES(?,?,?,?,?)"
+
+	result, err := s.db.ExecContext(ctx, query, response.OrderId, response.Provider, response.Action, response.Success, response.Body)
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	response.Id = id
+	return nil
+}
+
+// GetProviderResponses implements SimOrderRepository.
+func (s *SimOrderImplement) GetProviderResponses(ctx context.Context, orderId int) ([]models.OrderProviderResponse, error) {
+	query := `
+		SELECT id, order_id, provider, action, success, body, created_at
+		FROM sim_order_provider_responses WHERE order_id = ? ORDER BY id ASC
+	`
+	rows, err := s.db.QueryContext(ctx, query, orderId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	responses := []models.OrderProviderResponse{}
+	for rows.Next() {
+		var item models.OrderProviderResponse
+		if err := rows.Scan(&item.Id, &item.OrderId, &item.Provider, &item.Action, &item.Success, &item.Body, &item.CreatedAt); err != nil {
+			return nil, err
+		}
+		responses = append(responses, item)
+	}
+	return responses, rows.Err()
+}
+
+// Search implements SimOrderRepository.
+// Email diambil dari tabel users, order terbaru ditampilkan lebih dulu.
+func (s *SimOrderImplement) Search(ctx context.Context, query models.AdminOrderQuery) ([]models.SimOrder, int, error) {
+	conditions := []string{"1 = 1"}
+	args := []any{}
+	if query.UserId != 0 {
+		conditions = append(conditions, "user_id = ?")
+		args = append(args, query.UserId)
+	}
+	if query.Email != "" {
+		conditions = append(conditions, "user_id IN (SELECT id FROM users WHERE email = ?)")
+		args = append(args, query.Email)
+	}
+	if query.InvoiceId != "" {
+		conditions = append(conditions, "invoice_id = ?")
+		args = append(args, query.InvoiceId)
+	}
+	if query.PhoneNumber != "" {
+		conditions = append(conditions, "phone_number = ?")
+		args = append(args, query.PhoneNumber)
+	}
+	if query.ProviderOrderId != 0 {
+		conditions = append(conditions, "sim_order_service_id = ?")
+		args = append(args, query.ProviderOrderId)
+	}
+	if query.Status != "" {
+		conditions = append(conditions, "status = ?")
+		args = append(args, query.Status)
+	}
+	from := "FROM sim_orders WHERE " + strings.Join(conditions, " AND ")
+
+	var total int
+	if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) "+from, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT "+simOrderColumns+", COALESCE((SELECT email FROM users WHERE users.id = sim_orders.user_id), '') "+from+" ORDER BY id DESC LIMIT ? OFFSET ?",
+		append(args, query.Limit, (query.Page-1)*query.Limit)...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	orders := []models.SimOrder{}
+	for rows.Next() {
+		var order models.SimOrder
+		if err := scanSimOrder(extraScanner{rows, []any{&order.Email}}, &order); err != nil {
+			return nil, 0, err
+		}
+		orders = append(orders, order)
+	}
+	return orders, total, rows.Err()
+}
+
+// FindPaymentsByUser implements SimOrderRepository.
+// paid_at diambil dari riwayat status PAID pertama, order yang tidak punya invoice dilewati.
+func (s *SimOrderImplement) FindPaymentsByUser(ctx context.Context, userId int, limit int) ([]models.UserPayment, error) {
+	query := `
+		SELECT o.id, o.invoice_id, o.price, o.status,
+			(SELECT MIN(h.created_at) FROM sim_order_status_history h WHERE h.order_id = o.id AND h.status = ?),
+			o.refunded_at, o.created_at
+		FROM sim_orders o
+		WHERE o.user_id = ? AND o.invoice_id IS NOT NULL
+		ORDER BY o.id DESC LIMIT ?
+	`
+	rows, err := s.db.QueryContext(ctx, query, models.OrderStatusPaid, userId, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	payments := []models.UserPayment{}
+	for rows.Next() {
+		var payment models.UserPayment
+		var paidAt sql.NullTime
+		err := rows.Scan(
+			&payment.OrderId,
+			&payment.InvoiceId,
+			&payment.Amount,
+			&payment.Status,
+			&paidAt,
+			&payment.RefundedAt,
+			&payment.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if paidAt.Valid {
+			payment.PaidAt = &paidAt.Time
+		}
+		payments = append(payments, payment)
+	}
+	return payments, rows.Err()
+}
+
+// SumPaidVolume implements SimOrderRepository.
+// Total harga order user yang dibayar sejak waktu tertentu, order yang di-refund tidak dihitung.
+func (s *SimOrderImplement) SumPaidVolume(ctx context.Context, userId int, since time.Time) (float64, error) {
+	query := `
+		SELECT COALESCE(SUM(o.price), 0) FROM sim_orders o
+		WHERE o.user_id = ? AND o.refunded_at IS NULL
+			AND EXISTS(SELECT 1 FROM sim_order_status_history h WHERE h.order_id = o.id AND h.status = ? AND h.created_at >= ?)
+	`
+	var volume float64
+	err := s.db.QueryRowContext(ctx, query, userId, models.OrderStatusPaid, since).Scan(&volume)
+	return volume, err
+}
+
+// FindActiveProviderOrders implements SimOrderRepository.
+// Order yang sudah punya aktivasi 5sim dengan status belum final dan belum di-refund,
+// yang paling lama tidak diperbarui lebih dulu agar semua order mendapat giliran.
+func (s *SimOrderImplement) FindActiveProviderOrders(ctx context.Context, since time.Time, limit int) ([]models.SimOrder, error) {
+	query := "SELECT " + simOrderColumns + ` FROM sim_orders
+		WHERE sim_order_service_id IS NOT NULL AND refunded_at IS NULL AND created_at >= ?
+			AND status NOT IN ('FINISHED', 'CANCELED', 'TIMEOUT', 'BANNED', ?, ?)
+		ORDER BY updated_at ASC, id ASC LIMIT ?`
+	rows, err := s.db.QueryContext(ctx, query, since, models.OrderStatusFailed, models.OrderStatusRefunded, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orders := []models.SimOrder{}
+	for rows.Next() {
+		var order models.SimOrder
+		if err := scanSimOrder(rows, &order); err != nil {
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+	return orders, rows.Err()
 }
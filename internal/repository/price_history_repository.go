@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/imnzr/sim-service-project/models"
+)
+
+type PriceHistoryRepository interface {
+	Find(ctx context.Context, query models.PriceHistoryQuery) ([]models.ProductPriceHistory, error)
+}
+
+type PriceHistoryImplementation struct {
+	db *sql.DB
+}
+
+func NewPriceHistoryRepository(db *sql.DB) PriceHistoryRepository {
+	return &PriceHistoryImplementation{
+		db: db,
+	}
+}
+
+// insertPriceHistory dipanggil di dalam transaksi sync katalog (lihat ProductImplementation.BulkSync)
+func insertPriceHistory(ctx context.Context, tx *sql.Tx, history []models.ProductPriceHistory) error {
+	for start := 0; start < len(history); start += productBatchSize {
+		end := min(start+productBatchSize, len(history))
+		batch := history[start:end]
+
+		placeholders := make([]string, 0, len(batch))
+		args := make([]any, 0, len(batch)*12)
+		for _, row := range batch {
+			placeholders = append(placeholders, "(?,?,?,?,?,?,?,?,?,?,?,?)")
+			args = append(args,
+				row.Service,
+				row.Country,
+				row.Operator,
+				row.OldPriceDefault,
+				row.NewPriceDefault,
+				row.OldPriceSell,
+				row.NewPriceSell,
+				row.ExchangeRate,
+				row.ExchangeRateId,
+				row.PricingRuleId,
+				row.PricingRuleName,
+				row.ChangedAt,
+			)
+		}
+
+		query := `INSERT INTO product_price_history(service, country, operator, old_price_default, new_price_default,
+			old_price_sell, new_price_sell, exchange_rate, exchange_rate_id, pricing_rule_id, pricing_rule_name, changed_at) VALUES ` +
+			strings.Join(placeholders, ",")
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return fmt.Errorf("failed to insert price history: %w", err)
+		}
+	}
+	return nil
+}
+
+// Find implements PriceHistoryRepository.
+func (p *PriceHistoryImplementation) Find(ctx context.Context, query models.PriceHistoryQuery) ([]models.ProductPriceHistory, error) {
+	sqlQuery := `
+		SELECT id, service, country, operator, old_price_default, new_price_default, old_price_sell, new_price_sell,
+			exchange_rate, exchange_rate_id, pricing_rule_id, pricing_rule_name, changed_at
+		FROM product_price_history
+		WHERE service = ? AND country = ? AND operator = ?`
+	args := []any{query.Service, query.Country, query.Operator}
+
+	if query.From != nil {
+		sqlQuery += " AND changed_at >= ?"
+		args = append(args, *query.From)
+	}
+	if query.To != nil {
+		sqlQuery += " AND changed_at < ?"
+		args = append(args, *query.To)
+	}
+	sqlQuery += " ORDER BY changed_at DESC, id DESC LIMIT ?"
+	args = append(args, query.Limit)
+
+	rows, err := p.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := []models.ProductPriceHistory{}
+	for rows.Next() {
+		var row models.ProductPriceHistory
+		err := rows.Scan(
+			&row.Id,
+			&row.Service,
+			&row.Country,
+			&row.Operator,
+			&row.OldPriceDefault,
+			&row.NewPriceDefault,
+			&row.OldPriceSell,
+			&row.NewPriceSell,
+			&row.ExchangeRate,
+			&row.ExchangeRateId,
+			&row.PricingRuleId,
+			&row.PricingRuleName,
+			&row.ChangedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		history = append(history, row)
+	}
+	return history, rows.Err()
+}
@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/imnzr/sim-service-project/models"
+)
+
+type PriceTierRepository interface {
+	Create(ctx context.Context, tier *models.PriceTier) error
+	Update(ctx context.Context, tier *models.PriceTier) error
+	Delete(ctx context.Context, id int) error
+	GetById(ctx context.Context, id int) (*models.PriceTier, error)
+	FindAll(ctx context.Context) ([]models.PriceTier, error)
+	FindActive(ctx context.Context) ([]models.PriceTier, error)
+}
+
+type PriceTierImplementation struct {
+	db *sql.DB
+}
+
+func NewPriceTierRepository(db *sql.DB) PriceTierRepository {
+	return &PriceTierImplementation{
+		db: db,
+	}
+}
+
+const priceTierColumns = "id, name, discount_percent, monthly_volume_threshold, active, created_at, updated_at"
+
+func scanPriceTier(row rowScanner, tier *models.PriceTier) error {
+	return row.Scan(
+		&tier.Id,
+		&tier.Name,
+		&tier.DiscountPercent,
+		&tier.MonthlyVolumeThreshold,
+		&tier.Active,
+		&tier.CreatedAt,
+		&tier.UpdatedAt,
+	)
+}
+
+func (p *PriceTierImplementation) query(ctx context.Context, query string, args ...any) ([]models.PriceTier, error) {
+	rows, err := p.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tiers := []models.PriceTier{}
+	for rows.Next() {
+		var tier models.PriceTier
+		if err := scanPriceTier(rows, &tier); err != nil {
+			return nil, err
+		}
+		tiers = append(tiers, tier)
+	}
+	return tiers, rows.Err()
+}
+
+// Create implements PriceTierRepository.
+func (p *PriceTierImplementation) Create(ctx context.Context, tier *models.PriceTier) error {
+	query := "INSERT INTO price_tiers(name, discount_percent, monthly_volume_threshold, active) VALUES(?,?,?,?)"
+
+	result, err := p.db.ExecContext(ctx, query, tier.Name, tier.DiscountPercent, tier.MonthlyVolumeThreshold, tier.Active)
+	if err != nil {
+		if isDuplicateEntry(err) {
+			return ErrDuplicateEntry
+		}
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	tier.Id = int(id)
+	return nil
+}
+
+// Update implements PriceTierRepository.
+func (p *PriceTierImplementation) Update(ctx context.Context, tier *models.PriceTier) error {
+	query := `
+		UPDATE price_tiers SET name = ?, discount_percent = ?, monthly_volume_threshold = ?, active = ?, updated_at = NOW()
+		WHERE id = ?
+	`
+	_, err := p.db.ExecContext(ctx, query, tier.Name, tier.DiscountPercent, tier.MonthlyVolumeThreshold, tier.Active, tier.Id)
+	if isDuplicateEntry(err) {
+		return ErrDuplicateEntry
+	}
+	return err
+}
+
+// Delete implements PriceTierRepository.
+// User yang memakai tier ini kembali ke harga retail.
+func (p *PriceTierImplementation) Delete(ctx context.Context, id int) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "UPDATE users SET price_tier_id = NULL WHERE price_tier_id = ?", id); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM price_tiers WHERE id = ?", id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetById implements PriceTierRepository.
+func (p *PriceTierImplementation) GetById(ctx context.Context, id int) (*models.PriceTier, error) {
+	row := p.db.QueryRowContext(ctx, "SELECT "+priceTierColumns+" FROM price_tiers WHERE id = ?", id)
+
+	var tier models.PriceTier
+	if err := scanPriceTier(row, &tier); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &tier, nil
+}
+
+// FindAll implements PriceTierRepository.
+func (p *PriceTierImplementation) FindAll(ctx context.Context) ([]models.PriceTier, error) {
+	return p.query(ctx, "SELECT "+priceTierColumns+" FROM price_tiers ORDER BY discount_percent ASC, id ASC")
+}
+
+// FindActive implements PriceTierRepository.
+func (p *PriceTierImplementation) FindActive(ctx context.Context) ([]models.PriceTier, error) {
+	return p.query(ctx, "SELECT "+priceTierColumns+" FROM price_tiers WHERE active = 1 ORDER BY discount_percent ASC, id ASC")
+}
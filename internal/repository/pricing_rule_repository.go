@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/imnzr/sim-service-project/models"
+)
+
+type PricingRuleRepository interface {
+	Create(ctx context.Context, rule *models.PricingRule) error
+	Update(ctx context.Context, rule *models.PricingRule) error
+	Delete(ctx context.Context, id int) error
+	GetById(ctx context.Context, id int) (*models.PricingRule, error)
+	FindAll(ctx context.Context) ([]models.PricingRule, error)
+	FindActive(ctx context.Context) ([]models.PricingRule, error)
+}
+
+type PricingRuleImplementation struct {
+	db *sql.DB
+}
+
+func NewPricingRuleRepository(db *sql.DB) PricingRuleRepository {
+	return &PricingRuleImplementation{
+		db: db,
+	}
+}
+
+const pricingRuleColumns = `id, name, service, country, operator, priority, markup_percent, markup_fixed,
+	min_margin, round_to, active, created_at, updated_at`
+
+func scanPricingRule(row rowScanner, rule *models.PricingRule) error {
+	return row.Scan(
+		&rule.Id,
+		&rule.Name,
+		&rule.Service,
+		&rule.Country,
+		&rule.Operator,
+		&rule.Priority,
+		&rule.MarkupPercent,
+		&rule.MarkupFixed,
+		&rule.MinMargin,
+		&rule.RoundTo,
+		&rule.Active,
+		&rule.CreatedAt,
+		&rule.UpdatedAt,
+	)
+}
+
+func (p *PricingRuleImplementation) query(ctx context.Context, query string, args ...any) ([]models.PricingRule, error) {
+	rows, err := p.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []models.PricingRule
+	for rows.Next() {
+		var rule models.PricingRule
+		if err := scanPricingRule(rows, &rule); err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+// Create implements PricingRuleRepository.
+func (p *PricingRuleImplementation) Create(ctx context.Context, rule *models.PricingRule) error {
+	query := `
+		INSERT INTO pricing_rules(name, service, country, operator, priority, markup_percent, markup_fixed, min_margin, round_to, active)
+		VALUES(?,?,?,?,?,?,?,?,?,?)
+	`
+	result, err := p.db.ExecContext(ctx, query,
+		rule.Name,
+		rule.Service,
+		rule.Country,
+		rule.Operator,
+		rule.Priority,
+		rule.MarkupPercent,
+		rule.MarkupFixed,
+		rule.MinMargin,
+		rule.RoundTo,
+		rule.Active,
+	)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	rule.Id = int(id)
+	return nil
+}
+
+// Update implements PricingRuleRepository.
+func (p *PricingRuleImplementation) Update(ctx context.Context, rule *models.PricingRule) error {
+	query := `
+		UPDATE pricing_rules
+		SET name = ?, service = ?, country = ?, operator = ?, priority = ?, markup_percent = ?,
+			markup_fixed = ?, min_margin = ?, round_to = ?, active = ?, updated_at = NOW()
+		WHERE id = ?
+	`
+	_, err := p.db.ExecContext(ctx, query,
+		rule.Name,
+		rule.Service,
+		rule.Country,
+		rule.Operator,
+		rule.Priority,
+		rule.MarkupPercent,
+		rule.MarkupFixed,
+		rule.MinMargin,
+		rule.RoundTo,
+		rule.Active,
+		rule.Id,
+	)
+	return err
+}
+
+// Delete implements PricingRuleRepository.
+func (p *PricingRuleImplementation) Delete(ctx context.Context, id int) error {
+	_, err := p.db.ExecContext(ctx, "DELETE FROM pricing_rules WHERE id = ?", id)
+	return err
+}
+
+// GetById implements PricingRuleRepository.
+func (p *PricingRuleImplementation) GetById(ctx context.Context, id int) (*models.PricingRule, error) {
+	row := p.db.QueryRowContext(ctx, "SELECT "+pricingRuleColumns+" FROM pricing_rules WHERE id = ?", id)
+
+	var rule models.PricingRule
+	if err := scanPricingRule(row, &rule); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// FindAll implements PricingRuleRepository.
+func (p *PricingRuleImplementation) FindAll(ctx context.Context) ([]models.PricingRule, error) {
+	return p.query(ctx, "SELECT "+pricingRuleColumns+" FROM pricing_rules ORDER BY priority DESC, id ASC")
+}
+
+// FindActive implements PricingRuleRepository.
+func (p *PricingRuleImplementation) FindActive(ctx context.Context) ([]models.PricingRule, error) {
+	return p.query(ctx, "SELECT "+pricingRuleColumns+" FROM pricing_rules WHERE active = 1 ORDER BY priority DESC, id ASC")
+}
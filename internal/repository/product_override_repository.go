@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/imnzr/sim-service-project/models"
+)
+
+type ProductOverrideRepository interface {
+	Upsert(ctx context.Context, override *models.ProductOverride) error
+	Delete(ctx context.Context, id int) error
+	GetById(ctx context.Context, id int) (*models.ProductOverride, error)
+	FindAll(ctx context.Context, service string) ([]models.ProductOverride, error)
+	FindForProduct(ctx context.Context, service, country, operator string) ([]models.ProductOverride, error)
+}
+
+type ProductOverrideImplementation struct {
+	db *sql.DB
+}
+
+func NewProductOverrideRepository(db *sql.DB) ProductOverrideRepository {
+	return &ProductOverrideImplementation{
+		db: db,
+	}
+}
+
+const productOverrideColumns = "id, service, country, operator, enabled, pinned_price, display_name, icon_url, created_at, updated_at"
+
+func scanProductOverride(row rowScanner, override *models.ProductOverride) error {
+	return row.Scan(
+		&override.Id,
+		&override.Service,
+		&override.Country,
+		&override.Operator,
+		&override.Enabled,
+		&override.PinnedPrice,
+		&override.DisplayName,
+		&override.IconURL,
+		&override.CreatedAt,
+		&override.UpdatedAt,
+	)
+}
+
+func (p *ProductOverrideImplementation) query(ctx context.Context, query string, args ...any) ([]models.ProductOverride, error) {
+	rows, err := p.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	overrides := []models.ProductOverride{}
+	for rows.Next() {
+		var override models.ProductOverride
+		if err := scanProductOverride(rows, &override); err != nil {
+			return nil, err
+		}
+		overrides = append(overrides, override)
+	}
+	return overrides, rows.Err()
+}
+
+// Upsert implements ProductOverrideRepository.
+func (p *ProductOverrideImplementation) Upsert(ctx context.Context, override *models.ProductOverride) error {
+	query := `
+		INSERT INTO product_overrides(service, country, operator, enabled, pinned_price, display_name, icon_url)
+		VALUES(?,?,?,?,?,?,?)
+		ON DUPLICATE KEY UPDATE enabled = VALUES(enabled), pinned_price = VALUES(pinned_price),
+			display_name = VALUES(display_name), icon_url = VALUES(icon_url)
+	`
+	_, err := p.db.ExecContext(ctx, query,
+		override.Service,
+		override.Country,
+		override.Operator,
+		override.Enabled,
+		override.PinnedPrice,
+		override.DisplayName,
+		override.IconURL,
+	)
+	if err != nil {
+		return err
+	}
+
+	// LastInsertId tidak bisa diandalkan untuk ON DUPLICATE KEY UPDATE, baca ulang row-nya
+	row := p.db.QueryRowContext(ctx,
+		"SELECT "+productOverrideColumns+" FROM product_overrides WHERE service = ? AND country = ? AND operator = ?",
+		override.Service, override.Country, override.Operator,
+	)
+	return scanProductOverride(row, override)
+}
+
+// Delete implements ProductOverrideRepository.
+func (p *ProductOverrideImplementation) Delete(ctx context.Context, id int) error {
+	_, err := p.db.ExecContext(ctx, "DELETE FROM product_overrides WHERE id = ?", id)
+	return err
+}
+
+// GetById implements ProductOverrideRepository.
+func (p *ProductOverrideImplementation) GetById(ctx context.Context, id int) (*models.ProductOverride, error) {
+	row := p.db.QueryRowContext(ctx, "SELECT "+productOverrideColumns+" FROM product_overrides WHERE id = ?", id)
+
+	var override models.ProductOverride
+	if err := scanProductOverride(row, &override); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &override, nil
+}
+
+// FindAll implements ProductOverrideRepository.
+func (p *ProductOverrideImplementation) FindAll(ctx context.Context, service string) ([]models.ProductOverride, error) {
+	if service != "" {
+		return p.query(ctx, "SELECT "+productOverrideColumns+" FROM product_overrides WHERE service = ? ORDER BY country, operator", service)
+	}
+	return p.query(ctx, "SELECT "+productOverrideColumns+" FROM product_overrides ORDER BY service, country, operator")
+}
+
+// FindForProduct implements ProductOverrideRepository.
+// Mengembalikan override level service, service+country dan service+country+operator.
+func (p *ProductOverrideImplementation) FindForProduct(ctx context.Context, service string, country string, operator string) ([]models.ProductOverride, error) {
+	query := "SELECT " + productOverrideColumns + ` FROM product_overrides
+		WHERE service = ? AND (
+			(country = '' AND operator = '') OR
+			(country = ? AND operator = '') OR
+			(country = ? AND operator = ?)
+		)`
+	return p.query(ctx, query, service, country, country, operator)
+}
@@ -5,14 +5,18 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
-	"log"
+	"strings"
 
 	"github.com/imnzr/sim-service-project/models"
 )
 
+// jumlah row per statement INSERT ... ON DUPLICATE KEY UPDATE saat sync katalog
+const productBatchSize = 500
+
 type ProductRepository interface {
-	Upsert(ctx context.Context, product *models.SimProduct) error
+	BulkSync(ctx context.Context, upserts []models.SimProduct, unavailableIds []int, history []models.ProductPriceHistory) error
 	FindByKey(ctx context.Context, service, country, operator string) (*models.SimProduct, error)
+	FindAll(ctx context.Context) ([]models.SimProduct, error)
 }
 
 type ProductImplementation struct {
@@ -25,50 +29,77 @@ func NewProductRepository(db *sql.DB) ProductRepository {
 	}
 }
 
-// Upsert implements ProductRepository.
-func (p *ProductImplementation) Upsert(ctx context.Context, product *models.SimProduct) error {
-	query := "INSERT INTO product(service, country, operator, price_default, price_sell) VALUES(?,?,?,?,?)"
+const productColumns = "id, service, country, operator, price_default, price_sell, available, stock"
 
-	result, err := p.db.ExecContext(ctx, query,
-		product.Service,
-		product.Country,
-		product.Operator,
-		product.PriceDefault,
-		product.PriceSell,
+func scanProduct(row rowScanner, product *models.SimProduct) error {
+	return row.Scan(
+		&product.Id,
+		&product.Service,
+		&product.Country,
+		&product.Operator,
+		&product.PriceDefault,
+		&product.PriceSell,
+		&product.Available,
+		&product.Stock,
 	)
+}
 
+// BulkSync implements ProductRepository.
+// Semua upsert (per batch), penandaan produk yang hilang dan riwayat harga dijalankan dalam satu transaksi.
+func (p *ProductImplementation) BulkSync(ctx context.Context, upserts []models.SimProduct, unavailableIds []int, history []models.ProductPriceHistory) error {
+	tx, err := p.db.BeginTx(ctx, nil)
 	if err != nil {
-		log.Printf("failed to insert product: %v\n", err)
 		return err
 	}
+	defer tx.Rollback()
 
-	rows, _ := result.RowsAffected()
+	for start := 0; start < len(upserts); start += productBatchSize {
+		end := min(start+productBatchSize, len(upserts))
+		batch := upserts[start:end]
 
-	fmt.Printf("Upsert success: %s | %s | %s (affected: %d)\n",
-		product.Service,
-		product.Country,
-		product.Operator,
-		rows,
-	)
+		placeholders := make([]string, 0, len(batch))
+		args := make([]any, 0, len(batch)*6)
+		for _, product := range batch {
+			placeholders = append(placeholders, "(?,?,?,?,?,1,?)")
+			args = append(args, product.Service, product.Country, product.Operator, product.PriceDefault, product.PriceSell, product.Stock)
+		}
+
+		query := `INSERT INTO product(service, country, operator, price_default, price_sell, available, stock) VALUES ` +
+			strings.Join(placeholders, ",") +
+			` ON DUPLICATE KEY UPDATE price_default = VALUES(price_default), price_sell = VALUES(price_sell), available = 1, stock = VALUES(stock)`
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return fmt.Errorf("failed to upsert product batch: %w", err)
+		}
+	}
+
+	for start := 0; start < len(unavailableIds); start += productBatchSize {
+		end := min(start+productBatchSize, len(unavailableIds))
+		batch := unavailableIds[start:end]
+
+		args := make([]any, 0, len(batch))
+		for _, id := range batch {
+			args = append(args, id)
+		}
+
+		query := "UPDATE product SET available = 0, stock = 0 WHERE id IN (?" + strings.Repeat(",?", len(batch)-1) + ")"
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return fmt.Errorf("failed to mark products unavailable: %w", err)
+		}
+	}
+
+	if err := insertPriceHistory(ctx, tx, history); err != nil {
+		return err
+	}
 
-	return err
+	return tx.Commit()
 }
 
 // FindByKey implements ProductRepository.
 func (p *ProductImplementation) FindByKey(ctx context.Context, service string, country string, operator string) (*models.SimProduct, error) {
-	query := `
-		SELECT service, country, operator, price_sell
-		FROM product
-		WHERE service = ? AND country = ? AND operator = ?
-	`
-	var product models.SimProduct
+	query := "SELECT " + productColumns + " FROM product WHERE service = ? AND country = ? AND operator = ?"
 
-	err := p.db.QueryRowContext(ctx, query, service, country, operator).Scan(
-		&product.Service,
-		&product.Country,
-		&product.Operator,
-		&product.PriceSell,
-	)
+	var product models.SimProduct
+	err := scanProduct(p.db.QueryRowContext(ctx, query, service, country, operator), &product)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
@@ -77,3 +108,24 @@ func (p *ProductImplementation) FindByKey(ctx context.Context, service string, c
 	}
 	return &product, nil
 }
+
+// FindAll implements ProductRepository.
+func (p *ProductImplementation) FindAll(ctx context.Context) ([]models.SimProduct, error) {
+	query := "SELECT " + productColumns + " FROM product"
+
+	rows, err := p.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var products []models.SimProduct
+	for rows.Next() {
+		var product models.SimProduct
+		if err := scanProduct(rows, &product); err != nil {
+			return nil, err
+		}
+		products = append(products, product)
+	}
+	return products, rows.Err()
+}
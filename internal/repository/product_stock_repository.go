@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/imnzr/sim-service-project/models"
+)
+
+type ProductStockRepository interface {
+	RecordSnapshots(ctx context.Context, snapshots []models.ProductStockSnapshot) error
+	UpdateStock(ctx context.Context, service, country, operator string, qty int) error
+	Trend(ctx context.Context, service, country, operator string, since time.Time) ([]models.AvailabilityPoint, error)
+	InStockRatio(ctx context.Context, service, country, operator string, since time.Time) (float64, error)
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+type ProductStockImplementation struct {
+	db *sql.DB
+}
+
+func NewProductStockRepository(db *sql.DB) ProductStockRepository {
+	return &ProductStockImplementation{
+		db: db,
+	}
+}
+
+// RecordSnapshots implements ProductStockRepository.
+func (p *ProductStockImplementation) RecordSnapshots(ctx context.Context, snapshots []models.ProductStockSnapshot) error {
+	for start := 0; start < len(snapshots); start += productBatchSize {
+		end := min(start+productBatchSize, len(snapshots))
+		batch := snapshots[start:end]
+
+		placeholders := make([]string, 0, len(batch))
+		args := make([]any, 0, len(batch)*6)
+		for _, snapshot := range batch {
+			placeholders = append(placeholders, "(?,?,?,?,?,?)")
+			args = append(args, snapshot.Service, snapshot.Country, snapshot.Operator, snapshot.Qty, snapshot.Source, snapshot.RecordedAt)
+		}
+
+		query := "INSERT INTO product_stock_snapshots(service, country, operator, qty, source, recorded_at) VALUES " +
+			strings.Join(placeholders, ",")
+		if _, err := p.db.ExecContext(ctx, query, args...); err != nil {
+			return fmt.Errorf("failed to insert stock snapshots: %w", err)
+		}
+	}
+	return nil
+}
+
+// UpdateStock implements ProductStockRepository.
+func (p *ProductStockImplementation) UpdateStock(ctx context.Context, service string, country string, operator string, qty int) error {
+	query := "UPDATE product SET stock = ? WHERE service = ? AND country = ? AND operator = ?"
+	_, err := p.db.ExecContext(ctx, query, qty, service, country, operator)
+	return err
+}
+
+// Trend implements ProductStockRepository.
+func (p *ProductStockImplementation) Trend(ctx context.Context, service string, country string, operator string, since time.Time) ([]models.AvailabilityPoint, error) {
+	query := `
+		SELECT DATE_FORMAT(recorded_at, '%Y-%m-%d %H:00:00') AS hour, MIN(qty), MAX(qty), AVG(qty), COUNT(*)
+		FROM product_stock_snapshots
+		WHERE service = ? AND country = ? AND operator = ? AND recorded_at >= ?
+		GROUP BY hour
+		ORDER BY hour ASC
+	`
+	rows, err := p.db.QueryContext(ctx, query, service, country, operator, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	points := []models.AvailabilityPoint{}
+	for rows.Next() {
+		var hour string
+		var point models.AvailabilityPoint
+		if err := rows.Scan(&hour, &point.MinQty, &point.MaxQty, &point.AvgQty, &point.Samples); err != nil {
+			return nil, err
+		}
+		point.Hour, err = time.ParseInLocation(time.DateTime, hour, time.Local)
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, point)
+	}
+	return points, rows.Err()
+}
+
+// InStockRatio implements ProductStockRepository.
+func (p *ProductStockImplementation) InStockRatio(ctx context.Context, service string, country string, operator string, since time.Time) (float64, error) {
+	query := `
+		SELECT COALESCE(AVG(qty > 0), 0)
+		FROM product_stock_snapshots
+		WHERE service = ? AND country = ? AND operator = ? AND recorded_at >= ?
+	`
+	var ratio float64
+	err := p.db.QueryRowContext(ctx, query, service, country, operator, since).Scan(&ratio)
+	return ratio, err
+}
+
+// DeleteBefore implements ProductStockRepository.
+func (p *ProductStockImplementation) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	result, err := p.db.ExecContext(ctx, "DELETE FROM product_stock_snapshots WHERE recorded_at < ?", before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
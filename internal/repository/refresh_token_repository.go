@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/imnzr/sim-service-project/models"
+)
+
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, token *models.RefreshToken) error
+	GetByHash(ctx context.Context, tokenHash string) (*models.RefreshToken, error)
+	MarkUsed(ctx context.Context, id int64) (bool, error)
+	RevokeFamily(ctx context.Context, familyId string) error
+	RevokeAllForUser(ctx context.Context, userId uint) error
+	RevokeAllForUserExcept(ctx context.Context, userId uint, familyId string) error
+}
+
+type RefreshTokenImplementation struct {
+	db *sql.DB
+}
+
+func NewRefreshTokenRepository(db *sql.DB) RefreshTokenRepository {
+	return &RefreshTokenImplementation{
+		db: db,
+	}
+}
+
+// Create implements RefreshTokenRepository.
+func (r *RefreshTokenImplementation) Create(ctx context.Context, token *models.RefreshToken) error {
+	query := "INSERT INTO refresh_tokens(user_id, family_id, token_hash, expires_at) VALUES(?,?,?,?)"
+
+	result, err := r.db.ExecContext(ctx, query, token.UserId, token.FamilyId, token.TokenHash, token.ExpiresAt)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	token.Id = id
+	return nil
+}
+
+// GetByHash implements RefreshTokenRepository.
+func (r *RefreshTokenImplementation) GetByHash(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	query := `
+		SELECT id, user_id, family_id, token_hash, expires_at, used_at, revoked_at, created_at
+		FROM refresh_tokens WHERE token_hash = ?
+	`
+	var token models.RefreshToken
+	err := r.db.QueryRowContext(ctx, query, tokenHash).Scan(
+		&token.Id,
+		&token.UserId,
+		&token.FamilyId,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&token.UsedAt,
+		&token.RevokedAt,
+		&token.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// MarkUsed implements RefreshTokenRepository.
+// Mengembalikan false jika token sudah dipakai/dicabut sebelumnya (update atomic).
+func (r *RefreshTokenImplementation) MarkUsed(ctx context.Context, id int64) (bool, error) {
+	query := "UPDATE refresh_tokens SET used_at = NOW() WHERE id = ? AND used_at IS NULL AND revoked_at IS NULL"
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows == 1, nil
+}
+
+// RevokeFamily implements RefreshTokenRepository.
+func (r *RefreshTokenImplementation) RevokeFamily(ctx context.Context, familyId string) error {
+	query := "UPDATE refresh_tokens SET revoked_at = NOW() WHERE family_id = ? AND revoked_at IS NULL"
+	_, err := r.db.ExecContext(ctx, query, familyId)
+	return err
+}
+
+// RevokeAllForUser implements RefreshTokenRepository.
+func (r *RefreshTokenImplementation) RevokeAllForUser(ctx context.Context, userId uint) error {
+	query := "UPDATE refresh_tokens SET revoked_at = NOW() WHERE user_id = ? AND revoked_at IS NULL"
+	_, err := r.db.ExecContext(ctx, query, userId)
+	return err
+}
+
+// RevokeAllForUserExcept implements RefreshTokenRepository.
+func (r *RefreshTokenImplementation) RevokeAllForUserExcept(ctx context.Context, userId uint, familyId string) error {
+	query := "UPDATE refresh_tokens SET revoked_at = NOW() WHERE user_id = ? AND family_id <> ? AND revoked_at IS NULL"
+	_, err := r.db.ExecContext(ctx, query, userId, familyId)
+	return err
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/imnzr/sim-service-project/models"
+)
+
+type SyncRunRepository interface {
+	Start(ctx context.Context, run *models.SyncRun) error
+	Finish(ctx context.Context, run *models.SyncRun) error
+	GetLatest(ctx context.Context) (*models.SyncRun, error)
+}
+
+type SyncRunImplementation struct {
+	db *sql.DB
+}
+
+func NewSyncRunRepository(db *sql.DB) SyncRunRepository {
+	return &SyncRunImplementation{
+		db: db,
+	}
+}
+
+// Start implements SyncRunRepository.
+func (s *SyncRunImplementation) Start(ctx context.Context, run *models.SyncRun) error {
+	query := "INSERT INTO sync_runs(trigger_type, status, started_at) VALUES(?,?,?)"
+
+	result, err := s.db.ExecContext(ctx, query, run.Trigger, run.Status, run.StartedAt)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	run.Id = int(id)
+	return nil
+}
+
+// Finish implements SyncRunRepository.
+func (s *SyncRunImplementation) Finish(ctx context.Context, run *models.SyncRun) error {
+	query := `
+		UPDATE sync_runs
+		SET status = ?, added = ?, updated = ?, removed = ?, unchanged = ?, errors = ?, error_message = ?, finished_at = ?
+		WHERE id = ?
+	`
+	_, err := s.db.ExecContext(ctx, query,
+		run.Status,
+		run.Added,
+		run.Updated,
+		run.Removed,
+		run.Unchanged,
+		run.Errors,
+		run.ErrorMessage,
+		run.FinishedAt,
+		run.Id,
+	)
+	return err
+}
+
+// GetLatest implements SyncRunRepository.
+func (s *SyncRunImplementation) GetLatest(ctx context.Context) (*models.SyncRun, error) {
+	query := `
+		SELECT id, trigger_type, status, added, updated, removed, unchanged, errors, error_message, started_at, finished_at
+		FROM sync_runs ORDER BY started_at DESC, id DESC LIMIT 1
+	`
+	var run models.SyncRun
+	err := s.db.QueryRowContext(ctx, query).Scan(
+		&run.Id,
+		&run.Trigger,
+		&run.Status,
+		&run.Added,
+		&run.Updated,
+		&run.Removed,
+		&run.Unchanged,
+		&run.Errors,
+		&run.ErrorMessage,
+		&run.StartedAt,
+		&run.FinishedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &run, nil
+}
@@ -3,8 +3,11 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
+	"strings"
+	"time"
 
 	"github.com/imnzr/sim-service-project/models"
 )
@@ -13,8 +16,19 @@ type UserRepository interface {
 	CreateUser(ctx context.Context, user *models.User) error
 	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
 	GetUserById(ctx context.Context, id uint) (*models.User, error)
-	// UpdateUserEmail()
-	// UpdateUserUsername()
+	SetPasswordResetToken(ctx context.Context, userId uint, tokenHash string, expiresAt time.Time) error
+	GetUserByPasswordResetToken(ctx context.Context, tokenHash string) (*models.User, error)
+	ResetPassword(ctx context.Context, userId uint, tokenHash string, hashedPassword string) (bool, error)
+	SetEmailVerificationToken(ctx context.Context, userId uint, tokenHash string, expiresAt time.Time) error
+	GetUserByEmailVerificationToken(ctx context.Context, tokenHash string) (*models.User, error)
+	VerifyEmail(ctx context.Context, tokenHash string) (bool, error)
+	UpdateUserUsername(ctx context.Context, userId uint, username string) error
+	SetPendingEmail(ctx context.Context, userId uint, email string) error
+	UpdatePassword(ctx context.Context, userId uint, hashedPassword string) error
+	SoftDeleteUser(ctx context.Context, userId uint) error
+	SetSuspended(ctx context.Context, userId uint, suspended bool, reason string) (bool, error)
+	Search(ctx context.Context, query models.AdminUserQuery) ([]models.User, int, error)
+	SetPriceTier(ctx context.Context, userId uint, tierId *int) error
 }
 
 type UserRepositoryImplementation struct {
@@ -27,9 +41,27 @@ func NewUserRepository(db *sql.DB) UserRepository {
 	}
 }
 
+const userColumns = "id, username, email, password, role, email_verified_at, pending_email, balance, suspended_at, suspended_reason, price_tier_id"
+
+func scanUser(row rowScanner, user *models.User) error {
+	return row.Scan(
+		&user.ID,
+		&user.Username,
+		&user.Email,
+		&user.Password,
+		&user.Role,
+		&user.EmailVerifiedAt,
+		&user.PendingEmail,
+		&user.Balance,
+		&user.SuspendedAt,
+		&user.SuspendedReason,
+		&user.PriceTierId,
+	)
+}
+
 // GetUserById implements UserRepository.
 func (u *UserRepositoryImplementation) GetUserById(ctx context.Context, id uint) (*models.User, error) {
-	query := "SELECT id, username, email, password FROM `users` WHERE id = ?"
+	query := "SELECT " + userColumns + " FROM `users` WHERE id = ? AND deleted_at IS NULL"
 
 	rows, err := u.db.QueryContext(ctx, query, id)
 	if err != nil {
@@ -40,13 +72,7 @@ func (u *UserRepositoryImplementation) GetUserById(ctx context.Context, id uint)
 
 	if rows.Next() {
 		user := &models.User{}
-		err := rows.Scan(
-			&user.ID,
-			&user.Username,
-			&user.Email,
-			&user.Password,
-		)
-		if err != nil {
+		if err := scanUser(rows, user); err != nil {
 			log.Printf("failed to scan user row: %v", err)
 			return nil, fmt.Errorf("failed scan user")
 		}
@@ -57,7 +83,7 @@ func (u *UserRepositoryImplementation) GetUserById(ctx context.Context, id uint)
 
 // GetUserByEmail implements UserRepository.
 func (u *UserRepositoryImplementation) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
-	query := "SELECT id, username, email, password FROM `users` WHERE email = ?"
+	query := "SELECT " + userColumns + " FROM `users` WHERE email = ? AND deleted_at IS NULL"
 
 	rows, err := u.db.QueryContext(ctx, query, email)
 	if err != nil {
@@ -68,13 +94,7 @@ func (u *UserRepositoryImplementation) GetUserByEmail(ctx context.Context, email
 
 	if rows.Next() {
 		user := &models.User{}
-		err := rows.Scan(
-			&user.ID,
-			&user.Username,
-			&user.Email,
-			&user.Password,
-		)
-		if err != nil {
+		if err := scanUser(rows, user); err != nil {
 			log.Printf("failed to scan user row: %v", err)
 			return nil, fmt.Errorf("failed scan user")
 		}
@@ -85,10 +105,13 @@ func (u *UserRepositoryImplementation) GetUserByEmail(ctx context.Context, email
 
 // CreateUser implements UserRepository.
 func (u *UserRepositoryImplementation) CreateUser(ctx context.Context, user *models.User) error {
-	query := "INSERT INTO users(username, email, password) VALUES(?,?,?)"
+	query := "INSERT INTO users(username, email, password, role) VALUES(?,?,?,?)"
 
-	result, err := u.db.ExecContext(ctx, query, user.Username, user.Email, user.Password)
+	result, err := u.db.ExecContext(ctx, query, user.Username, user.Email, user.Password, user.Role)
 	if err != nil {
+		if isDuplicateEntry(err) {
+			return ErrDuplicateEntry
+		}
 		log.Println("failed to execute query create user :%w", err)
 		return fmt.Errorf("failed to create user")
 	}
@@ -101,3 +124,256 @@ func (u *UserRepositoryImplementation) CreateUser(ctx context.Context, user *mod
 	user.ID = uint(LastInsertID)
 	return nil
 }
+
+// SetPasswordResetToken implements UserRepository.
+// Token sebelumnya otomatis tidak berlaku karena hash-nya ditimpa.
+func (u *UserRepositoryImplementation) SetPasswordResetToken(ctx context.Context, userId uint, tokenHash string, expiresAt time.Time) error {
+	query := "UPDATE users SET password_reset_token_hash = ?, password_reset_expires_at = ? WHERE id = ?"
+
+	_, err := u.db.ExecContext(ctx, query, tokenHash, expiresAt, userId)
+	if err != nil {
+		log.Printf("failed to execute query set password reset token: %v", err)
+		return fmt.Errorf("failed to set password reset token")
+	}
+	return nil
+}
+
+// GetUserByPasswordResetToken implements UserRepository.
+func (u *UserRepositoryImplementation) GetUserByPasswordResetToken(ctx context.Context, tokenHash string) (*models.User, error) {
+	query := "SELECT id, username, email, password, password_reset_token_hash, password_reset_expires_at FROM `users` WHERE password_reset_token_hash = ?"
+
+	user := &models.User{}
+	err := u.db.QueryRowContext(ctx, query, tokenHash).Scan(
+		&user.ID,
+		&user.Username,
+		&user.Email,
+		&user.Password,
+		&user.PasswordResetTokenHash,
+		&user.PasswordResetExpiresAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		log.Printf("failed to execute query get user by password reset token: %v", err)
+		return nil, fmt.Errorf("failed to get user by password reset token")
+	}
+	return user, nil
+}
+
+// ResetPassword implements UserRepository.
+// Password diganti dan token dihapus dalam satu update, sehingga token hanya bisa dipakai sekali.
+// Mengembalikan false jika token sudah dipakai atau kedaluwarsa.
+func (u *UserRepositoryImplementation) ResetPassword(ctx context.Context, userId uint, tokenHash string, hashedPassword string) (bool, error) {
+	query := `
+		UPDATE users SET password = ?, password_reset_token_hash = NULL, password_reset_expires_at = NULL
+		WHERE id = ? AND password_reset_token_hash = ? AND password_reset_expires_at > NOW()
+	`
+	result, err := u.db.ExecContext(ctx, query, hashedPassword, userId, tokenHash)
+	if err != nil {
+		log.Printf("failed to execute query reset password: %v", err)
+		return false, fmt.Errorf("failed to reset password")
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows == 1, nil
+}
+
+// SetEmailVerificationToken implements UserRepository.
+func (u *UserRepositoryImplementation) SetEmailVerificationToken(ctx context.Context, userId uint, tokenHash string, expiresAt time.Time) error {
+	query := "UPDATE users SET email_verification_token_hash = ?, email_verification_expires_at = ? WHERE id = ?"
+
+	_, err := u.db.ExecContext(ctx, query, tokenHash, expiresAt, userId)
+	if err != nil {
+		log.Printf("failed to execute query set email verification token: %v", err)
+		return fmt.Errorf("failed to set email verification token")
+	}
+	return nil
+}
+
+// GetUserByEmailVerificationToken implements UserRepository.
+func (u *UserRepositoryImplementation) GetUserByEmailVerificationToken(ctx context.Context, tokenHash string) (*models.User, error) {
+	query := "SELECT " + userColumns + " FROM `users` WHERE email_verification_token_hash = ? AND deleted_at IS NULL"
+
+	user := &models.User{}
+	if err := scanUser(u.db.QueryRowContext(ctx, query, tokenHash), user); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		log.Printf("failed to execute query get user by email verification token: %v", err)
+		return nil, fmt.Errorf("failed to get user by email verification token")
+	}
+	return user, nil
+}
+
+// VerifyEmail implements UserRepository.
+// Jika ada pending_email, email tersebut menggantikan email lama.
+// Mengembalikan false jika token tidak ditemukan, sudah dipakai atau kedaluwarsa.
+func (u *UserRepositoryImplementation) VerifyEmail(ctx context.Context, tokenHash string) (bool, error) {
+	query := `
+		UPDATE users SET email = COALESCE(pending_email, email), pending_email = NULL,
+			email_verified_at = NOW(), email_verification_token_hash = NULL, email_verification_expires_at = NULL
+		WHERE email_verification_token_hash = ? AND email_verification_expires_at > NOW() AND deleted_at IS NULL
+	`
+	result, err := u.db.ExecContext(ctx, query, tokenHash)
+	if err != nil {
+		if isDuplicateEntry(err) {
+			return false, ErrDuplicateEntry
+		}
+		log.Printf("failed to execute query verify email: %v", err)
+		return false, fmt.Errorf("failed to verify email")
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows == 1, nil
+}
+
+// UpdateUserUsername implements UserRepository.
+func (u *UserRepositoryImplementation) UpdateUserUsername(ctx context.Context, userId uint, username string) error {
+	query := "UPDATE users SET username = ? WHERE id = ? AND deleted_at IS NULL"
+
+	_, err := u.db.ExecContext(ctx, query, username, userId)
+	if err != nil {
+		log.Printf("failed to execute query update username: %v", err)
+		return fmt.Errorf("failed to update username")
+	}
+	return nil
+}
+
+// SetPendingEmail implements UserRepository.
+func (u *UserRepositoryImplementation) SetPendingEmail(ctx context.Context, userId uint, email string) error {
+	query := "UPDATE users SET pending_email = ? WHERE id = ? AND deleted_at IS NULL"
+
+	_, err := u.db.ExecContext(ctx, query, email, userId)
+	if err != nil {
+		log.Printf("failed to execute query set pending email: %v", err)
+		return fmt.Errorf("failed to set pending email")
+	}
+	return nil
+}
+
+// UpdatePassword implements UserRepository.
+// Token reset password yang masih aktif ikut dihapus.
+func (u *UserRepositoryImplementation) UpdatePassword(ctx context.Context, userId uint, hashedPassword string) error {
+	query := `
+		UPDATE users SET password = ?, password_reset_token_hash = NULL, password_reset_expires_at = NULL
+		WHERE id = ? AND deleted_at IS NULL
+	`
+	_, err := u.db.ExecContext(ctx, query, hashedPassword, userId)
+	if err != nil {
+		log.Printf("failed to execute query update password: %v", err)
+		return fmt.Errorf("failed to update password")
+	}
+	return nil
+}
+
+// SoftDeleteUser implements UserRepository.
+// Data pribadi dianonimkan tetapi baris user tetap ada agar sim_orders masih punya referensi.
+// Password dikosongkan sehingga bcrypt tidak akan pernah cocok.
+func (u *UserRepositoryImplementation) SoftDeleteUser(ctx context.Context, userId uint) error {
+	query := `
+		UPDATE users SET
+			username = CONCAT('deleted_', id),
+			email = CONCAT('deleted+', id, '@invalid.local'),
+			password = '',
+			pending_email = NULL,
+			password_reset_token_hash = NULL,
+			password_reset_expires_at = NULL,
+			email_verification_token_hash = NULL,
+			email_verification_expires_at = NULL,
+			deleted_at = NOW()
+		WHERE id = ? AND deleted_at IS NULL
+	`
+	_, err := u.db.ExecContext(ctx, query, userId)
+	if err != nil {
+		log.Printf("failed to execute query soft delete user: %v", err)
+		return fmt.Errorf("failed to delete user")
+	}
+	return nil
+}
+
+// SetSuspended implements UserRepository.
+// Mengembalikan false jika status suspend user sudah sama (tidak ada perubahan).
+func (u *UserRepositoryImplementation) SetSuspended(ctx context.Context, userId uint, suspended bool, reason string) (bool, error) {
+	query := "UPDATE users SET suspended_at = NOW(), suspended_reason = ? WHERE id = ? AND suspended_at IS NULL AND deleted_at IS NULL"
+	args := []any{reason, userId}
+	if !suspended {
+		query = "UPDATE users SET suspended_at = NULL, suspended_reason = NULL WHERE id = ? AND suspended_at IS NOT NULL AND deleted_at IS NULL"
+		args = []any{userId}
+	}
+
+	result, err := u.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("failed to execute query set suspended: %v", err)
+		return false, fmt.Errorf("failed to update user suspension")
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows == 1, nil
+}
+
+// Search implements UserRepository.
+func (u *UserRepositoryImplementation) Search(ctx context.Context, query models.AdminUserQuery) ([]models.User, int, error) {
+	conditions := []string{"deleted_at IS NULL"}
+	args := []any{}
+	if query.Search != "" {
+		pattern := likePattern(query.Search)
+		conditions = append(conditions, "(email LIKE ? OR username LIKE ?)")
+		args = append(args, pattern, pattern)
+	}
+	if query.Role != "" {
+		conditions = append(conditions, "role = ?")
+		args = append(args, query.Role)
+	}
+	if query.Suspended != nil {
+		if *query.Suspended {
+			conditions = append(conditions, "suspended_at IS NOT NULL")
+		} else {
+			conditions = append(conditions, "suspended_at IS NULL")
+		}
+	}
+	from := "FROM `users` WHERE " + strings.Join(conditions, " AND ")
+
+	var total int
+	if err := u.db.QueryRowContext(ctx, "SELECT COUNT(*) "+from, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := u.db.QueryContext(ctx,
+		"SELECT "+userColumns+" "+from+" ORDER BY id DESC LIMIT ? OFFSET ?",
+		append(args, query.Limit, (query.Page-1)*query.Limit)...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	users := []models.User{}
+	for rows.Next() {
+		var user models.User
+		if err := scanUser(rows, &user); err != nil {
+			return nil, 0, err
+		}
+		users = append(users, user)
+	}
+	return users, total, rows.Err()
+}
+
+// SetPriceTier implements UserRepository.
+// tierId nil berarti user kembali ke harga retail.
+func (u *UserRepositoryImplementation) SetPriceTier(ctx context.Context, userId uint, tierId *int) error {
+	query := "UPDATE users SET price_tier_id = ? WHERE id = ? AND deleted_at IS NULL"
+
+	_, err := u.db.ExecContext(ctx, query, tierId, userId)
+	if err != nil {
+		log.Printf("failed to execute query set price tier: %v", err)
+		return fmt.Errorf("failed to update price tier")
+	}
+	return nil
+}
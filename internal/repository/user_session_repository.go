@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/imnzr/sim-service-project/models"
+)
+
+type UserSessionRepository interface {
+	Create(ctx context.Context, session *models.UserSession) error
+	Touch(ctx context.Context, familyId string, client models.ClientInfo) error
+	Revoke(ctx context.Context, familyId string) error
+	RevokeAllForUser(ctx context.Context, userId uint) error
+	RevokeAllForUserExcept(ctx context.Context, userId uint, familyId string) error
+	FindActiveByUser(ctx context.Context, userId uint) ([]models.UserSession, error)
+	IsRevoked(ctx context.Context, familyId string) (bool, error)
+}
+
+type UserSessionImplementation struct {
+	db *sql.DB
+}
+
+func NewUserSessionRepository(db *sql.DB) UserSessionRepository {
+	return &UserSessionImplementation{
+		db: db,
+	}
+}
+
+// Create implements UserSessionRepository.
+func (r *UserSessionImplementation) Create(ctx context.Context, session *models.UserSession) error {
+	query := "INSERT INTO user_sessions(user_id, family_id, user_agent, ip_address) VALUES(?,?,?,?)"
+
+	result, err := r.db.ExecContext(ctx, query, session.UserId, session.FamilyId, session.UserAgent, session.IPAddress)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	session.Id = id
+	return nil
+}
+
+// Touch implements UserSessionRepository.
+// Dipanggil saat refresh token dirotasi untuk memperbarui waktu dan perangkat terakhir.
+func (r *UserSessionImplementation) Touch(ctx context.Context, familyId string, client models.ClientInfo) error {
+	query := "UPDATE user_sessions SET last_used_at = NOW(), user_agent = ?, ip_address = ? WHERE family_id = ? AND revoked_at IS NULL"
+	_, err := r.db.ExecContext(ctx, query, client.UserAgent, client.IPAddress, familyId)
+	return err
+}
+
+// Revoke implements UserSessionRepository.
+func (r *UserSessionImplementation) Revoke(ctx context.Context, familyId string) error {
+	query := "UPDATE user_sessions SET revoked_at = NOW() WHERE family_id = ? AND revoked_at IS NULL"
+	_, err := r.db.ExecContext(ctx, query, familyId)
+	return err
+}
+
+// RevokeAllForUser implements UserSessionRepository.
+func (r *UserSessionImplementation) RevokeAllForUser(ctx context.Context, userId uint) error {
+	query := "UPDATE user_sessions SET revoked_at = NOW() WHERE user_id = ? AND revoked_at IS NULL"
+	_, err := r.db.ExecContext(ctx, query, userId)
+	return err
+}
+
+// RevokeAllForUserExcept implements UserSessionRepository.
+func (r *UserSessionImplementation) RevokeAllForUserExcept(ctx context.Context, userId uint, familyId string) error {
+	query := "UPDATE user_sessions SET revoked_at = NOW() WHERE user_id = ? AND family_id <> ? AND revoked_at IS NULL"
+	_, err := r.db.ExecContext(ctx, query, userId, familyId)
+	return err
+}
+
+// IsRevoked implements UserSessionRepository.
+// Sesi yang tidak ditemukan dianggap sudah dicabut.
+func (r *UserSessionImplementation) IsRevoked(ctx context.Context, familyId string) (bool, error) {
+	query := "SELECT revoked_at IS NOT NULL FROM user_sessions WHERE family_id = ?"
+
+	var revoked bool
+	err := r.db.QueryRowContext(ctx, query, familyId).Scan(&revoked)
+	if errors.Is(err, sql.ErrNoRows) {
+		return true, nil
+	}
+	return revoked, err
+}
+
+// FindActiveByUser implements UserSessionRepository.
+// Sesi yang semua refresh token-nya sudah kedaluwarsa tidak ditampilkan.
+func (r *UserSessionImplementation) FindActiveByUser(ctx context.Context, userId uint) ([]models.UserSession, error) {
+	query := `
+		SELECT s.id, s.user_id, s.family_id, s.user_agent, s.ip_address, s.created_at, s.last_used_at, s.revoked_at
+		FROM user_sessions s
+		WHERE s.user_id = ? AND s.revoked_at IS NULL
+			AND EXISTS (
+				SELECT 1 FROM refresh_tokens t
+				WHERE t.family_id = s.family_id AND t.used_at IS NULL AND t.revoked_at IS NULL AND t.expires_at > NOW()
+			)
+		ORDER BY s.last_used_at DESC
+	`
+	rows, err := r.db.QueryContext(ctx, query, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []models.UserSession{}
+	for rows.Next() {
+		var session models.UserSession
+		err := rows.Scan(
+			&session.Id,
+			&session.UserId,
+			&session.FamilyId,
+			&session.UserAgent,
+			&session.IPAddress,
+			&session.CreatedAt,
+			&session.LastUsedAt,
+			&session.RevokedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, rows.Err()
+}
@@ -0,0 +1,243 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/imnzr/sim-service-project/models"
+)
+
+type WebhookRepository interface {
+	CreateEndpoint(ctx context.Context, endpoint *models.WebhookEndpoint) error
+	GetEndpoint(ctx context.Context, id int64) (*models.WebhookEndpoint, error)
+	FindEndpointsByUser(ctx context.Context, userId uint) ([]models.WebhookEndpoint, error)
+	CountEndpointsByUser(ctx context.Context, userId uint) (int, error)
+	DeleteEndpoint(ctx context.Context, userId uint, id int64) (bool, error)
+	CreateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error
+	ClaimDelivery(ctx context.Context, id int64, leaseUntil time.Time) (bool, error)
+	SaveAttempt(ctx context.Context, delivery *models.WebhookDelivery) error
+	FindDueDeliveries(ctx context.Context, limit int) ([]models.WebhookDelivery, error)
+	FindDeliveries(ctx context.Context, endpointId int64, page, limit int) ([]models.WebhookDelivery, int, error)
+}
+
+type WebhookImplementation struct {
+	db *sql.DB
+}
+
+func NewWebhookRepository(db *sql.DB) WebhookRepository {
+	return &WebhookImplementation{
+		db: db,
+	}
+}
+
+const webhookEndpointColumns = "id, user_id, url, description, secret, events, created_at, updated_at"
+
+const webhookDeliveryColumns = `id, endpoint_id, event_id, event_type, payload, status, attempts, next_attempt_at,
+	last_status_code, last_error, delivered_at, created_at, updated_at`
+
+// event disimpan sebagai string dipisah koma, kosong berarti semua event
+func scanWebhookEndpoint(row rowScanner, endpoint *models.WebhookEndpoint) error {
+	var events string
+	err := row.Scan(
+		&endpoint.Id,
+		&endpoint.UserId,
+		&endpoint.URL,
+		&endpoint.Description,
+		&endpoint.Secret,
+		&events,
+		&endpoint.CreatedAt,
+		&endpoint.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	endpoint.Events = []string{}
+	if events != "" {
+		endpoint.Events = strings.Split(events, ",")
+	}
+	return nil
+}
+
+func scanWebhookDelivery(row rowScanner, delivery *models.WebhookDelivery) error {
+	return row.Scan(
+		&delivery.Id,
+		&delivery.EndpointId,
+		&delivery.EventId,
+		&delivery.EventType,
+		&delivery.Payload,
+		&delivery.Status,
+		&delivery.Attempts,
+		&delivery.NextAttemptAt,
+		&delivery.LastStatusCode,
+		&delivery.LastError,
+		&delivery.DeliveredAt,
+		&delivery.CreatedAt,
+		&delivery.UpdatedAt,
+	)
+}
+
+func (w *WebhookImplementation) queryDeliveries(ctx context.Context, query string, args ...any) ([]models.WebhookDelivery, error) {
+	rows, err := w.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []models.WebhookDelivery{}
+	for rows.Next() {
+		var delivery models.WebhookDelivery
+		if err := scanWebhookDelivery(rows, &delivery); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+// CreateEndpoint implements WebhookRepository.
+func (w *WebhookImplementation) CreateEndpoint(ctx context.Context, endpoint *models.WebhookEndpoint) error {
+	query := "INSERT INTO webhook_endpoints(user_id, url, description, secret, events) VALUES(?,?,?,?,?)"
+
+	result, err := w.db.ExecContext(ctx, query, endpoint.UserId, endpoint.URL, endpoint.Description, endpoint.Secret, strings.Join(endpoint.Events, ","))
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	endpoint.Id = id
+	return nil
+}
+
+// GetEndpoint implements WebhookRepository.
+// Kepemilikan endpoint dicek di service.
+func (w *WebhookImplementation) GetEndpoint(ctx context.Context, id int64) (*models.WebhookEndpoint, error) {
+	row := w.db.QueryRowContext(ctx, "SELECT "+webhookEndpointColumns+" FROM webhook_endpoints WHERE id = ?", id)
+
+	var endpoint models.WebhookEndpoint
+	if err := scanWebhookEndpoint(row, &endpoint); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &endpoint, nil
+}
+
+// FindEndpointsByUser implements WebhookRepository.
+func (w *WebhookImplementation) FindEndpointsByUser(ctx context.Context, userId uint) ([]models.WebhookEndpoint, error) {
+	query := "SELECT " + webhookEndpointColumns + " FROM webhook_endpoints WHERE user_id = ? ORDER BY id ASC"
+
+	rows, err := w.db.QueryContext(ctx, query, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	endpoints := []models.WebhookEndpoint{}
+	for rows.Next() {
+		var endpoint models.WebhookEndpoint
+		if err := scanWebhookEndpoint(rows, &endpoint); err != nil {
+			return nil, err
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+	return endpoints, rows.Err()
+}
+
+// CountEndpointsByUser implements WebhookRepository.
+func (w *WebhookImplementation) CountEndpointsByUser(ctx context.Context, userId uint) (int, error) {
+	var count int
+	err := w.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM webhook_endpoints WHERE user_id = ?", userId).Scan(&count)
+	return count, err
+}
+
+// DeleteEndpoint implements WebhookRepository.
+// Log pengiriman ikut terhapus (ON DELETE CASCADE).
+func (w *WebhookImplementation) DeleteEndpoint(ctx context.Context, userId uint, id int64) (bool, error) {
+	result, err := w.db.ExecContext(ctx, "DELETE FROM webhook_endpoints WHERE id = ? AND user_id = ?", id, userId)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// CreateDelivery implements WebhookRepository.
+func (w *WebhookImplementation) CreateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	query := "INSERT INTO webhook_deliveries(endpoint_id, event_id, event_type, payload, status, next_attempt_at) VALUES(?,?,?,?,?,?)"
+
+	result, err := w.db.ExecContext(ctx, query, delivery.EndpointId, delivery.EventId, delivery.EventType, delivery.Payload, delivery.Status, delivery.NextAttemptAt)
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	delivery.Id = id
+	return nil
+}
+
+// ClaimDelivery implements WebhookRepository.
+// next_attempt_at dimajukan sampai leaseUntil agar delivery yang sama tidak dikirim
+// dua kali oleh scheduler di instance lain. false berarti sudah diambil proses lain.
+func (w *WebhookImplementation) ClaimDelivery(ctx context.Context, id int64, leaseUntil time.Time) (bool, error) {
+	query := `
+		UPDATE webhook_deliveries SET next_attempt_at = ?, updated_at = NOW()
+		WHERE id = ? AND status = ? AND next_attempt_at <= NOW()
+	`
+	result, err := w.db.ExecContext(ctx, query, leaseUntil, id, models.WebhookDeliveryPending)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// SaveAttempt implements WebhookRepository.
+func (w *WebhookImplementation) SaveAttempt(ctx context.Context, delivery *models.WebhookDelivery) error {
+	query := `
+		UPDATE webhook_deliveries SET status = ?, attempts = ?, next_attempt_at = ?, last_status_code = ?,
+			last_error = ?, delivered_at = ?, updated_at = NOW()
+		WHERE id = ?
+	`
+	_, err := w.db.ExecContext(ctx, query,
+		delivery.Status,
+		delivery.Attempts,
+		delivery.NextAttemptAt,
+		delivery.LastStatusCode,
+		delivery.LastError,
+		delivery.DeliveredAt,
+		delivery.Id,
+	)
+	return err
+}
+
+// FindDueDeliveries implements WebhookRepository.
+func (w *WebhookImplementation) FindDueDeliveries(ctx context.Context, limit int) ([]models.WebhookDelivery, error) {
+	query := "SELECT " + webhookDeliveryColumns + ` FROM webhook_deliveries
+		WHERE status = ? AND next_attempt_at <= NOW()
+		ORDER BY next_attempt_at ASC LIMIT ?`
+	return w.queryDeliveries(ctx, query, models.WebhookDeliveryPending, limit)
+}
+
+// FindDeliveries implements WebhookRepository.
+func (w *WebhookImplementation) FindDeliveries(ctx context.Context, endpointId int64, page int, limit int) ([]models.WebhookDelivery, int, error) {
+	var total int
+	if err := w.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM webhook_deliveries WHERE endpoint_id = ?", endpointId).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := "SELECT " + webhookDeliveryColumns + ` FROM webhook_deliveries
+		WHERE endpoint_id = ? ORDER BY id DESC LIMIT ? OFFSET ?`
+	deliveries, err := w.queryDeliveries(ctx, query, endpointId, limit, (page-1)*limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	return deliveries, total, nil
+}
@@ -0,0 +1,58 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/imnzr/sim-service-project/internal/service"
+)
+
+// CatalogSyncScheduler menjalankan sinkronisasi katalog secara berkala di dalam proses
+type CatalogSyncScheduler struct {
+	syncService service.CatalogSyncService
+	interval    time.Duration
+}
+
+func NewCatalogSyncScheduler(syncService service.CatalogSyncService, interval time.Duration) *CatalogSyncScheduler {
+	return &CatalogSyncScheduler{
+		syncService: syncService,
+		interval:    interval,
+	}
+}
+
+// Start berjalan sampai ctx dibatalkan. Interval <= 0 berarti scheduler dimatikan.
+func (s *CatalogSyncScheduler) Start(ctx context.Context) {
+	if s.interval <= 0 {
+		log.Println("catalog sync scheduler disabled")
+		return
+	}
+
+	log.Printf("catalog sync scheduler started, interval %s", s.interval)
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("catalog sync scheduler stopped")
+			return
+		case <-ticker.C:
+			s.runOnce(ctx)
+		}
+	}
+}
+
+func (s *CatalogSyncScheduler) runOnce(ctx context.Context) {
+	run, err := s.syncService.Run(ctx, service.SyncTriggerScheduled)
+	if err != nil {
+		if errors.Is(err, service.ErrSyncInProgress) {
+			log.Println("scheduled catalog sync skipped: another instance is syncing")
+			return
+		}
+		log.Printf("scheduled catalog sync failed: %v", err)
+		return
+	}
+	log.Printf("scheduled catalog sync %d finished with status %s", run.Id, run.Status)
+}
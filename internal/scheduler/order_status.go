@@ -0,0 +1,82 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/imnzr/sim-service-project/internal/lock"
+	"github.com/imnzr/sim-service-project/internal/service"
+)
+
+const orderStatusLockKey = "lock:order_status_sync"
+
+// OrderStatusScheduler mengecek order yang masih aktif ke 5sim secara berkala agar SMS yang masuk
+// dan pembatalan dari 5sim tercatat dan dikirim ke webhook reseller tanpa recheck manual.
+// Redis lock memastikan hanya satu instance yang melakukan polling di setiap putaran.
+type OrderStatusScheduler struct {
+	orderService service.OrderService
+	lock         *lock.RedisLock
+	interval     time.Duration
+	lockTTL      time.Duration
+}
+
+func NewOrderStatusScheduler(orderService service.OrderService, redisLock *lock.RedisLock, interval, lockTTL time.Duration) *OrderStatusScheduler {
+	return &OrderStatusScheduler{
+		orderService: orderService,
+		lock:         redisLock,
+		interval:     interval,
+		lockTTL:      lockTTL,
+	}
+}
+
+// Start berjalan sampai ctx dibatalkan. Interval <= 0 berarti recheck berkala dimatikan.
+func (s *OrderStatusScheduler) Start(ctx context.Context) {
+	if s.interval <= 0 {
+		log.Println("order status scheduler disabled")
+		return
+	}
+
+	log.Printf("order status scheduler started, interval %s", s.interval)
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("order status scheduler stopped")
+			return
+		case <-ticker.C:
+			s.runOnce(ctx)
+		}
+	}
+}
+
+func (s *OrderStatusScheduler) runOnce(ctx context.Context) {
+	token, err := s.lock.Acquire(ctx, orderStatusLockKey, s.lockTTL)
+	if err != nil {
+		if !errors.Is(err, lock.ErrNotAcquired) {
+			log.Printf("failed to acquire order status lock: %v", err)
+		}
+		return
+	}
+	defer func() {
+		if err := s.lock.Release(context.Background(), orderStatusLockKey, token); err != nil {
+			log.Printf("failed to release order status lock: %v", err)
+		}
+	}()
+
+	// putaran tidak boleh berjalan lebih lama dari TTL lock
+	ctx, cancel := context.WithTimeout(ctx, s.lockTTL)
+	defer cancel()
+
+	checked, err := s.orderService.RecheckActiveOrders(ctx)
+	if err != nil {
+		log.Printf("order status run failed: %v", err)
+		return
+	}
+	if checked > 0 {
+		log.Printf("order status run re-checked %d order(s)", checked)
+	}
+}
@@ -0,0 +1,55 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/imnzr/sim-service-project/internal/service"
+)
+
+// WebhookDeliveryScheduler mengirim ulang webhook yang gagal sesuai jadwal backoff-nya
+type WebhookDeliveryScheduler struct {
+	webhookService service.WebhookService
+	interval       time.Duration
+}
+
+func NewWebhookDeliveryScheduler(webhookService service.WebhookService, interval time.Duration) *WebhookDeliveryScheduler {
+	return &WebhookDeliveryScheduler{
+		webhookService: webhookService,
+		interval:       interval,
+	}
+}
+
+// Start berjalan sampai ctx dibatalkan. Interval <= 0 berarti retry webhook dimatikan.
+func (s *WebhookDeliveryScheduler) Start(ctx context.Context) {
+	if s.interval <= 0 {
+		log.Println("webhook delivery scheduler disabled")
+		return
+	}
+
+	log.Printf("webhook delivery scheduler started, interval %s", s.interval)
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("webhook delivery scheduler stopped")
+			return
+		case <-ticker.C:
+			s.runOnce(ctx)
+		}
+	}
+}
+
+func (s *WebhookDeliveryScheduler) runOnce(ctx context.Context) {
+	processed, err := s.webhookService.ProcessDue(ctx)
+	if err != nil {
+		log.Printf("webhook retry run failed: %v", err)
+		return
+	}
+	if processed > 0 {
+		log.Printf("webhook retry run delivered %d webhook(s)", processed)
+	}
+}
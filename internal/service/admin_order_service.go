@@ -0,0 +1,346 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/imnzr/sim-service-project/internal/audit"
+	"github.com/imnzr/sim-service-project/internal/lock"
+	"github.com/imnzr/sim-service-project/internal/repository"
+	"github.com/imnzr/sim-service-project/models"
+)
+
+const (
+	defaultAdminOrderLimit = 20
+	maxAdminOrderLimit     = 100
+	maxRefundReasonLength  = 500
+)
+
+// OrderFulfilLockTTL adalah umur lock pembelian nomor, lebih lama dari request ke 5sim
+const OrderFulfilLockTTL = 2 * time.Minute
+
+// OrderFulfilLockKey adalah redis lock yang dipegang selama membeli nomor untuk satu order.
+// Dipakai webhook pembayaran dan retry admin agar order yang sama tidak dibeli dua kali.
+func OrderFulfilLockKey(orderId int) string {
+	return fmt.Sprintf("lock:order_fulfil:%d", orderId)
+}
+
+// Aksi audit log untuk order
+const (
+	AuditActionOrderRecheck = "order.recheck"
+	AuditActionOrderRetry   = "order.retry_fulfilment"
+	AuditActionOrderRefund  = "order.refund"
+)
+
+var (
+	ErrOrderNotFound         = errors.New("order not found")
+	ErrOrderNotPaid          = errors.New("order has not been paid")
+	ErrOrderAlreadyFulfilled = errors.New("order already has a 5sim activation")
+	ErrOrderAlreadyRefunded  = errors.New("order has already been refunded")
+	ErrRefundReasonRequired  = errors.New("refund reason is required")
+	ErrFulfilmentInProgress  = errors.New("fulfilment for this order is already in progress")
+)
+
+// status 5sim yang sudah final, aktivasinya tidak bisa dibatalkan lagi
+var finalProviderStatuses = map[string]bool{
+	"FINISHED": true,
+	"CANCELED": true,
+	"TIMEOUT":  true,
+	"BANNED":   true,
+}
+
+type AdminOrderService interface {
+	Search(ctx context.Context, query models.AdminOrderQuery) (*models.CatalogPage[models.SimOrder], error)
+	Detail(ctx context.Context, orderId int) (*models.AdminOrderDetail, error)
+	Recheck(ctx context.Context, actorId uint, orderId int) (*models.AdminOrderDetail, error)
+	RetryFulfilment(ctx context.Context, actorId uint, orderId int) (*models.AdminOrderDetail, error)
+	Refund(ctx context.Context, actorId uint, orderId int, reason string) (*models.AdminOrderDetail, error)
+}
+
+type AdminOrderServiceImplementation struct {
+	OrderRepo    repository.SimOrderRepository
+	BalanceRepo  repository.BalanceRepository
+	OrderService OrderService
+	AuditLogger  audit.Logger
+	Lock         *lock.RedisLock
+	Webhooks     WebhookService
+}
+
+func NewAdminOrderService(orderRepo repository.SimOrderRepository, balanceRepo repository.BalanceRepository, orderService OrderService, auditLogger audit.Logger, redisLock *lock.RedisLock, webhooks WebhookService) AdminOrderService {
+	return &AdminOrderServiceImplementation{
+		OrderRepo:    orderRepo,
+		BalanceRepo:  balanceRepo,
+		OrderService: orderService,
+		AuditLogger:  auditLogger,
+		Lock:         redisLock,
+		Webhooks:     webhooks,
+	}
+}
+
+// Search implements AdminOrderService.
+func (a *AdminOrderServiceImplementation) Search(ctx context.Context, query models.AdminOrderQuery) (*models.CatalogPage[models.SimOrder], error) {
+	query.Email = normalizeEmail(query.Email)
+	query.InvoiceId = strings.TrimSpace(query.InvoiceId)
+	query.PhoneNumber = strings.TrimSpace(query.PhoneNumber)
+	query.Status = strings.ToUpper(strings.TrimSpace(query.Status))
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.Limit < 1 {
+		query.Limit = defaultAdminOrderLimit
+	}
+	if query.Limit > maxAdminOrderLimit {
+		query.Limit = maxAdminOrderLimit
+	}
+
+	orders, total, err := a.OrderRepo.Search(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search orders: %w", err)
+	}
+	return &models.CatalogPage[models.SimOrder]{Items: orders, Page: query.Page, Limit: query.Limit, Total: total}, nil
+}
+
+// Detail implements AdminOrderService.
+func (a *AdminOrderServiceImplementation) Detail(ctx context.Context, orderId int) (*models.AdminOrderDetail, error) {
+	order, err := a.getOrder(ctx, orderId)
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := a.OrderRepo.GetStatusHistory(ctx, orderId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status history: %w", err)
+	}
+	responses, err := a.OrderRepo.GetProviderResponses(ctx, orderId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get provider responses: %w", err)
+	}
+	auditLogs, err := a.AuditLogger.FindByTarget(ctx, audit.TargetOrder, strconv.Itoa(orderId))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit logs: %w", err)
+	}
+
+	return &models.AdminOrderDetail{
+		Order:             *order,
+		StatusHistory:     history,
+		ProviderResponses: responses,
+		AuditLogs:         auditLogs,
+	}, nil
+}
+
+// Recheck implements AdminOrderService.
+func (a *AdminOrderServiceImplementation) Recheck(ctx context.Context, actorId uint, orderId int) (*models.AdminOrderDetail, error) {
+	order, err := a.getOrder(ctx, orderId)
+	if err != nil {
+		return nil, err
+	}
+
+	_, checkErr := a.OrderService.RecheckOrder(ctx, order)
+	if errors.Is(checkErr, ErrNoProviderOrder) {
+		return nil, checkErr
+	}
+	a.audit(ctx, actorId, AuditActionOrderRecheck, order, errorMetadata(checkErr))
+	if checkErr != nil {
+		return nil, fmt.Errorf("failed to re-check order with 5sim: %w", checkErr)
+	}
+
+	return a.Detail(ctx, orderId)
+}
+
+// RetryFulfilment implements AdminOrderService.
+// Hanya untuk order yang sudah dibayar tetapi pembelian nomor di 5sim gagal.
+// Redis lock mencegah dua retry bersamaan membeli dua nomor.
+func (a *AdminOrderServiceImplementation) RetryFulfilment(ctx context.Context, actorId uint, orderId int) (*models.AdminOrderDetail, error) {
+	release, err := a.lockOrder(ctx, orderId)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	order, err := a.getOrder(ctx, orderId)
+	if err != nil {
+		return nil, err
+	}
+	if order.RefundedAt != nil {
+		return nil, ErrOrderAlreadyRefunded
+	}
+	if order.SimOrderServiceId != nil {
+		return nil, ErrOrderAlreadyFulfilled
+	}
+	if err := a.ensurePaid(ctx, order); err != nil {
+		return nil, err
+	}
+
+	_, fulfilErr := a.OrderService.FulfillOrder(ctx, order)
+	a.audit(ctx, actorId, AuditActionOrderRetry, order, errorMetadata(fulfilErr))
+	if fulfilErr != nil {
+		return nil, fmt.Errorf("failed to buy number from 5sim: %w", fulfilErr)
+	}
+
+	return a.Detail(ctx, orderId)
+}
+
+// Refund implements AdminOrderService.
+// Refund manual: order ditandai REFUNDED dengan alasan wajib dan dicatat di audit log.
+// Order bulk yang dibayar dengan saldo dikembalikan ke saldo, order Xendit dikembalikan di luar sistem.
+// Aktivasi 5sim yang masih berjalan dibatalkan. Lock fulfilment ikut dipegang agar order tidak
+// di-refund saat webhook pembayaran atau retry sedang membeli nomor untuk order yang sama.
+func (a *AdminOrderServiceImplementation) Refund(ctx context.Context, actorId uint, orderId int, reason string) (*models.AdminOrderDetail, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, ErrRefundReasonRequired
+	}
+	if len(reason) > maxRefundReasonLength {
+		return nil, &ValidationError{Fields: map[string]string{"reason": "reason must be at most 500 characters"}}
+	}
+
+	release, err := a.lockOrder(ctx, orderId)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	order, err := a.getOrder(ctx, orderId)
+	if err != nil {
+		return nil, err
+	}
+	if order.RefundedAt != nil {
+		return nil, ErrOrderAlreadyRefunded
+	}
+	if err := a.ensurePaid(ctx, order); err != nil {
+		return nil, err
+	}
+
+	metadata := map[string]any{}
+	if order.SimOrderServiceId != nil && !finalProviderStatuses[order.Status] {
+		_, cancelErr := a.OrderService.CancelOrder(ctx, order)
+		metadata["provider_cancelled"] = cancelErr == nil
+		if cancelErr != nil {
+			log.Printf("failed to cancel 5sim activation for refunded order %d: %v", orderId, cancelErr)
+			metadata["provider_cancel_error"] = cancelErr.Error()
+		}
+	}
+
+	// saldo dikembalikan sebelum order ditandai REFUNDED. Reference unik per order membuat langkah ini
+	// aman diulang jika MarkRefunded gagal, tanpa mengembalikan dana dua kali.
+	if order.BulkOrderId != nil {
+		transaction, err := refundOrderToWallet(ctx, a.BalanceRepo, order, &actorId, reason)
+		if err != nil {
+			return nil, err
+		}
+		metadata["wallet_refunded"] = transaction != nil
+		if transaction != nil {
+			metadata["balance_transaction_id"] = transaction.Id
+		}
+	}
+
+	refunded, err := a.OrderRepo.MarkRefunded(ctx, orderId, models.OrderSourceAdmin, reason)
+	if err != nil {
+		return nil, fmt.Errorf("failed to mark order refunded: %w", err)
+	}
+	if !refunded {
+		return nil, ErrOrderAlreadyRefunded
+	}
+
+	refundedOrder, err := a.OrderRepo.GetById(ctx, orderId)
+	if err != nil {
+		log.Printf("failed to read refunded order %d for audit snapshot: %v", orderId, err)
+	}
+	if refundedOrder != nil {
+		a.Webhooks.DispatchOrder(ctx, models.WebhookEventOrderRefunded, refundedOrder)
+	}
+	err = a.AuditLogger.Record(ctx, audit.Entry{
+		ActorUserId: actorId,
+		Action:      AuditActionOrderRefund,
+		TargetType:  audit.TargetOrder,
+		TargetId:    strconv.Itoa(orderId),
+		Reason:      reason,
+		Before:      audit.OrderSnapshot(order),
+		After:       audit.OrderSnapshot(refundedOrder),
+		Metadata:    metadata,
+	})
+	if err != nil {
+		// refund sudah diterapkan (saldo, status, webhook), jadi tidak dikembalikan sebagai error.
+		// Audit yang hilang harus ditindaklanjuti manual.
+		log.Printf("AUDIT FAILURE: refund of order %d by user %d not written to audit log: %v", orderId, actorId, err)
+	}
+
+	return a.Detail(ctx, orderId)
+}
+
+// lockOrder memegang OrderFulfilLockKey untuk aksi admin yang mengubah order.
+// Fungsi release yang dikembalikan wajib dipanggil.
+func (a *AdminOrderServiceImplementation) lockOrder(ctx context.Context, orderId int) (func(), error) {
+	lockKey := OrderFulfilLockKey(orderId)
+	token, err := a.Lock.Acquire(ctx, lockKey, OrderFulfilLockTTL)
+	if err != nil {
+		if errors.Is(err, lock.ErrNotAcquired) {
+			return nil, ErrFulfilmentInProgress
+		}
+		return nil, fmt.Errorf("failed to acquire fulfilment lock: %w", err)
+	}
+	return func() {
+		if err := a.Lock.Release(context.Background(), lockKey, token); err != nil {
+			log.Printf("failed to release fulfilment lock for order %d: %v", orderId, err)
+		}
+	}, nil
+}
+
+func (a *AdminOrderServiceImplementation) getOrder(ctx context.Context, orderId int) (*models.SimOrder, error) {
+	order, err := a.OrderRepo.GetById(ctx, orderId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+	if order == nil {
+		return nil, ErrOrderNotFound
+	}
+	return order, nil
+}
+
+// ensurePaid memastikan order sudah dibayar. Order lama dari sebelum riwayat status dicatat
+// tidak punya baris di sim_order_status_history, jadi status saat ini dan nomor yang
+// sudah dibeli dari 5sim ikut dianggap bukti pembayaran.
+func (a *AdminOrderServiceImplementation) ensurePaid(ctx context.Context, order *models.SimOrder) error {
+	if order.Status == models.OrderStatusPaid || order.SimOrderServiceId != nil {
+		return nil
+	}
+	paid, err := a.OrderRepo.HasStatus(ctx, order.Id, models.OrderStatusPaid)
+	if err != nil {
+		return fmt.Errorf("failed to check payment status: %w", err)
+	}
+	if !paid {
+		return ErrOrderNotPaid
+	}
+	return nil
+}
+
+// audit mencatat aksi admin yang tidak wajib berhasil (recheck/retry), kegagalan hanya di-log.
+// Snapshot after dibaca ulang dari database karena aksi ke 5sim bisa mengubah order.
+func (a *AdminOrderServiceImplementation) audit(ctx context.Context, actorId uint, action string, before *models.SimOrder, metadata any) {
+	entry := audit.Entry{
+		ActorUserId: actorId,
+		Action:      action,
+		TargetType:  audit.TargetOrder,
+		TargetId:    strconv.Itoa(before.Id),
+		Before:      audit.OrderSnapshot(before),
+		Metadata:    metadata,
+	}
+	if after, err := a.OrderRepo.GetById(ctx, before.Id); err == nil {
+		entry.After = audit.OrderSnapshot(after)
+	}
+	if err := a.AuditLogger.Record(ctx, entry); err != nil {
+		log.Printf("failed to write audit log %s for order %d: %v", action, before.Id, err)
+	}
+}
+
+func errorMetadata(err error) map[string]any {
+	if err == nil {
+		return map[string]any{"success": true}
+	}
+	return map[string]any{"success": false, "error": err.Error()}
+}
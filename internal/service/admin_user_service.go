@@ -0,0 +1,353 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/imnzr/sim-service-project/internal/audit"
+	"github.com/imnzr/sim-service-project/internal/cache"
+	"github.com/imnzr/sim-service-project/internal/repository"
+	"github.com/imnzr/sim-service-project/models"
+)
+
+const (
+	defaultAdminUserLimit     = 20
+	maxAdminUserLimit         = 100
+	maxSuspendReasonLength    = 500
+	maxBalanceNoteLength      = 500
+	maxIdempotencyKeyLength   = 40
+	adminUserHistoryLimit     = 50
+	adminUserPaymentListLimit = 100
+)
+
+// Aksi audit log untuk user
+const (
+	AuditActionUserSuspend       = "user.suspend"
+	AuditActionUserUnsuspend     = "user.unsuspend"
+	AuditActionUserBalanceAdjust = "user.balance_adjust"
+)
+
+var (
+	ErrUserNotFound          = errors.New("user not found")
+	ErrSuspendReasonRequired = errors.New("reason is required")
+	ErrCannotSuspendSelf     = errors.New("you cannot suspend your own account")
+	ErrUserAlreadySuspended  = errors.New("user is already suspended")
+	ErrUserNotSuspended      = errors.New("user is not suspended")
+	ErrInsufficientBalance   = errors.New("adjustment would make the balance negative")
+	ErrIdempotencyKeyReused  = errors.New("idempotency_key was already used for a different adjustment")
+)
+
+type AdminUserService interface {
+	Search(ctx context.Context, query models.AdminUserQuery) (*models.CatalogPage[models.User], error)
+	Detail(ctx context.Context, userId uint) (*models.AdminUserDetail, error)
+	Orders(ctx context.Context, userId uint, page, limit int) (*models.CatalogPage[models.SimOrder], error)
+	Payments(ctx context.Context, userId uint) ([]models.UserPayment, error)
+	Suspend(ctx context.Context, actorId, userId uint, reason string) (*models.AdminUserDetail, error)
+	Unsuspend(ctx context.Context, actorId, userId uint, reason string) (*models.AdminUserDetail, error)
+	AdjustBalance(ctx context.Context, actorId, userId uint, req *models.BalanceAdjustmentRequest) (*models.BalanceTransaction, error)
+}
+
+type AdminUserServiceImplementation struct {
+	UserRepo    repository.UserRepository
+	BalanceRepo repository.BalanceRepository
+	OrderRepo   repository.SimOrderRepository
+	UserService UserService
+	AuditLogger audit.Logger
+	denyList    *cache.TokenDenyList
+}
+
+func NewAdminUserService(userRepo repository.UserRepository, balanceRepo repository.BalanceRepository, orderRepo repository.SimOrderRepository, userService UserService, auditLogger audit.Logger, denyList *cache.TokenDenyList) AdminUserService {
+	return &AdminUserServiceImplementation{
+		UserRepo:    userRepo,
+		BalanceRepo: balanceRepo,
+		OrderRepo:   orderRepo,
+		UserService: userService,
+		AuditLogger: auditLogger,
+		denyList:    denyList,
+	}
+}
+
+// Search implements AdminUserService.
+func (a *AdminUserServiceImplementation) Search(ctx context.Context, query models.AdminUserQuery) (*models.CatalogPage[models.User], error) {
+	query.Search = strings.TrimSpace(query.Search)
+	query.Role = strings.ToLower(strings.TrimSpace(query.Role))
+	if query.Role != "" && !models.ValidRole(query.Role) {
+		return nil, &ValidationError{Fields: map[string]string{"role": "unknown role"}}
+	}
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.Limit < 1 {
+		query.Limit = defaultAdminUserLimit
+	}
+	if query.Limit > maxAdminUserLimit {
+		query.Limit = maxAdminUserLimit
+	}
+
+	users, total, err := a.UserRepo.Search(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search users: %w", err)
+	}
+	return &models.CatalogPage[models.User]{Items: users, Page: query.Page, Limit: query.Limit, Total: total}, nil
+}
+
+// Detail implements AdminUserService.
+func (a *AdminUserServiceImplementation) Detail(ctx context.Context, userId uint) (*models.AdminUserDetail, error) {
+	user, err := a.getUser(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions, err := a.BalanceRepo.FindByUser(ctx, userId, adminUserHistoryLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get balance transactions: %w", err)
+	}
+	auditLogs, err := a.AuditLogger.FindByTarget(ctx, audit.TargetUser, userTargetId(userId))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit logs: %w", err)
+	}
+
+	return &models.AdminUserDetail{
+		User:                *user,
+		BalanceTransactions: transactions,
+		AuditLogs:           auditLogs,
+	}, nil
+}
+
+// Orders implements AdminUserService.
+func (a *AdminUserServiceImplementation) Orders(ctx context.Context, userId uint, page, limit int) (*models.CatalogPage[models.SimOrder], error) {
+	if _, err := a.getUser(ctx, userId); err != nil {
+		return nil, err
+	}
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = defaultAdminOrderLimit
+	}
+	if limit > maxAdminOrderLimit {
+		limit = maxAdminOrderLimit
+	}
+
+	orders, total, err := a.OrderRepo.Search(ctx, models.AdminOrderQuery{UserId: int(userId), Page: page, Limit: limit})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user orders: %w", err)
+	}
+	return &models.CatalogPage[models.SimOrder]{Items: orders, Page: page, Limit: limit, Total: total}, nil
+}
+
+// Payments implements AdminUserService.
+func (a *AdminUserServiceImplementation) Payments(ctx context.Context, userId uint) ([]models.UserPayment, error) {
+	if _, err := a.getUser(ctx, userId); err != nil {
+		return nil, err
+	}
+	payments, err := a.OrderRepo.FindPaymentsByUser(ctx, int(userId), adminUserPaymentListLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user payments: %w", err)
+	}
+	return payments, nil
+}
+
+// Suspend implements AdminUserService.
+// User yang di-suspend tidak bisa login/refresh, token yang masih aktif langsung dicabut
+// dan AuthMiddleware menolak request berikutnya lewat flag di redis (fallback ke database).
+func (a *AdminUserServiceImplementation) Suspend(ctx context.Context, actorId, userId uint, reason string) (*models.AdminUserDetail, error) {
+	reason, err := validateSuspendReason(reason)
+	if err != nil {
+		return nil, err
+	}
+	if actorId == userId {
+		return nil, ErrCannotSuspendSelf
+	}
+	user, err := a.getUser(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	// flag redis dipasang lebih dulu karena AuthMiddleware membaca redis. Jika gagal, suspend dibatalkan
+	// sebelum database berubah agar admin bisa mengulang tanpa user tetap bisa memakai token aktifnya.
+	if err := a.denyList.SuspendUser(ctx, userId); err != nil {
+		return nil, fmt.Errorf("failed to set suspension flag: %w", err)
+	}
+	changed, err := a.UserRepo.SetSuspended(ctx, userId, true, reason)
+	if err != nil {
+		if clearErr := a.denyList.UnsuspendUser(ctx, userId); clearErr != nil {
+			log.Printf("failed to clear suspension flag for user %d: %v", userId, clearErr)
+		}
+		return nil, fmt.Errorf("failed to suspend user: %w", err)
+	}
+	if !changed {
+		return nil, ErrUserAlreadySuspended
+	}
+
+	if err := a.UserService.RevokeAllSessions(ctx, userId); err != nil {
+		log.Printf("failed to revoke sessions of suspended user %d: %v", userId, err)
+	}
+
+	a.record(ctx, actorId, AuditActionUserSuspend, user, reason, nil)
+	return a.Detail(ctx, userId)
+}
+
+// Unsuspend implements AdminUserService.
+func (a *AdminUserServiceImplementation) Unsuspend(ctx context.Context, actorId, userId uint, reason string) (*models.AdminUserDetail, error) {
+	reason, err := validateSuspendReason(reason)
+	if err != nil {
+		return nil, err
+	}
+	user, err := a.getUser(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	changed, err := a.UserRepo.SetSuspended(ctx, userId, false, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to unsuspend user: %w", err)
+	}
+	if !changed {
+		return nil, ErrUserNotSuspended
+	}
+	if err := a.denyList.UnsuspendUser(ctx, userId); err != nil {
+		log.Printf("failed to clear suspension flag for user %d: %v", userId, err)
+	}
+
+	a.record(ctx, actorId, AuditActionUserUnsuspend, user, reason, nil)
+	return a.Detail(ctx, userId)
+}
+
+// AdjustBalance implements AdminUserService.
+// Amount positif menambah saldo, negatif mengurangi. Saldo tidak boleh menjadi negatif.
+func (a *AdminUserServiceImplementation) AdjustBalance(ctx context.Context, actorId, userId uint, req *models.BalanceAdjustmentRequest) (*models.BalanceTransaction, error) {
+	req.ReasonCode = strings.ToLower(strings.TrimSpace(req.ReasonCode))
+	req.Note = strings.TrimSpace(req.Note)
+	req.IdempotencyKey = strings.TrimSpace(req.IdempotencyKey)
+
+	fields := map[string]string{}
+	if req.Amount == 0 || math.IsNaN(req.Amount) || math.IsInf(req.Amount, 0) {
+		fields["amount"] = "amount must be a non-zero number"
+	} else if cents := req.Amount * 100; math.Abs(cents-math.Round(cents)) > 1e-6 {
+		fields["amount"] = "amount must have at most 2 decimal places"
+	}
+	if !models.ValidBalanceReason(req.ReasonCode) {
+		fields["reason_code"] = "reason_code must be one of compensation, refund, correction, promotion, chargeback"
+	}
+	if req.Note == "" {
+		fields["note"] = "note is required"
+	} else if len(req.Note) > maxBalanceNoteLength {
+		fields["note"] = "note must be at most 500 characters"
+	}
+	if req.IdempotencyKey == "" {
+		fields["idempotency_key"] = "idempotency_key is required"
+	} else if len(req.IdempotencyKey) > maxIdempotencyKeyLength {
+		fields["idempotency_key"] = "idempotency_key must be at most 40 characters"
+	}
+	if len(fields) > 0 {
+		return nil, &ValidationError{Fields: fields}
+	}
+
+	user, err := a.getUser(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	reference := adjustmentReference(userId, req.IdempotencyKey)
+	transaction := &models.BalanceTransaction{
+		UserId:      userId,
+		Type:        models.BalanceTxAdjustment,
+		Amount:      req.Amount,
+		ReasonCode:  &req.ReasonCode,
+		Note:        &req.Note,
+		Reference:   &reference,
+		ActorUserId: &actorId,
+	}
+	if err := a.BalanceRepo.Apply(ctx, transaction); err != nil {
+		if errors.Is(err, repository.ErrInsufficientBalance) {
+			return nil, ErrInsufficientBalance
+		}
+		if errors.Is(err, repository.ErrDuplicateEntry) {
+			return a.existingAdjustment(ctx, userId, reference, req)
+		}
+		return nil, fmt.Errorf("failed to adjust balance: %w", err)
+	}
+
+	metadata := map[string]any{
+		"transaction_id": transaction.Id,
+		"amount":         transaction.Amount,
+		"balance_after":  transaction.BalanceAfter,
+		"reason_code":    req.ReasonCode,
+	}
+	a.record(ctx, actorId, AuditActionUserBalanceAdjust, user, req.Note, metadata)
+	return transaction, nil
+}
+
+// adjustmentReference adalah reference ledger penyesuaian manual, unik per user dan idempotency key
+func adjustmentReference(userId uint, key string) string {
+	return fmt.Sprintf("adjust:%d:%s", userId, key)
+}
+
+// existingAdjustment mengembalikan penyesuaian yang sudah dicatat dengan idempotency key yang sama.
+// Key yang dipakai ulang untuk amount berbeda ditolak agar kesalahan client tidak tertutupi.
+func (a *AdminUserServiceImplementation) existingAdjustment(ctx context.Context, userId uint, reference string, req *models.BalanceAdjustmentRequest) (*models.BalanceTransaction, error) {
+	existing, err := a.BalanceRepo.FindByReference(ctx, models.BalanceTxAdjustment, reference)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get balance adjustment: %w", err)
+	}
+	if existing == nil || existing.UserId != userId || math.Abs(existing.Amount-req.Amount) > 0.001 {
+		return nil, ErrIdempotencyKeyReused
+	}
+	return existing, nil
+}
+
+func (a *AdminUserServiceImplementation) getUser(ctx context.Context, userId uint) (*models.User, error) {
+	user, err := a.UserRepo.GetUserById(ctx, userId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
+
+// record menulis audit log aksi admin terhadap user dengan snapshot sebelum dan sesudah aksi.
+// Perubahan sudah tersimpan, sehingga audit yang gagal hanya di-log keras untuk ditindaklanjuti manual
+// dan tidak dikembalikan sebagai error (admin yang mengulang aksi bisa menerapkannya dua kali).
+func (a *AdminUserServiceImplementation) record(ctx context.Context, actorId uint, action string, before *models.User, reason string, metadata any) {
+	after, err := a.UserRepo.GetUserById(ctx, before.ID)
+	if err != nil {
+		log.Printf("failed to read user %d for audit snapshot: %v", before.ID, err)
+	}
+
+	err = a.AuditLogger.Record(ctx, audit.Entry{
+		ActorUserId: actorId,
+		Action:      action,
+		TargetType:  audit.TargetUser,
+		TargetId:    userTargetId(before.ID),
+		Reason:      reason,
+		Before:      audit.UserSnapshot(before),
+		After:       audit.UserSnapshot(after),
+		Metadata:    metadata,
+	})
+	if err != nil {
+		log.Printf("AUDIT FAILURE: %s on user %d by user %d not written to audit log: %v", action, before.ID, actorId, err)
+	}
+}
+
+func validateSuspendReason(reason string) (string, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return "", ErrSuspendReasonRequired
+	}
+	if len(reason) > maxSuspendReasonLength {
+		return "", &ValidationError{Fields: map[string]string{"reason": "reason must be at most 500 characters"}}
+	}
+	return reason, nil
+}
+
+func userTargetId(userId uint) string {
+	return strconv.FormatUint(uint64(userId), 10)
+}
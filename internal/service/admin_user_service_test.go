@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/imnzr/sim-service-project/models"
+)
+
+func newAdjustmentTestService(balance float64) (*AdminUserServiceImplementation, *fakeBalanceRepo, *fakeAuditLogger) {
+	users := &fakeUserRepo{users: map[uint]*models.User{
+		1: {ID: 1, Username: "reseller", Email: "reseller@example.com", Role: models.RoleReseller},
+	}}
+	balances := newFakeBalanceRepo(map[uint]float64{1: balance})
+	auditLogger := &fakeAuditLogger{}
+	service := NewAdminUserService(users, balances, nil, nil, auditLogger, nil).(*AdminUserServiceImplementation)
+	return service, balances, auditLogger
+}
+
+func adjustmentRequest(amount float64, key string) *models.BalanceAdjustmentRequest {
+	return &models.BalanceAdjustmentRequest{Amount: amount, ReasonCode: "compensation", Note: "order 42 not delivered", IdempotencyKey: key}
+}
+
+func TestAdjustBalanceRetryWithSameKeyAppliesOnce(t *testing.T) {
+	service, balances, _ := newAdjustmentTestService(1000)
+	ctx := context.Background()
+
+	first, err := service.AdjustBalance(ctx, 99, 1, adjustmentRequest(5000, "ticket-123"))
+	if err != nil {
+		t.Fatalf("first adjustment: %v", err)
+	}
+	// admin menekan tombol dua kali atau request diulang setelah timeout
+	second, err := service.AdjustBalance(ctx, 99, 1, adjustmentRequest(5000, "ticket-123"))
+	if err != nil {
+		t.Fatalf("repeated adjustment must succeed: %v", err)
+	}
+	if second.Id != first.Id {
+		t.Fatalf("repeated adjustment must return the original transaction %d, got %d", first.Id, second.Id)
+	}
+	if balances.balances[1] != 6000 || len(balances.transactions) != 1 {
+		t.Fatalf("adjustment applied twice: balance %v, %d transactions", balances.balances[1], len(balances.transactions))
+	}
+}
+
+func TestAdjustBalanceRejectsReusedKeyWithDifferentAmount(t *testing.T) {
+	service, balances, _ := newAdjustmentTestService(1000)
+	ctx := context.Background()
+
+	if _, err := service.AdjustBalance(ctx, 99, 1, adjustmentRequest(5000, "ticket-123")); err != nil {
+		t.Fatalf("first adjustment: %v", err)
+	}
+	if _, err := service.AdjustBalance(ctx, 99, 1, adjustmentRequest(7000, "ticket-123")); !errors.Is(err, ErrIdempotencyKeyReused) {
+		t.Fatalf("expected ErrIdempotencyKeyReused, got %v", err)
+	}
+	if balances.balances[1] != 6000 {
+		t.Fatalf("reused key must not change the balance, got %v", balances.balances[1])
+	}
+}
+
+func TestAdjustBalanceKeysAreScopedPerUser(t *testing.T) {
+	service, balances, _ := newAdjustmentTestService(1000)
+	service.UserRepo.(*fakeUserRepo).users[2] = &models.User{ID: 2, Username: "customer", Role: models.RoleCustomer}
+	ctx := context.Background()
+
+	if _, err := service.AdjustBalance(ctx, 99, 1, adjustmentRequest(5000, "ticket-123")); err != nil {
+		t.Fatalf("adjustment for user 1: %v", err)
+	}
+	if _, err := service.AdjustBalance(ctx, 99, 2, adjustmentRequest(5000, "ticket-123")); err != nil {
+		t.Fatalf("same key for another user must be applied: %v", err)
+	}
+	if balances.balances[1] != 6000 || balances.balances[2] != 5000 {
+		t.Fatalf("unexpected balances: user 1 %v, user 2 %v", balances.balances[1], balances.balances[2])
+	}
+}
+
+func TestAdjustBalanceInsufficientBalance(t *testing.T) {
+	service, balances, _ := newAdjustmentTestService(1000)
+
+	if _, err := service.AdjustBalance(context.Background(), 99, 1, adjustmentRequest(-5000, "chargeback-1")); !errors.Is(err, ErrInsufficientBalance) {
+		t.Fatalf("expected ErrInsufficientBalance, got %v", err)
+	}
+	if balances.balances[1] != 1000 || len(balances.transactions) != 0 {
+		t.Fatal("balance must not go negative")
+	}
+}
+
+func TestAdjustBalanceSucceedsWhenAuditFails(t *testing.T) {
+	service, balances, auditLogger := newAdjustmentTestService(1000)
+	auditLogger.err = errors.New("audit store unavailable")
+
+	transaction, err := service.AdjustBalance(context.Background(), 99, 1, adjustmentRequest(5000, "ticket-123"))
+	if err != nil {
+		t.Fatalf("audit failure must not fail an applied adjustment: %v", err)
+	}
+	if transaction.BalanceAfter != 6000 || balances.balances[1] != 6000 {
+		t.Fatalf("unexpected balance after adjustment: %v", transaction.BalanceAfter)
+	}
+}
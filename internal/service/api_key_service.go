@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/imnzr/sim-service-project/config"
+	"github.com/imnzr/sim-service-project/helper"
+	"github.com/imnzr/sim-service-project/internal/audit"
+	"github.com/imnzr/sim-service-project/internal/cache"
+	"github.com/imnzr/sim-service-project/internal/repository"
+	"github.com/imnzr/sim-service-project/models"
+)
+
+const (
+	apiKeyPrefix        = "sk_"
+	apiKeyRandomBytes   = 24
+	apiKeyDisplayLength = 12
+	maxAPIKeyNameLength = 100
+	maxAPIKeyExpiryDays = 365
+)
+
+// Aksi audit log untuk API key
+const (
+	AuditActionAPIKeyCreate = "api_key.create"
+	AuditActionAPIKeyRevoke = "api_key.revoke"
+)
+
+var (
+	ErrInvalidAPIKey  = errors.New("invalid, expired or revoked API key")
+	ErrAPIKeyNotFound = errors.New("API key not found")
+	ErrTooManyAPIKeys = errors.New("maximum number of active API keys reached")
+)
+
+type APIKeyService interface {
+	Create(ctx context.Context, userId uint, req *models.CreateAPIKeyRequest) (*models.CreateAPIKeyResponse, error)
+	List(ctx context.Context, userId uint) ([]models.APIKey, error)
+	Revoke(ctx context.Context, userId uint, keyId int64) error
+	Authenticate(ctx context.Context, rawKey string, ipAddress string) (*models.APIKey, *models.User, error)
+	AllowRequest(ctx context.Context, key *models.APIKey) (bool, error)
+}
+
+type APIKeyServiceImplementation struct {
+	APIKeyRepo   repository.APIKeyRepository
+	UserRepo     repository.UserRepository
+	rateLimiter  *cache.RateLimiter
+	auditLogger  audit.Logger
+	rateLimit    int
+	maxRateLimit int
+	rateWindow   time.Duration
+	maxPerUser   int
+}
+
+func NewAPIKeyService(apiKeyRepo repository.APIKeyRepository, userRepo repository.UserRepository, rateLimiter *cache.RateLimiter, auditLogger audit.Logger, cfg config.AppConfig) APIKeyService {
+	return &APIKeyServiceImplementation{
+		APIKeyRepo:   apiKeyRepo,
+		UserRepo:     userRepo,
+		rateLimiter:  rateLimiter,
+		auditLogger:  auditLogger,
+		rateLimit:    cfg.APIKeyRateLimit,
+		maxRateLimit: cfg.APIKeyMaxRateLimit,
+		rateWindow:   cfg.APIKeyRateWindow,
+		maxPerUser:   cfg.APIKeyMaxPerUser,
+	}
+}
+
+func (a *APIKeyServiceImplementation) validateCreate(req *models.CreateAPIKeyRequest) error {
+	req.Name = strings.TrimSpace(req.Name)
+
+	fields := map[string]string{}
+	if req.Name == "" {
+		fields["name"] = "name is required"
+	} else if len(req.Name) > maxAPIKeyNameLength {
+		fields["name"] = "name must be at most 100 characters"
+	}
+
+	scopes := []string{}
+	for _, scope := range req.Scopes {
+		scope = strings.TrimSpace(scope)
+		if !models.ValidAPIKeyScope(scope) {
+			fields["scopes"] = fmt.Sprintf("unknown scope %q, allowed: %s", scope, strings.Join(models.APIKeyScopes, ", "))
+			break
+		}
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	if len(scopes) == 0 && fields["scopes"] == "" {
+		fields["scopes"] = "at least one scope is required"
+	}
+	req.Scopes = scopes
+
+	if req.RateLimit == 0 {
+		req.RateLimit = a.rateLimit
+	}
+	if req.RateLimit < 1 || req.RateLimit > a.maxRateLimit {
+		fields["rate_limit"] = fmt.Sprintf("rate_limit must be between 1 and %d requests per %s", a.maxRateLimit, a.rateWindow)
+	}
+	if req.ExpiresInDays < 0 || req.ExpiresInDays > maxAPIKeyExpiryDays {
+		fields["expires_in_days"] = "expires_in_days must be between 0 (never) and 365"
+	}
+
+	if len(fields) > 0 {
+		return &ValidationError{Fields: fields}
+	}
+	return nil
+}
+
+// Create implements APIKeyService.
+// Key asli hanya dikembalikan sekali, yang disimpan hanya hash-nya.
+func (a *APIKeyServiceImplementation) Create(ctx context.Context, userId uint, req *models.CreateAPIKeyRequest) (*models.CreateAPIKeyResponse, error) {
+	if err := a.validateCreate(req); err != nil {
+		return nil, err
+	}
+
+	active, err := a.APIKeyRepo.CountActiveByUser(ctx, userId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count API keys: %w", err)
+	}
+	if active >= a.maxPerUser {
+		return nil, ErrTooManyAPIKeys
+	}
+
+	random, err := helper.RandomToken(apiKeyRandomBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate API key: %w", err)
+	}
+	rawKey := apiKeyPrefix + random
+
+	key := &models.APIKey{
+		UserId:    userId,
+		Name:      req.Name,
+		Prefix:    rawKey[:apiKeyDisplayLength],
+		KeyHash:   helper.HashToken(rawKey),
+		Scopes:    req.Scopes,
+		RateLimit: req.RateLimit,
+		CreatedAt: time.Now(),
+	}
+	if req.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		key.ExpiresAt = &expiresAt
+	}
+	if err := a.APIKeyRepo.Create(ctx, key); err != nil {
+		return nil, fmt.Errorf("failed to create API key: %w", err)
+	}
+
+	a.audit(ctx, userId, AuditActionAPIKeyCreate, key.Id, map[string]any{
+		"name":       key.Name,
+		"prefix":     key.Prefix,
+		"scopes":     key.Scopes,
+		"rate_limit": key.RateLimit,
+		"expires_at": key.ExpiresAt,
+	})
+	return &models.CreateAPIKeyResponse{APIKey: *key, Key: rawKey}, nil
+}
+
+// List implements APIKeyService.
+func (a *APIKeyServiceImplementation) List(ctx context.Context, userId uint) ([]models.APIKey, error) {
+	keys, err := a.APIKeyRepo.FindByUser(ctx, userId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+	return keys, nil
+}
+
+// Revoke implements APIKeyService.
+func (a *APIKeyServiceImplementation) Revoke(ctx context.Context, userId uint, keyId int64) error {
+	revoked, err := a.APIKeyRepo.Revoke(ctx, userId, keyId)
+	if err != nil {
+		return fmt.Errorf("failed to revoke API key: %w", err)
+	}
+	if !revoked {
+		return ErrAPIKeyNotFound
+	}
+
+	a.audit(ctx, userId, AuditActionAPIKeyRevoke, keyId, nil)
+	return nil
+}
+
+// Authenticate implements APIKeyService.
+// Key harus aktif dan pemiliknya belum dihapus atau di-suspend.
+func (a *APIKeyServiceImplementation) Authenticate(ctx context.Context, rawKey string, ipAddress string) (*models.APIKey, *models.User, error) {
+	if !strings.HasPrefix(rawKey, apiKeyPrefix) {
+		return nil, nil, ErrInvalidAPIKey
+	}
+
+	key, err := a.APIKeyRepo.FindByHash(ctx, helper.HashToken(rawKey))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+	if key == nil || key.RevokedAt != nil || (key.ExpiresAt != nil && time.Now().After(*key.ExpiresAt)) {
+		return nil, nil, ErrInvalidAPIKey
+	}
+
+	user, err := a.UserRepo.GetUserById(ctx, key.UserId)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil, nil, ErrInvalidAPIKey
+	}
+	if user.SuspendedAt != nil {
+		return nil, nil, ErrAccountSuspended
+	}
+
+	if err := a.APIKeyRepo.TouchLastUsed(ctx, key.Id, ipAddress); err != nil {
+		log.Printf("failed to update last used of API key %d: %v", key.Id, err)
+	}
+	return key, user, nil
+}
+
+// AllowRequest implements APIKeyService.
+// Rate limit dihitung per key, bukan per user, sehingga satu integrasi tidak menghabiskan jatah integrasi lain.
+func (a *APIKeyServiceImplementation) AllowRequest(ctx context.Context, key *models.APIKey) (bool, error) {
+	return a.rateLimiter.Allow(ctx, "api_key:"+strconv.FormatInt(key.Id, 10), key.RateLimit, a.rateWindow)
+}
+
+func (a *APIKeyServiceImplementation) audit(ctx context.Context, userId uint, action string, keyId int64, metadata any) {
+	err := a.auditLogger.Record(ctx, audit.Entry{
+		ActorUserId: userId,
+		Action:      action,
+		TargetType:  audit.TargetAPIKey,
+		TargetId:    strconv.FormatInt(keyId, 10),
+		Metadata:    metadata,
+	})
+	if err != nil {
+		log.Printf("failed to write audit log %s for API key %d: %v", action, keyId, err)
+	}
+}
@@ -0,0 +1,355 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/imnzr/sim-service-project/config"
+	"github.com/imnzr/sim-service-project/internal/audit"
+	"github.com/imnzr/sim-service-project/internal/repository"
+	"github.com/imnzr/sim-service-project/models"
+)
+
+// AuditActionOrderBulkCreate dicatat sekali per bulk order
+const AuditActionOrderBulkCreate = "order.bulk_create"
+
+// ErrWalletBalanceTooLow dikembalikan jika saldo tidak cukup untuk seluruh bulk order
+var ErrWalletBalanceTooLow = errors.New("insufficient wallet balance")
+
+type BulkOrderService interface {
+	Create(ctx context.Context, userId uint, req *models.CreateBulkOrderRequest) (*models.BulkOrderResult, error)
+}
+
+type BulkOrderServiceImplementation struct {
+	BulkRepo       repository.BulkOrderRepository
+	OrderRepo      repository.SimOrderRepository
+	BalanceRepo    repository.BalanceRepository
+	ProductService ProductService
+	PriceTiers     PriceTierService
+	OrderService   OrderService
+	Webhooks       WebhookService
+	AuditLogger    audit.Logger
+	maxItems       int
+	maxQuantity    int
+	workers        int
+}
+
+func NewBulkOrderService(bulkRepo repository.BulkOrderRepository, orderRepo repository.SimOrderRepository, balanceRepo repository.BalanceRepository, productService ProductService, priceTiers PriceTierService, orderService OrderService, webhooks WebhookService, auditLogger audit.Logger, cfg config.AppConfig) BulkOrderService {
+	return &BulkOrderServiceImplementation{
+		BulkRepo:       bulkRepo,
+		OrderRepo:      orderRepo,
+		BalanceRepo:    balanceRepo,
+		ProductService: productService,
+		PriceTiers:     priceTiers,
+		OrderService:   orderService,
+		Webhooks:       webhooks,
+		AuditLogger:    auditLogger,
+		maxItems:       cfg.BulkOrderMaxItems,
+		maxQuantity:    cfg.BulkOrderMaxQuantity,
+		workers:        max(cfg.BulkOrderWorkers, 1),
+	}
+}
+
+// bulkUnit adalah satu nomor di dalam bulk order
+type bulkUnit struct {
+	order  *models.SimOrder
+	result models.BulkOrderItemResult
+	err    error
+}
+
+func (b *BulkOrderServiceImplementation) validate(req *models.CreateBulkOrderRequest) error {
+	errs := fieldErrors{}
+	if len(req.Items) == 0 {
+		errs.add("items", "at least one item is required")
+	}
+	if len(req.Items) > b.maxItems {
+		errs.add("items", fmt.Sprintf("at most %d items are allowed", b.maxItems))
+	}
+
+	quantity := 0
+	for i, item := range req.Items {
+		field := fmt.Sprintf("items[%d]", i)
+		if item.Service == "" || item.Country == "" || item.Operator == "" {
+			errs.add(field, "service, country and operator are required")
+		}
+		if item.Quantity < 1 {
+			errs.add(field, "quantity must be at least 1")
+		}
+		quantity += item.Quantity
+	}
+	if quantity > b.maxQuantity {
+		errs.add("items", fmt.Sprintf("total quantity must be at most %d", b.maxQuantity))
+	}
+	return errs.err()
+}
+
+// Create implements BulkOrderService.
+// Semua harga dihitung dulu, saldo dipotong sekali untuk seluruh nomor, lalu nomor dibeli
+// bersamaan dengan worker pool terbatas. Nomor yang gagal dibeli di-refund ke saldo per order
+// (reference "order:<id>") sehingga refund manual admin untuk order yang sama tidak mengembalikan dana dua kali.
+func (b *BulkOrderServiceImplementation) Create(ctx context.Context, userId uint, req *models.CreateBulkOrderRequest) (*models.BulkOrderResult, error) {
+	if err := b.validate(req); err != nil {
+		return nil, err
+	}
+
+	// harga dihitung di server, termasuk diskon tier reseller
+	quotes := make([]*models.PriceQuote, len(req.Items))
+	errs := fieldErrors{}
+	total := 0.0
+	quantity := 0
+	for i, item := range req.Items {
+		quote, err := b.ProductService.QuotePrice(ctx, item.Service, item.Country, item.Operator)
+		if err != nil {
+			errs.add(fmt.Sprintf("items[%d]", i), err.Error())
+			continue
+		}
+		if err := b.PriceTiers.ApplyToQuote(ctx, userId, quote); err != nil {
+			return nil, err
+		}
+		quotes[i] = quote
+		total += quote.PriceSell * float64(item.Quantity)
+		quantity += item.Quantity
+	}
+	if err := errs.err(); err != nil {
+		return nil, err
+	}
+
+	bulk := &models.BulkOrder{
+		UserId:      userId,
+		Status:      models.BulkOrderProcessing,
+		Quantity:    quantity,
+		TotalAmount: roundAmount(total),
+	}
+	if err := b.BulkRepo.Create(ctx, bulk); err != nil {
+		return nil, fmt.Errorf("failed to create bulk order: %w", err)
+	}
+
+	// order dibuat PENDING dulu dan baru PAID setelah saldo berhasil dipotong
+	units := make([]*bulkUnit, 0, quantity)
+	for i, item := range req.Items {
+		quote := quotes[i]
+		for range item.Quantity {
+			order := &models.SimOrder{
+				UserId:         int(userId),
+				Service:        item.Service,
+				Country:        item.Country,
+				Operator:       item.Operator,
+				PriceSell:      quote.PriceSell,
+				ExchangeRate:   &quote.ExchangeRate,
+				ExchangeRateId: &quote.ExchangeRateId,
+				Status:         models.OrderStatusPending,
+				BulkOrderId:    &bulk.Id,
+			}
+			if _, err := b.OrderRepo.Create(ctx, order); err != nil {
+				b.abort(ctx, bulk, units, "failed to create order")
+				return nil, fmt.Errorf("failed to create order: %w", err)
+			}
+			units = append(units, &bulkUnit{
+				order: order,
+				result: models.BulkOrderItemResult{
+					ItemIndex: i,
+					OrderId:   order.Id,
+					InvoiceId: order.InvoiceId,
+					Service:   order.Service,
+					Country:   order.Country,
+					Operator:  order.Operator,
+					Price:     order.PriceSell,
+				},
+			})
+		}
+	}
+
+	reference := bulkReference(bulk.Id)
+	chargeNote := fmt.Sprintf("bulk order of %d numbers", quantity)
+	charge := &models.BalanceTransaction{
+		UserId:    userId,
+		Type:      models.BalanceTxOrderCharge,
+		Amount:    -bulk.TotalAmount,
+		Note:      &chargeNote,
+		Reference: &reference,
+	}
+	if err := b.BalanceRepo.Apply(ctx, charge); err != nil {
+		if errors.Is(err, repository.ErrInsufficientBalance) {
+			b.abort(ctx, bulk, units, ErrWalletBalanceTooLow.Error())
+			return nil, ErrWalletBalanceTooLow
+		}
+		b.abort(ctx, bulk, units, "failed to charge wallet")
+		return nil, fmt.Errorf("failed to charge wallet: %w", err)
+	}
+	balanceAfter := charge.BalanceAfter
+
+	// saldo sudah terpotong, sisa proses tidak boleh berhenti walaupun client memutus koneksi
+	workCtx := context.WithoutCancel(ctx)
+	for _, unit := range units {
+		if err := b.OrderRepo.UpdateStatusByInvoiceId(workCtx, unit.order.InvoiceId, models.OrderStatusPaid); err != nil {
+			unit.err = fmt.Errorf("failed to mark order paid: %w", err)
+			continue
+		}
+		unit.order.Status = models.OrderStatusPaid
+	}
+	b.fulfil(workCtx, units)
+
+	failed := []*bulkUnit{}
+	for _, unit := range units {
+		if unit.err == nil {
+			unit.result.Status = models.BulkItemFulfilled
+			continue
+		}
+		if markErr := b.OrderRepo.MarkFailed(workCtx, unit.order.Id, unit.err.Error()); markErr != nil {
+			log.Printf("failed to mark bulk order item %d failed: %v", unit.order.Id, markErr)
+		}
+		unit.result.Status = models.BulkItemFailed
+		unit.result.Error = unit.err.Error()
+		failed = append(failed, unit)
+	}
+	if refunded, after := b.refundFailed(workCtx, bulk, failed); refunded > 0 {
+		bulk.RefundedAmount = roundAmount(refunded)
+		balanceAfter = after
+	}
+
+	fulfilled := len(units) - len(failed)
+	switch {
+	case len(failed) == 0:
+		bulk.Status = models.BulkOrderCompleted
+	case fulfilled == 0:
+		bulk.Status = models.BulkOrderFailed
+	default:
+		bulk.Status = models.BulkOrderPartial
+	}
+	now := time.Now()
+	bulk.CompletedAt = &now
+	if err := b.BulkRepo.Complete(workCtx, bulk); err != nil {
+		log.Printf("failed to complete bulk order %d: %v", bulk.Id, err)
+	}
+
+	result := &models.BulkOrderResult{
+		BulkOrder:    *bulk,
+		Fulfilled:    fulfilled,
+		BalanceAfter: balanceAfter,
+		Items:        make([]models.BulkOrderItemResult, 0, len(units)),
+	}
+	orderIds := make([]int, 0, len(units))
+	for _, unit := range units {
+		if unit.result.Status == models.BulkItemRefunded {
+			result.Refunded++
+		}
+		result.Items = append(result.Items, unit.result)
+		orderIds = append(orderIds, unit.order.Id)
+	}
+
+	err := b.AuditLogger.Record(workCtx, audit.Entry{
+		ActorUserId: userId,
+		Action:      AuditActionOrderBulkCreate,
+		TargetType:  audit.TargetBulkOrder,
+		TargetId:    strconv.Itoa(bulk.Id),
+		After:       bulk,
+		Metadata:    map[string]any{"order_ids": orderIds, "fulfilled": fulfilled, "refunded": result.Refunded},
+	})
+	if err != nil {
+		log.Printf("failed to write audit log %s for bulk order %d: %v", AuditActionOrderBulkCreate, bulk.Id, err)
+	}
+
+	if fulfilled > 0 {
+		if err := b.PriceTiers.EvaluateUpgrade(workCtx, userId); err != nil {
+			log.Printf("failed to evaluate price tier upgrade for user %d: %v", userId, err)
+		}
+	}
+	return result, nil
+}
+
+// fulfil membeli nomor di 5sim dengan paling banyak b.workers request bersamaan
+func (b *BulkOrderServiceImplementation) fulfil(ctx context.Context, units []*bulkUnit) {
+	jobs := make(chan *bulkUnit)
+	var wg sync.WaitGroup
+	for range min(b.workers, len(units)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for unit := range jobs {
+				result, err := b.OrderService.FulfillOrder(ctx, unit.order)
+				if err != nil {
+					unit.err = err
+					continue
+				}
+				unit.result.PhoneNumber = &result.Phone
+			}
+		}()
+	}
+
+	for _, unit := range units {
+		if unit.err == nil {
+			jobs <- unit
+		}
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// refundFailed mengembalikan harga tiap nomor yang gagal ke saldo, menandai order REFUNDED
+// dan mengirim webhook order.refunded. Item yang refund-nya gagal dibiarkan failed untuk di-refund admin.
+// Mengembalikan total yang di-refund dan saldo setelah refund terakhir.
+func (b *BulkOrderServiceImplementation) refundFailed(ctx context.Context, bulk *models.BulkOrder, failed []*bulkUnit) (float64, float64) {
+	refunded := 0.0
+	balanceAfter := 0.0
+	for _, unit := range failed {
+		// nomor masih aktif di 5sim tanpa catatan, admin yang memutuskan refund setelah dicek
+		if errors.Is(unit.err, ErrProviderOrderNotSaved) {
+			log.Printf("bulk order %d item %d not refunded automatically: %v", bulk.Id, unit.order.Id, unit.err)
+			continue
+		}
+		note := fmt.Sprintf("automatic refund for bulk order %d", bulk.Id)
+		transaction, err := refundOrderToWallet(ctx, b.BalanceRepo, unit.order, nil, note)
+		if err != nil {
+			log.Printf("REFUND FAILURE: bulk order %d item %d refund of %.2f failed: %v", bulk.Id, unit.order.Id, unit.order.PriceSell, err)
+			continue
+		}
+		if transaction != nil {
+			refunded += transaction.Amount
+			balanceAfter = transaction.BalanceAfter
+		}
+
+		reason := "automatic refund: " + unit.err.Error()
+		if _, err := b.OrderRepo.MarkRefunded(ctx, unit.order.Id, models.OrderSourceSystem, reason); err != nil {
+			log.Printf("failed to mark bulk order item %d refunded: %v", unit.order.Id, err)
+			continue
+		}
+		unit.result.Status = models.BulkItemRefunded
+
+		order, err := b.OrderRepo.GetById(ctx, unit.order.Id)
+		if err != nil || order == nil {
+			log.Printf("failed to read refunded order %d for webhook: %v", unit.order.Id, err)
+			continue
+		}
+		b.Webhooks.DispatchOrder(ctx, models.WebhookEventOrderRefunded, order)
+	}
+	return refunded, balanceAfter
+}
+
+// abort menggagalkan bulk order sebelum saldo dipotong
+func (b *BulkOrderServiceImplementation) abort(ctx context.Context, bulk *models.BulkOrder, units []*bulkUnit, reason string) {
+	for _, unit := range units {
+		if err := b.OrderRepo.MarkFailed(ctx, unit.order.Id, reason); err != nil {
+			log.Printf("failed to mark bulk order item %d failed: %v", unit.order.Id, err)
+		}
+	}
+	now := time.Now()
+	bulk.Status = models.BulkOrderFailed
+	bulk.CompletedAt = &now
+	if err := b.BulkRepo.Complete(ctx, bulk); err != nil {
+		log.Printf("failed to mark bulk order %d failed: %v", bulk.Id, err)
+	}
+}
+
+func bulkReference(bulkId int) string {
+	return "bulk_order:" + strconv.Itoa(bulkId)
+}
+
+func roundAmount(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,386 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/imnzr/sim-service-project/config"
+	"github.com/imnzr/sim-service-project/internal/audit"
+	"github.com/imnzr/sim-service-project/internal/repository"
+	"github.com/imnzr/sim-service-project/models"
+)
+
+// fakeBalanceRepo meniru ledger saldo: saldo tidak boleh negatif dan type + reference unik
+type fakeBalanceRepo struct {
+	mu           sync.Mutex
+	balances     map[uint]float64
+	transactions []*models.BalanceTransaction
+}
+
+func newFakeBalanceRepo(balances map[uint]float64) *fakeBalanceRepo {
+	return &fakeBalanceRepo{balances: balances}
+}
+
+func (f *fakeBalanceRepo) Apply(ctx context.Context, transaction *models.BalanceTransaction) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if transaction.Reference != nil {
+		for _, existing := range f.transactions {
+			if existing.Type == transaction.Type && existing.Reference != nil && *existing.Reference == *transaction.Reference {
+				return repository.ErrDuplicateEntry
+			}
+		}
+	}
+	after := f.balances[transaction.UserId] + transaction.Amount
+	if after < 0 {
+		return repository.ErrInsufficientBalance
+	}
+	f.balances[transaction.UserId] = after
+	transaction.Id = int64(len(f.transactions) + 1)
+	transaction.BalanceAfter = after
+	stored := *transaction
+	f.transactions = append(f.transactions, &stored)
+	return nil
+}
+
+func (f *fakeBalanceRepo) FindByUser(ctx context.Context, userId uint, limit int) ([]models.BalanceTransaction, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	transactions := []models.BalanceTransaction{}
+	for _, transaction := range f.transactions {
+		if transaction.UserId == userId {
+			transactions = append(transactions, *transaction)
+		}
+	}
+	return transactions, nil
+}
+
+func (f *fakeBalanceRepo) FindByReference(ctx context.Context, txType, reference string) (*models.BalanceTransaction, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, transaction := range f.transactions {
+		if transaction.Type == txType && transaction.Reference != nil && *transaction.Reference == reference {
+			copied := *transaction
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeBalanceRepo) byType(txType string) []*models.BalanceTransaction {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	found := []*models.BalanceTransaction{}
+	for _, transaction := range f.transactions {
+		if transaction.Type == txType {
+			found = append(found, transaction)
+		}
+	}
+	return found
+}
+
+type fakeBulkOrderRepo struct {
+	repository.BulkOrderRepository
+	completed *models.BulkOrder
+}
+
+func (f *fakeBulkOrderRepo) Create(ctx context.Context, bulk *models.BulkOrder) error {
+	bulk.Id = 7
+	return nil
+}
+
+func (f *fakeBulkOrderRepo) Complete(ctx context.Context, bulk *models.BulkOrder) error {
+	copied := *bulk
+	f.completed = &copied
+	return nil
+}
+
+type fakeSimOrderRepo struct {
+	repository.SimOrderRepository
+	mu     sync.Mutex
+	orders map[int]*models.SimOrder
+}
+
+func (f *fakeSimOrderRepo) Create(ctx context.Context, order *models.SimOrder) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	order.Id = len(f.orders) + 1
+	order.InvoiceId = fmt.Sprintf("INV-%d", order.Id)
+	copied := *order
+	f.orders[order.Id] = &copied
+	return order.Id, nil
+}
+
+func (f *fakeSimOrderRepo) UpdateStatusByInvoiceId(ctx context.Context, invoiceId, status string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, order := range f.orders {
+		if order.InvoiceId == invoiceId {
+			order.Status = status
+		}
+	}
+	return nil
+}
+
+func (f *fakeSimOrderRepo) MarkFailed(ctx context.Context, orderId int, reason string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.orders[orderId].Status = models.OrderStatusFailed
+	return nil
+}
+
+func (f *fakeSimOrderRepo) MarkRefunded(ctx context.Context, orderId int, source, reason string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	order := f.orders[orderId]
+	if order.Status == models.OrderStatusRefunded {
+		return false, nil
+	}
+	order.Status = models.OrderStatusRefunded
+	return true, nil
+}
+
+func (f *fakeSimOrderRepo) GetById(ctx context.Context, id int) (*models.SimOrder, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	order, ok := f.orders[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *order
+	return &copied, nil
+}
+
+// fakeQuoteService mengembalikan harga tetap per service
+type fakeQuoteService struct {
+	ProductService
+	prices map[string]float64
+}
+
+func (f *fakeQuoteService) QuotePrice(ctx context.Context, service, country, operator string) (*models.PriceQuote, error) {
+	price, ok := f.prices[service]
+	if !ok {
+		return nil, errors.New("product not found")
+	}
+	return &models.PriceQuote{Service: service, Country: country, Operator: operator, PriceSell: price, ExchangeRate: 200, ExchangeRateId: 1}, nil
+}
+
+type fakePriceTierService struct {
+	PriceTierService
+}
+
+func (f *fakePriceTierService) ApplyToQuote(ctx context.Context, userId uint, quote *models.PriceQuote) error {
+	return nil
+}
+
+func (f *fakePriceTierService) EvaluateUpgrade(ctx context.Context, userId uint) error {
+	return nil
+}
+
+// fakeFulfilService gagal membeli nomor untuk service yang ada di failures
+type fakeFulfilService struct {
+	OrderService
+	failures map[string]error
+}
+
+func (f *fakeFulfilService) FulfillOrder(ctx context.Context, order *models.SimOrder) (*models.ResponsOrderFromService, error) {
+	if err := f.failures[order.Service]; err != nil {
+		return nil, err
+	}
+	return &models.ResponsOrderFromService{Id: 1000 + order.Id, Phone: fmt.Sprintf("+7900000%04d", order.Id)}, nil
+}
+
+type fakeWebhookService struct {
+	WebhookService
+	mu     sync.Mutex
+	events []string
+}
+
+func (f *fakeWebhookService) DispatchOrder(ctx context.Context, event string, order *models.SimOrder) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.events = append(f.events, fmt.Sprintf("%s:%d", event, order.Id))
+}
+
+type fakeAuditLogger struct {
+	audit.Logger
+	mu      sync.Mutex
+	entries []audit.Entry
+	err     error
+}
+
+func (f *fakeAuditLogger) Record(ctx context.Context, entry audit.Entry) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+	f.entries = append(f.entries, entry)
+	return nil
+}
+
+type bulkOrderFixture struct {
+	service  *BulkOrderServiceImplementation
+	bulks    *fakeBulkOrderRepo
+	orders   *fakeSimOrderRepo
+	balances *fakeBalanceRepo
+	webhooks *fakeWebhookService
+}
+
+func newBulkOrderFixture(balance float64, failures map[string]error) *bulkOrderFixture {
+	fixture := &bulkOrderFixture{
+		bulks:    &fakeBulkOrderRepo{},
+		orders:   &fakeSimOrderRepo{orders: map[int]*models.SimOrder{}},
+		balances: newFakeBalanceRepo(map[uint]float64{1: balance}),
+		webhooks: &fakeWebhookService{},
+	}
+	products := &fakeQuoteService{prices: map[string]float64{"telegram": 5000, "whatsapp": 7500}}
+	cfg := config.AppConfig{BulkOrderMaxItems: 20, BulkOrderMaxQuantity: 100, BulkOrderWorkers: 3}
+	fixture.service = NewBulkOrderService(fixture.bulks, fixture.orders, fixture.balances, products, &fakePriceTierService{},
+		&fakeFulfilService{failures: failures}, fixture.webhooks, &fakeAuditLogger{}, cfg).(*BulkOrderServiceImplementation)
+	return fixture
+}
+
+func TestBulkOrderChargesWalletOnce(t *testing.T) {
+	fixture := newBulkOrderFixture(100000, nil)
+	req := &models.CreateBulkOrderRequest{Items: []models.BulkOrderItemRequest{
+		{Service: "telegram", Country: "russia", Operator: "any", Quantity: 3},
+		{Service: "whatsapp", Country: "india", Operator: "any", Quantity: 2},
+	}}
+
+	result, err := fixture.service.Create(context.Background(), 1, req)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	charges := fixture.balances.byType(models.BalanceTxOrderCharge)
+	if len(charges) != 1 {
+		t.Fatalf("expected exactly one wallet charge, got %d", len(charges))
+	}
+	if charges[0].Amount != -30000 || *charges[0].Reference != "bulk_order:7" {
+		t.Fatalf("unexpected charge: amount %v reference %q", charges[0].Amount, *charges[0].Reference)
+	}
+	if refunds := fixture.balances.byType(models.BalanceTxOrderRefund); len(refunds) != 0 {
+		t.Fatalf("expected no refunds, got %d", len(refunds))
+	}
+	if result.Fulfilled != 5 || result.BulkOrder.Status != models.BulkOrderCompleted || result.BalanceAfter != 70000 {
+		t.Fatalf("unexpected result: fulfilled %d status %s balance %v", result.Fulfilled, result.BulkOrder.Status, result.BalanceAfter)
+	}
+	for _, order := range fixture.orders.orders {
+		if order.Status != models.OrderStatusPaid {
+			t.Fatalf("order %d: expected PAID, got %s", order.Id, order.Status)
+		}
+	}
+}
+
+func TestBulkOrderInsufficientBalanceChargesNothing(t *testing.T) {
+	fixture := newBulkOrderFixture(10000, nil)
+	req := &models.CreateBulkOrderRequest{Items: []models.BulkOrderItemRequest{
+		{Service: "telegram", Country: "russia", Operator: "any", Quantity: 3},
+	}}
+
+	if _, err := fixture.service.Create(context.Background(), 1, req); !errors.Is(err, ErrWalletBalanceTooLow) {
+		t.Fatalf("expected ErrWalletBalanceTooLow, got %v", err)
+	}
+	if len(fixture.balances.transactions) != 0 || fixture.balances.balances[1] != 10000 {
+		t.Fatal("wallet must not be touched when the balance is too low")
+	}
+	if fixture.bulks.completed == nil || fixture.bulks.completed.Status != models.BulkOrderFailed {
+		t.Fatal("bulk order must be marked failed")
+	}
+	for _, order := range fixture.orders.orders {
+		if order.Status != models.OrderStatusFailed {
+			t.Fatalf("order %d: expected FAILED, got %s", order.Id, order.Status)
+		}
+	}
+}
+
+func TestBulkOrderRefundsFailedItems(t *testing.T) {
+	fixture := newBulkOrderFixture(100000, map[string]error{
+		"whatsapp": errors.New("no free phones"),
+	})
+	req := &models.CreateBulkOrderRequest{Items: []models.BulkOrderItemRequest{
+		{Service: "telegram", Country: "russia", Operator: "any", Quantity: 2},
+		{Service: "whatsapp", Country: "india", Operator: "any", Quantity: 2},
+	}}
+
+	result, err := fixture.service.Create(context.Background(), 1, req)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	refunds := fixture.balances.byType(models.BalanceTxOrderRefund)
+	if len(refunds) != 2 {
+		t.Fatalf("expected one refund per failed order, got %d", len(refunds))
+	}
+	for _, refund := range refunds {
+		if refund.Amount != 7500 {
+			t.Fatalf("refund must equal the order price, got %v", refund.Amount)
+		}
+	}
+	if fixture.balances.balances[1] != 90000 {
+		t.Fatalf("expected balance 90000 after refunds, got %v", fixture.balances.balances[1])
+	}
+	if result.BulkOrder.Status != models.BulkOrderPartial || result.Refunded != 2 || result.BulkOrder.RefundedAmount != 15000 {
+		t.Fatalf("unexpected result: status %s refunded %d amount %v", result.BulkOrder.Status, result.Refunded, result.BulkOrder.RefundedAmount)
+	}
+	for _, item := range result.Items {
+		want := models.BulkItemFulfilled
+		if item.Service == "whatsapp" {
+			want = models.BulkItemRefunded
+		}
+		if item.Status != want {
+			t.Fatalf("order %d: expected item status %s, got %s", item.OrderId, want, item.Status)
+		}
+	}
+	if len(fixture.webhooks.events) != 2 {
+		t.Fatalf("expected two order.refunded webhooks, got %v", fixture.webhooks.events)
+	}
+}
+
+func TestBulkOrderDoesNotRefundUnsavedProviderOrders(t *testing.T) {
+	fixture := newBulkOrderFixture(100000, map[string]error{
+		"telegram": fmt.Errorf("order 1: %w", ErrProviderOrderNotSaved),
+	})
+	req := &models.CreateBulkOrderRequest{Items: []models.BulkOrderItemRequest{
+		{Service: "telegram", Country: "russia", Operator: "any", Quantity: 1},
+	}}
+
+	result, err := fixture.service.Create(context.Background(), 1, req)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if refunds := fixture.balances.byType(models.BalanceTxOrderRefund); len(refunds) != 0 {
+		t.Fatalf("number still active at 5sim must not be refunded automatically, got %d refunds", len(refunds))
+	}
+	if result.BulkOrder.Status != models.BulkOrderFailed || result.Items[0].Status != models.BulkItemFailed {
+		t.Fatalf("unexpected result: status %s item %s", result.BulkOrder.Status, result.Items[0].Status)
+	}
+}
+
+func TestRefundOrderToWalletIsIdempotent(t *testing.T) {
+	balances := newFakeBalanceRepo(map[uint]float64{1: 0})
+	order := &models.SimOrder{Id: 42, UserId: 1, PriceSell: 5000}
+	ctx := context.Background()
+
+	first, err := refundOrderToWallet(ctx, balances, order, nil, "bulk refund")
+	if err != nil || first == nil {
+		t.Fatalf("first refund: transaction %v, err %v", first, err)
+	}
+
+	// refund admin untuk order yang sama setelah refund otomatis
+	actorId := uint(99)
+	second, err := refundOrderToWallet(ctx, balances, order, &actorId, "manual refund")
+	if err != nil {
+		t.Fatalf("second refund must not fail: %v", err)
+	}
+	if second != nil {
+		t.Fatal("second refund must not record a transaction")
+	}
+	if balances.balances[1] != 5000 || len(balances.transactions) != 1 {
+		t.Fatalf("order refunded twice: balance %v, %d transactions", balances.balances[1], len(balances.transactions))
+	}
+}
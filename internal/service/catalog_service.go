@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/imnzr/sim-service-project/internal/repository"
+	"github.com/imnzr/sim-service-project/models"
+)
+
+const (
+	defaultCatalogLimit = 20
+	maxCatalogLimit     = 100
+)
+
+var ErrProductNotFound = errors.New("product not found")
+
+type CatalogService interface {
+	ListCountries(ctx context.Context, query models.CatalogQuery) (*models.CatalogPage[models.CatalogCountry], error)
+	ListServices(ctx context.Context, country string, query models.CatalogQuery) (*models.CatalogPage[models.CatalogService], error)
+	ListOperators(ctx context.Context, country, service string, query models.CatalogQuery) (*models.CatalogPage[models.CatalogOperator], error)
+	SearchProducts(ctx context.Context, query models.CatalogQuery) (*models.CatalogPage[models.CatalogProduct], error)
+	AvailabilityTrend(ctx context.Context, service, country, operator string, window time.Duration) (*models.AvailabilityTrend, error)
+}
+
+type CatalogServiceImplementation struct {
+	Repo        repository.CatalogRepository
+	ProductRepo repository.ProductRepository
+	StockRepo   repository.ProductStockRepository
+	PriceTiers  PriceTierService
+}
+
+func NewCatalogService(repo repository.CatalogRepository, productRepo repository.ProductRepository, stockRepo repository.ProductStockRepository, priceTiers PriceTierService) CatalogService {
+	return &CatalogServiceImplementation{
+		Repo:        repo,
+		ProductRepo: productRepo,
+		StockRepo:   stockRepo,
+		PriceTiers:  priceTiers,
+	}
+}
+
+// callerTier mengambil tier caller katalog. Katalog tetap tampil dengan harga retail jika tier gagal dimuat.
+func (c *CatalogServiceImplementation) callerTier(ctx context.Context, query models.CatalogQuery) *models.PriceTier {
+	if query.UserId == 0 {
+		return nil
+	}
+	tier, err := c.PriceTiers.TierForUser(ctx, query.UserId)
+	if err != nil {
+		log.Printf("failed to load price tier for user %d: %v", query.UserId, err)
+		return nil
+	}
+	if tier == nil || tier.DiscountPercent <= 0 {
+		return nil
+	}
+	return tier
+}
+
+func effectivePrice(tier *models.PriceTier, price float64) *float64 {
+	if tier == nil {
+		return nil
+	}
+	discounted := tier.ApplyDiscount(price)
+	return &discounted
+}
+
+// normalizeCatalogQuery memberi nilai default page/limit dan membatasi limit
+func normalizeCatalogQuery(query models.CatalogQuery) models.CatalogQuery {
+	query.Search = strings.TrimSpace(query.Search)
+	query.Sort = strings.ToLower(query.Sort)
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.Limit < 1 {
+		query.Limit = defaultCatalogLimit
+	}
+	if query.Limit > maxCatalogLimit {
+		query.Limit = maxCatalogLimit
+	}
+	return query
+}
+
+// ListCountries implements CatalogService.
+func (c *CatalogServiceImplementation) ListCountries(ctx context.Context, query models.CatalogQuery) (*models.CatalogPage[models.CatalogCountry], error) {
+	query = normalizeCatalogQuery(query)
+
+	items, total, err := c.Repo.ListCountries(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if tier := c.callerTier(ctx, query); tier != nil {
+		for i := range items {
+			items[i].EffectiveMinPrice = effectivePrice(tier, items[i].MinPrice)
+		}
+	}
+	return &models.CatalogPage[models.CatalogCountry]{Items: items, Page: query.Page, Limit: query.Limit, Total: total}, nil
+}
+
+// ListServices implements CatalogService.
+func (c *CatalogServiceImplementation) ListServices(ctx context.Context, country string, query models.CatalogQuery) (*models.CatalogPage[models.CatalogService], error) {
+	query = normalizeCatalogQuery(query)
+
+	items, total, err := c.Repo.ListServices(ctx, country, query)
+	if err != nil {
+		return nil, err
+	}
+	if tier := c.callerTier(ctx, query); tier != nil {
+		for i := range items {
+			items[i].EffectiveMinPrice = effectivePrice(tier, items[i].MinPrice)
+		}
+	}
+	return &models.CatalogPage[models.CatalogService]{Items: items, Page: query.Page, Limit: query.Limit, Total: total}, nil
+}
+
+// ListOperators implements CatalogService.
+func (c *CatalogServiceImplementation) ListOperators(ctx context.Context, country string, service string, query models.CatalogQuery) (*models.CatalogPage[models.CatalogOperator], error) {
+	query = normalizeCatalogQuery(query)
+
+	items, total, err := c.Repo.ListOperators(ctx, country, service, query)
+	if err != nil {
+		return nil, err
+	}
+	if tier := c.callerTier(ctx, query); tier != nil {
+		for i := range items {
+			items[i].EffectivePrice = effectivePrice(tier, items[i].PriceSell)
+		}
+	}
+	return &models.CatalogPage[models.CatalogOperator]{Items: items, Page: query.Page, Limit: query.Limit, Total: total}, nil
+}
+
+// SearchProducts implements CatalogService.
+func (c *CatalogServiceImplementation) SearchProducts(ctx context.Context, query models.CatalogQuery) (*models.CatalogPage[models.CatalogProduct], error) {
+	query = normalizeCatalogQuery(query)
+
+	items, total, err := c.Repo.SearchProducts(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if tier := c.callerTier(ctx, query); tier != nil {
+		for i := range items {
+			items[i].EffectivePrice = effectivePrice(tier, items[i].PriceSell)
+		}
+	}
+	return &models.CatalogPage[models.CatalogProduct]{Items: items, Page: query.Page, Limit: query.Limit, Total: total}, nil
+}
+
+// AvailabilityTrend implements CatalogService.
+func (c *CatalogServiceImplementation) AvailabilityTrend(ctx context.Context, service string, country string, operator string, window time.Duration) (*models.AvailabilityTrend, error) {
+	product, err := c.ProductRepo.FindByKey(ctx, service, country, operator)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product: %w", err)
+	}
+	if product == nil {
+		return nil, fmt.Errorf("%w: %s/%s/%s", ErrProductNotFound, service, country, operator)
+	}
+
+	since := time.Now().Add(-window)
+	points, err := c.StockRepo.Trend(ctx, service, country, operator, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load stock trend: %w", err)
+	}
+	ratio, err := c.StockRepo.InStockRatio(ctx, service, country, operator, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load stock ratio: %w", err)
+	}
+
+	currentStock := product.Stock
+	if !product.Available {
+		currentStock = 0
+	}
+	return &models.AvailabilityTrend{
+		Service:      service,
+		Country:      country,
+		Operator:     operator,
+		CurrentStock: currentStock,
+		Since:        since,
+		InStockRatio: ratio,
+		Points:       points,
+	}, nil
+}
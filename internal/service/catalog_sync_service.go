@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/imnzr/sim-service-project/config"
+	"github.com/imnzr/sim-service-project/internal/lock"
+	"github.com/imnzr/sim-service-project/internal/repository"
+	"github.com/imnzr/sim-service-project/models"
+)
+
+const (
+	SyncTriggerScheduled = "scheduled"
+	SyncTriggerManual    = "manual"
+
+	SyncStatusRunning = "running"
+	SyncStatusSuccess = "success"
+	SyncStatusFailed  = "failed"
+
+	catalogSyncLockKey = "lock:catalog_sync"
+)
+
+// ErrSyncInProgress dikembalikan jika sync sedang berjalan di instance lain
+var ErrSyncInProgress = errors.New("catalog sync is already running")
+
+type CatalogSyncService interface {
+	Run(ctx context.Context, trigger string) (*models.SyncRun, error)
+	LatestRun(ctx context.Context) (*models.SyncRun, error)
+}
+
+type CatalogSyncServiceImplementation struct {
+	ProductService ProductService
+	SyncRunRepo    repository.SyncRunRepository
+	StockRepo      repository.ProductStockRepository
+	Lock           *lock.RedisLock
+	lockTTL        time.Duration
+	stockRetention time.Duration
+}
+
+func NewCatalogSyncService(productService ProductService, syncRunRepo repository.SyncRunRepository, stockRepo repository.ProductStockRepository, redisLock *lock.RedisLock, cfg config.AppConfig) CatalogSyncService {
+	return &CatalogSyncServiceImplementation{
+		ProductService: productService,
+		SyncRunRepo:    syncRunRepo,
+		StockRepo:      stockRepo,
+		Lock:           redisLock,
+		lockTTL:        cfg.CatalogSyncLockTTL,
+		stockRetention: cfg.StockHistoryRetention,
+	}
+}
+
+// Run implements CatalogSyncService.
+// Hanya satu instance yang boleh sync dalam satu waktu; setiap run dicatat di tabel sync_runs.
+func (c *CatalogSyncServiceImplementation) Run(ctx context.Context, trigger string) (*models.SyncRun, error) {
+	token, err := c.Lock.Acquire(ctx, catalogSyncLockKey, c.lockTTL)
+	if err != nil {
+		if errors.Is(err, lock.ErrNotAcquired) {
+			return nil, ErrSyncInProgress
+		}
+		return nil, fmt.Errorf("failed to acquire catalog sync lock: %w", err)
+	}
+	defer func() {
+		if err := c.Lock.Release(context.Background(), catalogSyncLockKey, token); err != nil {
+			log.Printf("failed to release catalog sync lock: %v", err)
+		}
+	}()
+
+	// sync tidak boleh berjalan lebih lama dari TTL lock
+	ctx, cancel := context.WithTimeout(ctx, c.lockTTL)
+	defer cancel()
+
+	run := &models.SyncRun{
+		Trigger:   trigger,
+		Status:    SyncStatusRunning,
+		StartedAt: time.Now(),
+	}
+	if err := c.SyncRunRepo.Start(ctx, run); err != nil {
+		return nil, fmt.Errorf("failed to record sync run: %w", err)
+	}
+
+	summary, syncErr := c.ProductService.SyncFromSimServices(ctx)
+
+	finishedAt := time.Now()
+	run.FinishedAt = &finishedAt
+	if syncErr != nil {
+		message := syncErr.Error()
+		run.Status = SyncStatusFailed
+		run.ErrorMessage = &message
+	} else {
+		run.Status = SyncStatusSuccess
+		run.Added = summary.Added
+		run.Updated = summary.Updated
+		run.Removed = summary.Removed
+		run.Unchanged = summary.Unchanged
+		run.Errors = summary.Errors
+	}
+
+	// pakai context baru agar hasil tetap tercatat walaupun ctx sync sudah timeout
+	if err := c.SyncRunRepo.Finish(context.Background(), run); err != nil {
+		log.Printf("failed to record sync run %d result: %v", run.Id, err)
+	}
+
+	if syncErr != nil {
+		return run, syncErr
+	}
+
+	// snapshot stok lama dibuang agar tabel tidak tumbuh tanpa batas
+	if c.stockRetention > 0 {
+		deleted, err := c.StockRepo.DeleteBefore(context.Background(), time.Now().Add(-c.stockRetention))
+		if err != nil {
+			log.Printf("failed to prune stock snapshots: %v", err)
+		} else if deleted > 0 {
+			log.Printf("pruned %d stock snapshots", deleted)
+		}
+	}
+	return run, nil
+}
+
+// LatestRun implements CatalogSyncService.
+func (c *CatalogSyncServiceImplementation) LatestRun(ctx context.Context) (*models.SyncRun, error) {
+	return c.SyncRunRepo.GetLatest(ctx)
+}
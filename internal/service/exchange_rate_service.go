@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/imnzr/sim-service-project/config"
+	exchangerate "github.com/imnzr/sim-service-project/internal/exchange_rate"
+	"github.com/imnzr/sim-service-project/internal/repository"
+	"github.com/imnzr/sim-service-project/models"
+)
+
+const (
+	CurrencyRUB = "RUB"
+	CurrencyIDR = "IDR"
+)
+
+var ErrUnsupportedCurrencyPair = errors.New("only RUB/IDR exchange rate is supported")
+
+// SupportedCurrencyPair: harga katalog hanya memakai kurs RUB ke IDR, pasangan lain
+// tidak perlu diambil dari source maupun disimpan.
+func SupportedCurrencyPair(base, quote string) bool {
+	return base == CurrencyRUB && quote == CurrencyIDR
+}
+
+type ExchangeRateService interface {
+	CurrentRate(ctx context.Context, base, quote string) (*models.ExchangeRate, error)
+	Refresh(ctx context.Context, base, quote string) (*models.ExchangeRate, error)
+	SetManualRate(ctx context.Context, req *models.ManualExchangeRateRequest) (*models.ExchangeRate, error)
+	ClearManualRate(ctx context.Context, base, quote string) error
+}
+
+type ExchangeRateServiceImplementation struct {
+	Repo   repository.ExchangeRateRepository
+	Source exchangerate.RateSource
+	ttl    time.Duration
+}
+
+func NewExchangeRateService(repo repository.ExchangeRateRepository, source exchangerate.RateSource, cfg config.AppConfig) ExchangeRateService {
+	return &ExchangeRateServiceImplementation{
+		Repo:   repo,
+		Source: source,
+		ttl:    cfg.ExchangeRateTTL,
+	}
+}
+
+// CurrentRate implements ExchangeRateService.
+// Urutan: override manual aktif, kurs tersimpan yang belum kadaluarsa, ambil ulang dari source,
+// dan terakhir kurs tersimpan yang sudah kadaluarsa jika source sedang gagal.
+func (e *ExchangeRateServiceImplementation) CurrentRate(ctx context.Context, base string, quote string) (*models.ExchangeRate, error) {
+	override, err := e.Repo.GetActiveOverride(ctx, base, quote)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get manual exchange rate: %w", err)
+	}
+	if override != nil {
+		return override, nil
+	}
+
+	cached, err := e.Repo.GetLatest(ctx, base, quote)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cached exchange rate: %w", err)
+	}
+	if cached != nil && time.Since(cached.FetchedAt) < e.ttl {
+		return cached, nil
+	}
+
+	fresh, err := e.Refresh(ctx, base, quote)
+	if err != nil {
+		if cached != nil {
+			log.Printf("exchange rate refresh failed, using stale rate from %s: %v", cached.FetchedAt, err)
+			return cached, nil
+		}
+		return nil, err
+	}
+	return fresh, nil
+}
+
+// Refresh implements ExchangeRateService.
+func (e *ExchangeRateServiceImplementation) Refresh(ctx context.Context, base string, quote string) (*models.ExchangeRate, error) {
+	if e.Source == nil {
+		return nil, errors.New("exchange rate source is not configured")
+	}
+
+	value, err := e.Source.FetchRate(ctx, base, quote)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch exchange rate from %s: %w", e.Source.Name(), err)
+	}
+
+	rate := &models.ExchangeRate{
+		BaseCurrency:  base,
+		QuoteCurrency: quote,
+		Rate:          value,
+		Source:        e.Source.Name(),
+		FetchedAt:     time.Now(),
+	}
+	if err := e.Repo.Save(ctx, rate); err != nil {
+		return nil, fmt.Errorf("failed to save exchange rate: %w", err)
+	}
+
+	log.Printf("exchange rate %s/%s refreshed from %s: %f", base, quote, rate.Source, rate.Rate)
+	return rate, nil
+}
+
+// SetManualRate implements ExchangeRateService.
+func (e *ExchangeRateServiceImplementation) SetManualRate(ctx context.Context, req *models.ManualExchangeRateRequest) (*models.ExchangeRate, error) {
+	base := strings.ToUpper(req.BaseCurrency)
+	quote := strings.ToUpper(req.QuoteCurrency)
+	if base == "" || quote == "" {
+		return nil, errors.New("base_currency and quote_currency are required")
+	}
+	if !SupportedCurrencyPair(base, quote) {
+		return nil, ErrUnsupportedCurrencyPair
+	}
+	if req.Rate <= 0 {
+		return nil, errors.New("rate must be greater than zero")
+	}
+
+	rate := &models.ExchangeRate{
+		BaseCurrency:  base,
+		QuoteCurrency: quote,
+		Rate:          req.Rate,
+		Source:        "manual",
+		FetchedAt:     time.Now(),
+	}
+	if err := e.Repo.SetOverride(ctx, rate); err != nil {
+		return nil, fmt.Errorf("failed to save manual exchange rate: %w", err)
+	}
+	return rate, nil
+}
+
+// ClearManualRate implements ExchangeRateService.
+func (e *ExchangeRateServiceImplementation) ClearManualRate(ctx context.Context, base string, quote string) error {
+	return e.Repo.ClearOverride(ctx, strings.ToUpper(base), strings.ToUpper(quote))
+}
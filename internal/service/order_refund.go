@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/imnzr/sim-service-project/internal/repository"
+	"github.com/imnzr/sim-service-project/models"
+)
+
+// orderRefundReference unik per order sehingga refund ke saldo tidak bisa tercatat dua kali
+func orderRefundReference(orderId int) string {
+	return "order:" + strconv.Itoa(orderId)
+}
+
+// refundOrderToWallet mengembalikan harga order yang dibayar dengan saldo.
+// Aman dipanggil ulang: jika order sudah pernah di-refund ke saldo, tidak ada yang dicatat
+// dan hasilnya (nil, nil).
+func refundOrderToWallet(ctx context.Context, balanceRepo repository.BalanceRepository, order *models.SimOrder, actorId *uint, note string) (*models.BalanceTransaction, error) {
+	reference := orderRefundReference(order.Id)
+	transaction := &models.BalanceTransaction{
+		UserId:      uint(order.UserId),
+		Type:        models.BalanceTxOrderRefund,
+		Amount:      order.PriceSell,
+		Note:        &note,
+		Reference:   &reference,
+		ActorUserId: actorId,
+	}
+	if err := balanceRepo.Apply(ctx, transaction); err != nil {
+		if errors.Is(err, repository.ErrDuplicateEntry) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to refund order %d to wallet: %w", order.Id, err)
+	}
+	return transaction, nil
+}
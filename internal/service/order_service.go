@@ -4,12 +4,14 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/imnzr/sim-service-project/config"
 	"github.com/imnzr/sim-service-project/internal/repository"
@@ -17,9 +19,36 @@ import (
 	"github.com/imnzr/sim-service-project/utils"
 )
 
+// Aksi ke 5sim yang response-nya disimpan di sim_order_provider_responses
+const (
+	ProviderFiveSim      = "5sim"
+	ProviderActionBuy    = "buy"
+	ProviderActionCheck  = "check"
+	ProviderActionCancel = "cancel"
+)
+
+// status 5sim yang berarti aktivasi batal, dikirim ke reseller sebagai order.cancelled
+var cancelledProviderStatuses = map[string]bool{
+	"CANCELED": true,
+	"TIMEOUT":  true,
+	"BANNED":   true,
+}
+
+// ErrNoProviderOrder dikembalikan jika order belum punya nomor dari 5sim
+var ErrNoProviderOrder = errors.New("order has no 5sim activation yet")
+
+// ErrProviderOrderNotSaved: nomor sudah terbeli di 5sim tetapi gagal disimpan dan gagal dibatalkan.
+// Order seperti ini tidak boleh di-refund otomatis karena nomornya tetap ditagih 5sim, perlu dicek admin.
+var ErrProviderOrderNotSaved = errors.New("5sim number was bought but could not be saved or cancelled")
+
 type OrderService interface {
 	BuyNumberFromService(ctx context.Context, service, country, operator string) (*models.ResponsOrderFromService, error)
 	CheckSimOrderStatus(ctx context.Context, orderId int) (*models.ResponsOrderFromService, error)
+	CancelSimOrder(ctx context.Context, orderId int) (*models.ResponsOrderFromService, error)
+	FulfillOrder(ctx context.Context, order *models.SimOrder) (*models.ResponsOrderFromService, error)
+	RecheckOrder(ctx context.Context, order *models.SimOrder) (*models.ResponsOrderFromService, error)
+	CancelOrder(ctx context.Context, order *models.SimOrder) (*models.ResponsOrderFromService, error)
+	RecheckActiveOrders(ctx context.Context) (int, error)
 	// CheckOrderStatus(ctx context.Context, orderId int) (*models.ResponsOrderFromService, error)
 }
 
@@ -27,15 +56,27 @@ type OrderServiceImplementation struct {
 	simOrderRepo repository.SimOrderRepository
 	DB           *sql.DB
 	Config       config.AppConfig
+	Webhooks     WebhookService
 }
 
-func NewOrderService(simOrderRepo repository.SimOrderRepository, db *sql.DB) OrderService {
+func NewOrderService(simOrderRepo repository.SimOrderRepository, db *sql.DB, webhooks WebhookService) OrderService {
 	return &OrderServiceImplementation{
 		simOrderRepo: simOrderRepo,
 		DB:           db,
+		Webhooks:     webhooks,
 	}
 }
 
+// notify mengirim event order ke webhook reseller dengan data order terbaru
+func (o *OrderServiceImplementation) notify(ctx context.Context, event string, orderId int) {
+	order, err := o.simOrderRepo.GetById(ctx, orderId)
+	if err != nil || order == nil {
+		log.Printf("failed to read order %d for webhook %s: %v", orderId, event, err)
+		return
+	}
+	o.Webhooks.DispatchOrder(ctx, event, order)
+}
+
 // CheckOrderStatus implements OrderService.
 // func (o *OrderServiceImplementation) CheckOrderStatus(ctx context.Context, orderId int) (*models.ResponsOrderFromService, error) {
 // 	order, err := o.simOrderRepo.GetById(ctx, orderId)
@@ -49,72 +90,204 @@ func NewOrderService(simOrderRepo repository.SimOrderRepository, db *sql.DB) Ord
 // 	return o.CheckSimOrderStatus(ctx, order.SimOrderServiceId)
 // }
 
-// CheckSimOrderStatus implements OrderService.
-func (o *OrderServiceImplementation) CheckSimOrderStatus(ctx context.Context, orderId int) (*models.ResponsOrderFromService, error) {
+// providerError membawa body mentah dari 5sim agar tetap bisa disimpan walaupun request gagal
+type providerError struct {
+	body string
+}
+
+func (p *providerError) Error() string {
+	return fmt.Sprintf("service error: %s", p.body)
+}
+
+// callSimService memanggil endpoint user 5sim dan mendecode response JSON-nya.
+// 5sim mengembalikan plain text untuk error, sehingga body non-JSON dianggap gagal.
+func (o *OrderServiceImplementation) callSimService(ctx context.Context, path string) (*models.ResponsOrderFromService, error) {
 	client := http.Client{}
-	url := fmt.Sprintf("%s/user/check/%d", os.Getenv("SIM_SERVICE_URL"), orderId)
+	url := os.Getenv("SERVICE_API_URL") + path
 
 	req, err := utils.NewRequestSIM("GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
-
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", o.Config.SimServiceAPIKey))
-	resp, err := client.Do(req)
+	resp, err := client.Do(req.WithContext(ctx))
 	if err != nil {
 		return nil, err
 	}
-
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("service error: %s", body)
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	bodyStr := strings.TrimSpace(string(bodyBytes))
+
+	// ✅ Jika bukan JSON (biasanya plain text = error)
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(bodyStr, "{") {
+		return nil, &providerError{body: bodyStr}
 	}
 
 	var result models.ResponsOrderFromService
+	if err := json.Unmarshal(bodyBytes, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode JSON response: %w", err)
+	}
+	result.RawBody = bodyStr
+
+	return &result, nil
+}
 
-	errResult := json.NewDecoder(resp.Body).Decode(&result)
-	if errResult != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", errResult)
+// CheckSimOrderStatus implements OrderService.
+func (o *OrderServiceImplementation) CheckSimOrderStatus(ctx context.Context, orderId int) (*models.ResponsOrderFromService, error) {
+	result, err := o.callSimService(ctx, fmt.Sprintf("/user/check/%d", orderId))
+	if err != nil {
+		return nil, err
 	}
 	if result.Id == 0 {
 		return nil, fmt.Errorf("order not found or invalid order ID")
 	}
-	return &result, nil
+	return result, nil
+}
+
+// CancelSimOrder implements OrderService.
+func (o *OrderServiceImplementation) CancelSimOrder(ctx context.Context, orderId int) (*models.ResponsOrderFromService, error) {
+	return o.callSimService(ctx, fmt.Sprintf("/user/cancel/%d", orderId))
 }
 
 func (o *OrderServiceImplementation) BuyNumberFromService(ctx context.Context, service, country, operator string) (*models.ResponsOrderFromService, error) {
-	client := http.Client{}
-	url := fmt.Sprintf("%s/user/buy/activation/%s/%s/%s", os.Getenv("SERVICE_API_URL"), country, operator, service)
+	return o.callSimService(ctx, fmt.Sprintf("/user/buy/activation/%s/%s/%s", country, operator, service))
+}
 
-	req, err := utils.NewRequestSIM("GET", url, nil)
+// recordProviderResponse menyimpan response mentah 5sim, kegagalan hanya di-log
+func (o *OrderServiceImplementation) recordProviderResponse(ctx context.Context, orderId int, action string, result *models.ResponsOrderFromService, callErr error) {
+	response := &models.OrderProviderResponse{
+		OrderId:  orderId,
+		Provider: ProviderFiveSim,
+		Action:   action,
+		Success:  callErr == nil,
+	}
+
+	var providerErr *providerError
+	switch {
+	case callErr == nil:
+		response.Body = result.RawBody
+	case errors.As(callErr, &providerErr):
+		response.Body = providerErr.body
+	default:
+		response.Body = callErr.Error()
+	}
+
+	if err := o.simOrderRepo.RecordProviderResponse(ctx, response); err != nil {
+		log.Printf("failed to record 5sim %s response for order %d: %v", action, orderId, err)
+	}
+}
+
+// FulfillOrder implements OrderService.
+// Membeli nomor di 5sim untuk order yang sudah dibayar lalu menyimpan hasilnya.
+// Jika nomor gagal disimpan, aktivasi dibatalkan agar tidak ada nomor terbeli tanpa catatan.
+func (o *OrderServiceImplementation) FulfillOrder(ctx context.Context, order *models.SimOrder) (*models.ResponsOrderFromService, error) {
+	result, err := o.BuyNumberFromService(ctx, order.Service, order.Country, order.Operator)
+	o.recordProviderResponse(ctx, order.Id, ProviderActionBuy, result, err)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := client.Do(req)
+
+	if err := o.simOrderRepo.AttachSimDataService(ctx, order.Id, result); err != nil {
+		cancelResult, cancelErr := o.CancelSimOrder(ctx, result.Id)
+		o.recordProviderResponse(ctx, order.Id, ProviderActionCancel, cancelResult, cancelErr)
+		if cancelErr != nil {
+			log.Printf("5sim activation %d for order %d was bought but not saved and could not be cancelled: %v", result.Id, order.Id, cancelErr)
+			return nil, fmt.Errorf("%w: activation %d: %v", ErrProviderOrderNotSaved, result.Id, err)
+		}
+		return nil, fmt.Errorf("failed to save 5sim order, activation %d cancelled: %w", result.Id, err)
+	}
+	o.notify(ctx, models.WebhookEventOrderNumberAssigned, order.Id)
+	return result, nil
+}
+
+// RecheckOrder implements OrderService.
+// Mengambil status terbaru dari 5sim dan menyimpan kode OTP terakhir jika sudah ada SMS masuk.
+func (o *OrderServiceImplementation) RecheckOrder(ctx context.Context, order *models.SimOrder) (*models.ResponsOrderFromService, error) {
+	return o.recheck(ctx, order, true)
+}
+
+// batas order yang dicek per putaran RecheckActiveOrders dan umur order yang masih dicek.
+// Aktivasi 5sim kedaluwarsa jauh sebelum activeOrderMaxAge, setelah itu admin bisa recheck manual.
+const (
+	activeOrderBatchSize = 100
+	activeOrderMaxAge    = 2 * time.Hour
+)
+
+// RecheckActiveOrders implements OrderService.
+// Dipanggil scheduler agar SMS yang masuk dan pembatalan dari 5sim sampai ke reseller tanpa recheck manual.
+func (o *OrderServiceImplementation) RecheckActiveOrders(ctx context.Context) (int, error) {
+	orders, err := o.simOrderRepo.FindActiveProviderOrders(ctx, time.Now().Add(-activeOrderMaxAge), activeOrderBatchSize)
 	if err != nil {
-		return nil, err
+		return 0, fmt.Errorf("failed to load active orders: %w", err)
 	}
-	defer resp.Body.Close()
 
-	bodyBytes, err := io.ReadAll(resp.Body)
+	checked := 0
+	for i := range orders {
+		if ctx.Err() != nil {
+			return checked, ctx.Err()
+		}
+		if _, err := o.recheck(ctx, &orders[i], false); err != nil {
+			log.Printf("failed to re-check order %d with 5sim: %v", orders[i].Id, err)
+			continue
+		}
+		checked++
+	}
+	return checked, nil
+}
+
+// recheck menyamakan order dengan 5sim. Recheck berkala (recordAlways false) hanya menyimpan
+// response 5sim jika gagal atau ada perubahan, supaya tabel response tidak terisi polling.
+func (o *OrderServiceImplementation) recheck(ctx context.Context, order *models.SimOrder, recordAlways bool) (*models.ResponsOrderFromService, error) {
+	if order.SimOrderServiceId == nil {
+		return nil, ErrNoProviderOrder
+	}
+
+	result, err := o.CheckSimOrderStatus(ctx, *order.SimOrderServiceId)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		o.recordProviderResponse(ctx, order.Id, ProviderActionCheck, result, err)
+		return nil, err
 	}
 
-	bodyStr := strings.TrimSpace(string(bodyBytes))
-	log.Println("📦 Response body:", bodyStr)
+	var otp *string
+	if len(result.SMS) > 0 {
+		code := result.SMS[len(result.SMS)-1].Code
+		if code != "" {
+			otp = &code
+		}
+	}
+	otpChanged := otp != nil && (order.OTP == nil || *order.OTP != *otp)
+	if recordAlways || otpChanged || order.Status != result.Status {
+		o.recordProviderResponse(ctx, order.Id, ProviderActionCheck, result, nil)
+	}
+	if err := o.simOrderRepo.UpdateFromProvider(ctx, order.Id, result.Status, otp); err != nil {
+		return nil, fmt.Errorf("failed to update order from 5sim: %w", err)
+	}
 
-	// ✅ Jika bukan JSON (biasanya plain text = error)
-	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(bodyStr, "{") {
-		return nil, fmt.Errorf("service error: %s", bodyStr)
+	// event hanya dikirim saat ada perubahan, re-check berulang tidak mengirim event yang sama lagi
+	if otpChanged {
+		o.notify(ctx, models.WebhookEventOrderSMSReceived, order.Id)
 	}
+	if cancelledProviderStatuses[result.Status] && order.Status != result.Status && order.RefundedAt == nil {
+		o.notify(ctx, models.WebhookEventOrderCancelled, order.Id)
+	}
+	return result, nil
+}
 
-	var result models.ResponsOrderFromService
-	if err := json.Unmarshal(bodyBytes, &result); err != nil {
-		return nil, fmt.Errorf("failed to decode JSON response: %w", err)
+// CancelOrder implements OrderService.
+// Membatalkan aktivasi di 5sim (saldo 5sim dikembalikan jika belum ada SMS).
+func (o *OrderServiceImplementation) CancelOrder(ctx context.Context, order *models.SimOrder) (*models.ResponsOrderFromService, error) {
+	if order.SimOrderServiceId == nil {
+		return nil, ErrNoProviderOrder
 	}
 
-	return &result, nil
+	result, err := o.CancelSimOrder(ctx, *order.SimOrderServiceId)
+	o.recordProviderResponse(ctx, order.Id, ProviderActionCancel, result, err)
+	if err == nil {
+		o.notify(ctx, models.WebhookEventOrderCancelled, order.Id)
+	}
+	return result, err
 }
@@ -0,0 +1,334 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/imnzr/sim-service-project/internal/audit"
+	"github.com/imnzr/sim-service-project/internal/repository"
+	"github.com/imnzr/sim-service-project/models"
+)
+
+const maxPriceTierNameLength = 64
+
+// Aksi audit log untuk tier harga
+const (
+	AuditActionUserPriceTierAssign  = "user.price_tier_assign"
+	AuditActionUserPriceTierUpgrade = "user.price_tier_upgrade"
+)
+
+var (
+	ErrPriceTierNotFound  = errors.New("price tier not found")
+	ErrPriceTierInactive  = errors.New("price tier is not active")
+	ErrDuplicatePriceTier = errors.New("price tier name already exists")
+)
+
+type PriceTierService interface {
+	List(ctx context.Context) ([]models.PriceTier, error)
+	Get(ctx context.Context, id int) (*models.PriceTier, error)
+	Create(ctx context.Context, req *models.PriceTierRequest) (*models.PriceTier, error)
+	Update(ctx context.Context, id int, req *models.PriceTierRequest) (*models.PriceTier, error)
+	Delete(ctx context.Context, id int) error
+	AssignTier(ctx context.Context, actorId, userId uint, tierId *int) (*models.User, error)
+	TierForUser(ctx context.Context, userId uint) (*models.PriceTier, error)
+	Summary(ctx context.Context, userId uint) (*models.UserPriceTierSummary, error)
+	ApplyToQuote(ctx context.Context, userId uint, quote *models.PriceQuote) error
+	EvaluateUpgrade(ctx context.Context, userId uint) error
+}
+
+type PriceTierServiceImplementation struct {
+	Repo        repository.PriceTierRepository
+	UserRepo    repository.UserRepository
+	OrderRepo   repository.SimOrderRepository
+	AuditLogger audit.Logger
+}
+
+func NewPriceTierService(repo repository.PriceTierRepository, userRepo repository.UserRepository, orderRepo repository.SimOrderRepository, auditLogger audit.Logger) PriceTierService {
+	return &PriceTierServiceImplementation{
+		Repo:        repo,
+		UserRepo:    userRepo,
+		OrderRepo:   orderRepo,
+		AuditLogger: auditLogger,
+	}
+}
+
+// startOfMonth adalah awal periode volume bulanan
+func startOfMonth(now time.Time) time.Time {
+	return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+}
+
+func validatePriceTierRequest(req *models.PriceTierRequest) error {
+	req.Name = strings.TrimSpace(req.Name)
+
+	errs := fieldErrors{}
+	switch {
+	case req.Name == "":
+		errs.add("name", "name is required")
+	case len(req.Name) > maxPriceTierNameLength:
+		errs.add("name", "name is too long")
+	}
+	if req.DiscountPercent < 0 || req.DiscountPercent >= 100 {
+		errs.add("discount_percent", "discount_percent must be between 0 and 100")
+	}
+	if req.MonthlyVolumeThreshold != nil && *req.MonthlyVolumeThreshold < 0 {
+		errs.add("monthly_volume_threshold", "monthly_volume_threshold must not be negative")
+	}
+	return errs.err()
+}
+
+// List implements PriceTierService.
+func (p *PriceTierServiceImplementation) List(ctx context.Context) ([]models.PriceTier, error) {
+	return p.Repo.FindAll(ctx)
+}
+
+// Get implements PriceTierService.
+func (p *PriceTierServiceImplementation) Get(ctx context.Context, id int) (*models.PriceTier, error) {
+	tier, err := p.Repo.GetById(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get price tier: %w", err)
+	}
+	if tier == nil {
+		return nil, ErrPriceTierNotFound
+	}
+	return tier, nil
+}
+
+// Create implements PriceTierService.
+func (p *PriceTierServiceImplementation) Create(ctx context.Context, req *models.PriceTierRequest) (*models.PriceTier, error) {
+	if err := validatePriceTierRequest(req); err != nil {
+		return nil, err
+	}
+	tier := &models.PriceTier{
+		Name:                   req.Name,
+		DiscountPercent:        req.DiscountPercent,
+		MonthlyVolumeThreshold: req.MonthlyVolumeThreshold,
+		Active:                 req.Active == nil || *req.Active,
+	}
+	if err := p.Repo.Create(ctx, tier); err != nil {
+		if errors.Is(err, repository.ErrDuplicateEntry) {
+			return nil, ErrDuplicatePriceTier
+		}
+		return nil, fmt.Errorf("failed to create price tier: %w", err)
+	}
+	return p.Get(ctx, tier.Id)
+}
+
+// Update implements PriceTierService.
+func (p *PriceTierServiceImplementation) Update(ctx context.Context, id int, req *models.PriceTierRequest) (*models.PriceTier, error) {
+	if err := validatePriceTierRequest(req); err != nil {
+		return nil, err
+	}
+	tier, err := p.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	tier.Name = req.Name
+	tier.DiscountPercent = req.DiscountPercent
+	tier.MonthlyVolumeThreshold = req.MonthlyVolumeThreshold
+	if req.Active != nil {
+		tier.Active = *req.Active
+	}
+	if err := p.Repo.Update(ctx, tier); err != nil {
+		if errors.Is(err, repository.ErrDuplicateEntry) {
+			return nil, ErrDuplicatePriceTier
+		}
+		return nil, fmt.Errorf("failed to update price tier: %w", err)
+	}
+	return p.Get(ctx, id)
+}
+
+// Delete implements PriceTierService.
+func (p *PriceTierServiceImplementation) Delete(ctx context.Context, id int) error {
+	if _, err := p.Get(ctx, id); err != nil {
+		return err
+	}
+	if err := p.Repo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete price tier: %w", err)
+	}
+	return nil
+}
+
+// AssignTier implements PriceTierService.
+// tierId nil menghapus tier user sehingga kembali ke harga retail.
+func (p *PriceTierServiceImplementation) AssignTier(ctx context.Context, actorId, userId uint, tierId *int) (*models.User, error) {
+	user, err := p.getUser(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	if tierId != nil {
+		tier, err := p.Get(ctx, *tierId)
+		if err != nil {
+			return nil, err
+		}
+		if !tier.Active {
+			return nil, ErrPriceTierInactive
+		}
+	}
+
+	if err := p.UserRepo.SetPriceTier(ctx, userId, tierId); err != nil {
+		return nil, err
+	}
+	after := p.record(ctx, actorId, AuditActionUserPriceTierAssign, user, nil)
+	if after == nil {
+		return p.getUser(ctx, userId)
+	}
+	return after, nil
+}
+
+// TierForUser implements PriceTierService.
+// Tier yang sudah dinonaktifkan dianggap tidak ada sehingga user membayar harga retail.
+func (p *PriceTierServiceImplementation) TierForUser(ctx context.Context, userId uint) (*models.PriceTier, error) {
+	user, err := p.UserRepo.GetUserById(ctx, userId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil || user.PriceTierId == nil {
+		return nil, nil
+	}
+	tier, err := p.Repo.GetById(ctx, *user.PriceTierId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get price tier: %w", err)
+	}
+	if tier == nil || !tier.Active {
+		return nil, nil
+	}
+	return tier, nil
+}
+
+// Summary implements PriceTierService.
+func (p *PriceTierServiceImplementation) Summary(ctx context.Context, userId uint) (*models.UserPriceTierSummary, error) {
+	tier, err := p.TierForUser(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	volume, err := p.OrderRepo.SumPaidVolume(ctx, int(userId), startOfMonth(time.Now()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load monthly volume: %w", err)
+	}
+	tiers, err := p.Repo.FindActive(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load price tiers: %w", err)
+	}
+
+	summary := &models.UserPriceTierSummary{Tier: tier, MonthlyVolume: volume}
+	for i := range tiers {
+		candidate := &tiers[i]
+		if candidate.MonthlyVolumeThreshold == nil || !betterTier(candidate, tier) {
+			continue
+		}
+		if summary.NextTier == nil || *candidate.MonthlyVolumeThreshold < *summary.NextTier.MonthlyVolumeThreshold {
+			summary.NextTier = candidate
+		}
+	}
+	return summary, nil
+}
+
+// ApplyToQuote implements PriceTierService.
+func (p *PriceTierServiceImplementation) ApplyToQuote(ctx context.Context, userId uint, quote *models.PriceQuote) error {
+	tier, err := p.TierForUser(ctx, userId)
+	if err != nil {
+		return err
+	}
+	if tier == nil || tier.DiscountPercent <= 0 {
+		return nil
+	}
+	retail := quote.PriceSell
+	quote.RetailPrice = &retail
+	quote.PriceSell = tier.ApplyDiscount(retail)
+	quote.PriceTierId = &tier.Id
+	quote.DiscountPercent = tier.DiscountPercent
+	return nil
+}
+
+// EvaluateUpgrade implements PriceTierService.
+// Hanya reseller yang naik tier otomatis, dan tier tidak pernah diturunkan otomatis
+// sehingga tier yang di-assign admin tetap berlaku.
+func (p *PriceTierServiceImplementation) EvaluateUpgrade(ctx context.Context, userId uint) error {
+	user, err := p.getUser(ctx, userId)
+	if err != nil {
+		return err
+	}
+	if user.Role != models.RoleReseller {
+		return nil
+	}
+	current, err := p.TierForUser(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	volume, err := p.OrderRepo.SumPaidVolume(ctx, int(userId), startOfMonth(time.Now()))
+	if err != nil {
+		return fmt.Errorf("failed to load monthly volume: %w", err)
+	}
+	tiers, err := p.Repo.FindActive(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load price tiers: %w", err)
+	}
+
+	var target *models.PriceTier
+	for i := range tiers {
+		candidate := &tiers[i]
+		if candidate.MonthlyVolumeThreshold == nil || volume < *candidate.MonthlyVolumeThreshold {
+			continue
+		}
+		if betterTier(candidate, current) && betterTier(candidate, target) {
+			target = candidate
+		}
+	}
+	if target == nil {
+		return nil
+	}
+
+	if err := p.UserRepo.SetPriceTier(ctx, userId, &target.Id); err != nil {
+		return err
+	}
+	// upgrade dilakukan sistem, tidak ada actor user
+	p.record(ctx, 0, AuditActionUserPriceTierUpgrade, user, map[string]any{
+		"monthly_volume": volume,
+		"price_tier_id":  target.Id,
+	})
+	return nil
+}
+
+// betterTier true jika candidate memberi diskon lebih besar dari current
+func betterTier(candidate, current *models.PriceTier) bool {
+	return current == nil || candidate.DiscountPercent > current.DiscountPercent
+}
+
+func (p *PriceTierServiceImplementation) getUser(ctx context.Context, userId uint) (*models.User, error) {
+	user, err := p.UserRepo.GetUserById(ctx, userId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
+
+// record menulis audit log perubahan tier user dan mengembalikan user setelah perubahan.
+// Perubahan sudah tersimpan, jadi kegagalan audit hanya di-log.
+func (p *PriceTierServiceImplementation) record(ctx context.Context, actorId uint, action string, before *models.User, metadata any) *models.User {
+	after, err := p.UserRepo.GetUserById(ctx, before.ID)
+	if err != nil {
+		log.Printf("failed to read user %d for audit snapshot: %v", before.ID, err)
+	}
+
+	err = p.AuditLogger.Record(ctx, audit.Entry{
+		ActorUserId: actorId,
+		Action:      action,
+		TargetType:  audit.TargetUser,
+		TargetId:    userTargetId(before.ID),
+		Before:      audit.UserSnapshot(before),
+		After:       audit.UserSnapshot(after),
+		Metadata:    metadata,
+	})
+	if err != nil {
+		log.Printf("AUDIT FAILURE: %s on user %d not written to audit log: %v", action, before.ID, err)
+	}
+	return after
+}
@@ -0,0 +1,282 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/imnzr/sim-service-project/internal/repository"
+	"github.com/imnzr/sim-service-project/models"
+)
+
+// markup flat dalam IDR jika tidak ada pricing rule yang cocok
+const defaultMarkup = 2000.0
+
+// PricingDryRunRequest berisi perubahan rule yang ingin disimulasikan
+type PricingDryRunRequest struct {
+	Rule   models.PricingRule
+	Delete bool
+	Limit  int
+}
+
+type PricingService interface {
+	ListRules(ctx context.Context) ([]models.PricingRule, error)
+	GetRule(ctx context.Context, id int) (*models.PricingRule, error)
+	CreateRule(ctx context.Context, rule *models.PricingRule) error
+	UpdateRule(ctx context.Context, rule *models.PricingRule) error
+	DeleteRule(ctx context.Context, id int) error
+	ActiveRules(ctx context.Context) ([]models.PricingRule, error)
+	DryRun(ctx context.Context, req *PricingDryRunRequest) (*models.PricingDryRunResponse, error)
+}
+
+type PricingServiceImplementation struct {
+	Repo         repository.PricingRuleRepository
+	ProductRepo  repository.ProductRepository
+	OverrideRepo repository.ProductOverrideRepository
+	ExchangeRate ExchangeRateService
+}
+
+func NewPricingService(repo repository.PricingRuleRepository, productRepo repository.ProductRepository, overrideRepo repository.ProductOverrideRepository, exchangeRate ExchangeRateService) PricingService {
+	return &PricingServiceImplementation{
+		Repo:         repo,
+		ProductRepo:  productRepo,
+		OverrideRepo: overrideRepo,
+		ExchangeRate: exchangeRate,
+	}
+}
+
+// ruleMatches mengecek apakah rule berlaku untuk produk. Field kosong berarti wildcard.
+func ruleMatches(rule models.PricingRule, service, country, operator string) bool {
+	return (rule.Service == "" || rule.Service == service) &&
+		(rule.Country == "" || rule.Country == country) &&
+		(rule.Operator == "" || rule.Operator == operator)
+}
+
+// ruleOrder mengembalikan urutan pembuatan rule; rule baru (id 0) dianggap paling akhir
+func ruleOrder(rule models.PricingRule) int {
+	if rule.Id == 0 {
+		return math.MaxInt
+	}
+	return rule.Id
+}
+
+func ruleSpecificity(rule models.PricingRule) int {
+	specificity := 0
+	for _, field := range []string{rule.Service, rule.Country, rule.Operator} {
+		if field != "" {
+			specificity++
+		}
+	}
+	return specificity
+}
+
+// SelectPricingRule memilih rule dengan priority tertinggi, lalu yang paling spesifik, lalu id terkecil
+func SelectPricingRule(rules []models.PricingRule, service, country, operator string) *models.PricingRule {
+	var selected *models.PricingRule
+	for i := range rules {
+		rule := &rules[i]
+		if !rule.Active || !ruleMatches(*rule, service, country, operator) {
+			continue
+		}
+		if selected == nil ||
+			rule.Priority > selected.Priority ||
+			(rule.Priority == selected.Priority && ruleSpecificity(*rule) > ruleSpecificity(*selected)) ||
+			(rule.Priority == selected.Priority && ruleSpecificity(*rule) == ruleSpecificity(*selected) && ruleOrder(*rule) < ruleOrder(*selected)) {
+			selected = rule
+		}
+	}
+	return selected
+}
+
+// ApplyPricingRule menghitung harga jual dari modal (IDR) berdasarkan rule
+func ApplyPricingRule(rule *models.PricingRule, cost float64) float64 {
+	if rule == nil {
+		return cost + defaultMarkup
+	}
+
+	price := cost*(1+rule.MarkupPercent/100) + rule.MarkupFixed
+	if price-cost < rule.MinMargin {
+		price = cost + rule.MinMargin
+	}
+	if rule.RoundTo > 0 {
+		price = math.Ceil(price/rule.RoundTo) * rule.RoundTo
+	}
+	return price
+}
+
+// CalculatePrice memilih rule yang cocok lalu menghitung harga jual
+func CalculatePrice(rules []models.PricingRule, service, country, operator string, cost float64) (float64, *models.PricingRule) {
+	rule := SelectPricingRule(rules, service, country, operator)
+	return ApplyPricingRule(rule, cost), rule
+}
+
+func validatePricingRule(rule *models.PricingRule) error {
+	rule.Name = strings.TrimSpace(rule.Name)
+	if rule.Name == "" {
+		return errors.New("name is required")
+	}
+	if rule.MarkupPercent < 0 || rule.MarkupFixed < 0 || rule.MinMargin < 0 || rule.RoundTo < 0 {
+		return errors.New("markup_percent, markup_fixed, min_margin and round_to must not be negative")
+	}
+	return nil
+}
+
+// ListRules implements PricingService.
+func (p *PricingServiceImplementation) ListRules(ctx context.Context) ([]models.PricingRule, error) {
+	return p.Repo.FindAll(ctx)
+}
+
+// GetRule implements PricingService.
+func (p *PricingServiceImplementation) GetRule(ctx context.Context, id int) (*models.PricingRule, error) {
+	rule, err := p.Repo.GetById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if rule == nil {
+		return nil, fmt.Errorf("pricing rule %d not found", id)
+	}
+	return rule, nil
+}
+
+// CreateRule implements PricingService.
+func (p *PricingServiceImplementation) CreateRule(ctx context.Context, rule *models.PricingRule) error {
+	if err := validatePricingRule(rule); err != nil {
+		return err
+	}
+	return p.Repo.Create(ctx, rule)
+}
+
+// UpdateRule implements PricingService.
+func (p *PricingServiceImplementation) UpdateRule(ctx context.Context, rule *models.PricingRule) error {
+	if err := validatePricingRule(rule); err != nil {
+		return err
+	}
+	if _, err := p.GetRule(ctx, rule.Id); err != nil {
+		return err
+	}
+	return p.Repo.Update(ctx, rule)
+}
+
+// DeleteRule implements PricingService.
+func (p *PricingServiceImplementation) DeleteRule(ctx context.Context, id int) error {
+	if _, err := p.GetRule(ctx, id); err != nil {
+		return err
+	}
+	return p.Repo.Delete(ctx, id)
+}
+
+// ActiveRules implements PricingService.
+func (p *PricingServiceImplementation) ActiveRules(ctx context.Context) ([]models.PricingRule, error) {
+	return p.Repo.FindActive(ctx)
+}
+
+// DryRun implements PricingService.
+// Rule pada request menggantikan rule dengan id yang sama (atau ditambahkan jika id 0),
+// lalu seluruh katalog dihitung ulang tanpa menyimpan apa pun.
+// Produk yang dinonaktifkan atau harganya di-pin lewat product_overrides tidak terpengaruh rule,
+// jadi tidak dihitung sebagai perubahan dan hanya dilaporkan jumlahnya.
+func (p *PricingServiceImplementation) DryRun(ctx context.Context, req *PricingDryRunRequest) (*models.PricingDryRunResponse, error) {
+	if !req.Delete {
+		if err := validatePricingRule(&req.Rule); err != nil {
+			return nil, err
+		}
+	}
+
+	current, err := p.Repo.FindActive(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load pricing rules: %w", err)
+	}
+
+	proposed := make([]models.PricingRule, 0, len(current)+1)
+	for _, rule := range current {
+		if req.Rule.Id != 0 && rule.Id == req.Rule.Id {
+			continue
+		}
+		proposed = append(proposed, rule)
+	}
+	if !req.Delete {
+		proposed = append(proposed, req.Rule)
+	}
+
+	rate, err := p.ExchangeRate.CurrentRate(ctx, CurrencyRUB, CurrencyIDR)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get exchange rate: %w", err)
+	}
+
+	products, err := p.ProductRepo.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load catalog: %w", err)
+	}
+
+	overrides, err := p.OverrideRepo.FindAll(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load product overrides: %w", err)
+	}
+	overridesByService := make(map[string][]models.ProductOverride)
+	for _, override := range overrides {
+		overridesByService[override.Service] = append(overridesByService[override.Service], override)
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+
+	response := &models.PricingDryRunResponse{
+		ExchangeRate:  rate.Rate,
+		TotalProducts: len(products),
+		Changes:       []models.PricingDryRunChange{},
+	}
+	for _, product := range products {
+		var applicable []models.ProductOverride
+		for _, override := range overridesByService[product.Service] {
+			if overrideApplies(override, product.Service, product.Country, product.Operator) {
+				applicable = append(applicable, override)
+			}
+		}
+		if len(applicable) > 0 {
+			resolved := resolveOverride(product.Service, applicable)
+			if !resolved.Enabled {
+				response.DisabledCount++
+				continue
+			}
+			if resolved.PinnedPrice != nil {
+				response.PinnedCount++
+				continue
+			}
+		}
+
+		cost := product.PriceDefault * rate.Rate
+		currentPrice, _ := CalculatePrice(current, product.Service, product.Country, product.Operator, cost)
+		newPrice, rule := CalculatePrice(proposed, product.Service, product.Country, product.Operator, cost)
+		if newPrice == currentPrice {
+			continue
+		}
+
+		response.ChangedCount++
+		if newPrice > currentPrice {
+			response.IncreasedCount++
+		} else {
+			response.DecreasedCount++
+		}
+
+		if len(response.Changes) < limit {
+			change := models.PricingDryRunChange{
+				Service:      product.Service,
+				Country:      product.Country,
+				Operator:     product.Operator,
+				PriceDefault: product.PriceDefault,
+				CurrentPrice: currentPrice,
+				NewPrice:     newPrice,
+			}
+			if rule != nil {
+				ruleId := rule.Id
+				change.RuleId = &ruleId
+			}
+			response.Changes = append(response.Changes, change)
+		}
+	}
+	return response, nil
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/imnzr/sim-service-project/models"
+)
+
+func TestSelectPricingRule(t *testing.T) {
+	rules := []models.PricingRule{
+		{Id: 1, Name: "global", Active: true},
+		{Id: 2, Name: "telegram", Service: "telegram", Active: true},
+		{Id: 3, Name: "telegram russia", Service: "telegram", Country: "russia", Active: true},
+		{Id: 4, Name: "telegram russia mts", Service: "telegram", Country: "russia", Operator: "mts", Active: true},
+		{Id: 5, Name: "whatsapp promo", Service: "whatsapp", Priority: 10, Active: true},
+		{Id: 6, Name: "whatsapp india", Service: "whatsapp", Country: "india", Active: true},
+		{Id: 7, Name: "inactive", Service: "discord", Priority: 100, Active: false},
+		{Id: 8, Name: "telegram duplicate", Service: "telegram", Active: true},
+	}
+
+	tests := []struct {
+		name     string
+		rules    []models.PricingRule
+		service  string
+		country  string
+		operator string
+		wantId   int
+	}{
+		{name: "no rules", rules: nil, service: "telegram", country: "russia", operator: "any", wantId: 0},
+		{name: "wildcard only", rules: rules, service: "instagram", country: "usa", operator: "any", wantId: 1},
+		{name: "most specific wins", rules: rules, service: "telegram", country: "russia", operator: "mts", wantId: 4},
+		{name: "country match", rules: rules, service: "telegram", country: "russia", operator: "beeline", wantId: 3},
+		{name: "lowest id on tie", rules: rules, service: "telegram", country: "usa", operator: "any", wantId: 2},
+		{name: "priority beats specificity", rules: rules, service: "whatsapp", country: "india", operator: "any", wantId: 5},
+		{name: "inactive rule ignored", rules: rules, service: "discord", country: "usa", operator: "any", wantId: 1},
+		{
+			name:    "new rule loses tie to saved rule",
+			rules:   []models.PricingRule{{Id: 0, Name: "draft", Active: true}, {Id: 9, Name: "saved", Active: true}},
+			service: "telegram", country: "russia", operator: "any",
+			wantId: 9,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SelectPricingRule(tt.rules, tt.service, tt.country, tt.operator)
+			if tt.wantId == 0 {
+				if got != nil {
+					t.Fatalf("expected no rule, got %d (%s)", got.Id, got.Name)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatalf("expected rule %d, got nil", tt.wantId)
+			}
+			if got.Id != tt.wantId {
+				t.Fatalf("expected rule %d, got %d (%s)", tt.wantId, got.Id, got.Name)
+			}
+		})
+	}
+}
+
+func TestApplyPricingRule(t *testing.T) {
+	tests := []struct {
+		name string
+		rule *models.PricingRule
+		cost float64
+		want float64
+	}{
+		{name: "nil rule uses default markup", rule: nil, cost: 10000, want: 10000 + defaultMarkup},
+		{name: "percent markup", rule: &models.PricingRule{MarkupPercent: 10}, cost: 10000, want: 11000},
+		{name: "percent and fixed markup", rule: &models.PricingRule{MarkupPercent: 10, MarkupFixed: 500}, cost: 10000, want: 11500},
+		{name: "min margin applied", rule: &models.PricingRule{MarkupPercent: 1, MinMargin: 1500}, cost: 10000, want: 11500},
+		{name: "min margin not needed", rule: &models.PricingRule{MarkupPercent: 20, MinMargin: 1500}, cost: 10000, want: 12000},
+		{name: "rounded up", rule: &models.PricingRule{MarkupPercent: 10, RoundTo: 500}, cost: 10100, want: 11500},
+		{name: "already rounded", rule: &models.PricingRule{MarkupFixed: 1000, RoundTo: 500}, cost: 10000, want: 11000},
+		{name: "zero markup", rule: &models.PricingRule{}, cost: 7500, want: 7500},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ApplyPricingRule(tt.rule, tt.cost); got != tt.want {
+				t.Fatalf("ApplyPricingRule() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/imnzr/sim-service-project/internal/repository"
+	"github.com/imnzr/sim-service-project/models"
+)
+
+type ProductOverrideService interface {
+	List(ctx context.Context, service string) ([]models.ProductOverride, error)
+	Upsert(ctx context.Context, override *models.ProductOverride) error
+	Delete(ctx context.Context, id int) error
+	Resolve(ctx context.Context, service, country, operator string) (*models.ResolvedOverride, error)
+}
+
+type ProductOverrideServiceImplementation struct {
+	Repo repository.ProductOverrideRepository
+}
+
+func NewProductOverrideService(repo repository.ProductOverrideRepository) ProductOverrideService {
+	return &ProductOverrideServiceImplementation{
+		Repo: repo,
+	}
+}
+
+// List implements ProductOverrideService.
+func (p *ProductOverrideServiceImplementation) List(ctx context.Context, service string) ([]models.ProductOverride, error) {
+	return p.Repo.FindAll(ctx, service)
+}
+
+// Upsert implements ProductOverrideService.
+func (p *ProductOverrideServiceImplementation) Upsert(ctx context.Context, override *models.ProductOverride) error {
+	override.Service = strings.TrimSpace(override.Service)
+	override.Country = strings.TrimSpace(override.Country)
+	override.Operator = strings.TrimSpace(override.Operator)
+
+	if override.Service == "" {
+		return errors.New("service is required")
+	}
+	if override.Operator != "" && override.Country == "" {
+		return errors.New("country is required when operator is set")
+	}
+	if override.PinnedPrice != nil && *override.PinnedPrice <= 0 {
+		return errors.New("pinned_price must be greater than zero")
+	}
+	if override.Enabled == nil && override.PinnedPrice == nil && override.DisplayName == nil && override.IconURL == nil {
+		return errors.New("at least one of enabled, pinned_price, display_name or icon_url is required")
+	}
+	return p.Repo.Upsert(ctx, override)
+}
+
+// Delete implements ProductOverrideService.
+func (p *ProductOverrideServiceImplementation) Delete(ctx context.Context, id int) error {
+	override, err := p.Repo.GetById(ctx, id)
+	if err != nil {
+		return err
+	}
+	if override == nil {
+		return fmt.Errorf("product override %d not found", id)
+	}
+	return p.Repo.Delete(ctx, id)
+}
+
+// Resolve implements ProductOverrideService.
+// Override yang lebih spesifik (operator > country > service) menimpa yang lebih umum per field.
+func (p *ProductOverrideServiceImplementation) Resolve(ctx context.Context, service string, country string, operator string) (*models.ResolvedOverride, error) {
+	overrides, err := p.Repo.FindForProduct(ctx, service, country, operator)
+	if err != nil {
+		return nil, err
+	}
+
+	return resolveOverride(service, overrides), nil
+}
+
+// overrideApplies mengecek apakah override berlaku untuk produk, sama dengan filter FindForProduct
+func overrideApplies(override models.ProductOverride, service, country, operator string) bool {
+	if override.Service != service {
+		return false
+	}
+	switch {
+	case override.Operator != "":
+		return override.Country == country && override.Operator == operator
+	case override.Country != "":
+		return override.Country == country
+	default:
+		return true
+	}
+}
+
+// resolveOverride menggabungkan override yang berlaku untuk satu produk
+func resolveOverride(service string, overrides []models.ProductOverride) *models.ResolvedOverride {
+	sort.Slice(overrides, func(i, j int) bool {
+		return overrideSpecificity(overrides[i]) < overrideSpecificity(overrides[j])
+	})
+
+	resolved := &models.ResolvedOverride{
+		Enabled:     true,
+		DisplayName: service,
+	}
+	for _, override := range overrides {
+		if override.Enabled != nil {
+			resolved.Enabled = *override.Enabled
+		}
+		if override.PinnedPrice != nil {
+			resolved.PinnedPrice = override.PinnedPrice
+		}
+		if override.DisplayName != nil {
+			resolved.DisplayName = *override.DisplayName
+		}
+		if override.IconURL != nil {
+			resolved.IconURL = override.IconURL
+		}
+	}
+	return resolved
+}
+
+func overrideSpecificity(override models.ProductOverride) int {
+	switch {
+	case override.Operator != "":
+		return 2
+	case override.Country != "":
+		return 1
+	default:
+		return 0
+	}
+}
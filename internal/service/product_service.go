@@ -3,19 +3,21 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"math"
 	"net/http"
 	"os"
 	"strings"
 	"time"
 
 	"github.com/imnzr/sim-service-project/config"
+	"github.com/imnzr/sim-service-project/internal/cache"
 	"github.com/imnzr/sim-service-project/internal/repository"
 	"github.com/imnzr/sim-service-project/models"
 	"github.com/imnzr/sim-service-project/utils"
-	"github.com/redis/go-redis/v9"
 )
 
 type ProductInformation struct {
@@ -25,42 +27,141 @@ type ProductInformation struct {
 }
 
 type ProductService interface {
-	GetProductAvailable(service, country, operator string) (map[string]ProductInformation, error)
-	SyncFromSimServices(ctx context.Context) error
+	GetProductAvailable(ctx context.Context, service, country, operator string) (map[string]ProductInformation, error)
+	SyncFromSimServices(ctx context.Context) (*models.SyncSummary, error)
+	QuotePrice(ctx context.Context, service, country, operator string) (*models.PriceQuote, error)
+	GetPriceHistory(ctx context.Context, query models.PriceHistoryQuery) ([]models.ProductPriceHistory, error)
 }
 
+// prefix key cache data produk dari 5sim, dihapus setiap selesai sync katalog
+const productCacheKeyPrefix = "service_product:"
+
+const (
+	StockSourceSync = "sync"
+	StockSourceLive = "live"
+)
+
 type ProductServiceImplementation struct {
-	Repo repository.ProductRepository
-	Cfg  config.AppConfig
+	Repo         repository.ProductRepository
+	StockRepo    repository.ProductStockRepository
+	HistoryRepo  repository.PriceHistoryRepository
+	ExchangeRate ExchangeRateService
+	Pricing      PricingService
+	Overrides    ProductOverrideService
+	Cache        *cache.Cache
+	RateLimiter  *cache.RateLimiter
+	Cfg          config.AppConfig
 }
 
-func NewProductService(repo repository.ProductRepository, cfg config.AppConfig) ProductService {
+func NewProductService(repo repository.ProductRepository, stockRepo repository.ProductStockRepository, historyRepo repository.PriceHistoryRepository, exchangeRate ExchangeRateService, pricing PricingService, overrides ProductOverrideService, cache *cache.Cache, rateLimiter *cache.RateLimiter, cfg config.AppConfig) ProductService {
 	return &ProductServiceImplementation{
-		Repo: repo,
-		Cfg:  cfg,
+		Repo:         repo,
+		StockRepo:    stockRepo,
+		HistoryRepo:  historyRepo,
+		ExchangeRate: exchangeRate,
+		Pricing:      pricing,
+		Overrides:    overrides,
+		Cache:        cache,
+		RateLimiter:  rateLimiter,
+		Cfg:          cfg,
 	}
 }
 
-// GetProductAvailable implements ProductService.
-func (serv *ProductServiceImplementation) GetProductAvailable(service string, country string, operator string) (map[string]ProductInformation, error) {
-	redisClient := redis.NewClient(&redis.Options{
-		Addr:     serv.Cfg.RedisURL,
-		Password: serv.Cfg.RedisPassword,
-		DB:       0,
+// QuotePrice implements ProductService.
+// Harga diambil dari katalog (price_sell hasil sync terakhir atau harga yang di-pin admin),
+// sama dengan harga yang ditampilkan ke customer, bukan dari harga yang dikirim client.
+func (serv *ProductServiceImplementation) QuotePrice(ctx context.Context, service string, country string, operator string) (*models.PriceQuote, error) {
+	product, err := serv.Repo.FindByKey(ctx, service, country, operator)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find product: %w", err)
+	}
+	if product == nil {
+		return nil, fmt.Errorf("product not found: %s/%s/%s", service, country, operator)
+	}
+	if !product.Available || product.Stock <= 0 {
+		return nil, fmt.Errorf("product is out of stock: %s/%s/%s", service, country, operator)
+	}
+
+	override, err := serv.Overrides.Resolve(ctx, service, country, operator)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load product override: %w", err)
+	}
+	if !override.Enabled {
+		return nil, fmt.Errorf("product is not for sale: %s/%s/%s", service, country, operator)
+	}
+
+	if product.PriceSell <= 0 && override.PinnedPrice == nil {
+		return nil, fmt.Errorf("product has no price yet: %s/%s/%s", service, country, operator)
+	}
+
+	quote := &models.PriceQuote{
+		Service:      product.Service,
+		Country:      product.Country,
+		Operator:     product.Operator,
+		PriceDefault: product.PriceDefault,
+		PriceSell:    product.PriceSell,
+	}
+
+	// kurs dan rule dicatat dari riwayat harga yang menghasilkan price_sell saat ini
+	history, err := serv.HistoryRepo.Find(ctx, models.PriceHistoryQuery{
+		Service:  service,
+		Country:  country,
+		Operator: operator,
+		Limit:    1,
 	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load price history: %w", err)
+	}
+	if len(history) > 0 && samePrice(history[0].NewPriceSell, product.PriceSell) {
+		quote.ExchangeRate = history[0].ExchangeRate
+		if history[0].ExchangeRateId != nil {
+			quote.ExchangeRateId = *history[0].ExchangeRateId
+		}
+		quote.PricingRuleId = history[0].PricingRuleId
+	} else {
+		rate, err := serv.ExchangeRate.CurrentRate(ctx, CurrencyRUB, CurrencyIDR)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get exchange rate: %w", err)
+		}
+		quote.ExchangeRate = rate.Rate
+		quote.ExchangeRateId = rate.Id
+	}
 
-	cacheKey := fmt.Sprintf("service_product:%s:%s:%s", service, country, operator)
+	// harga yang di-pin admin menggantikan hasil pricing rule
+	if override.PinnedPrice != nil {
+		quote.PriceSell = *override.PinnedPrice
+		quote.PricingRuleId = nil
+		quote.PricePinned = true
+	}
+	return quote, nil
+}
 
-	cacheData, err := redisClient.Get(context.Background(), cacheKey).Result()
+// GetProductAvailable implements ProductService.
+// Data 5sim di-cache per kombinasi service/country/operator. Jika redis down,
+// data diambil dari katalog di database agar 5sim tidak dibanjiri request.
+func (serv *ProductServiceImplementation) GetProductAvailable(ctx context.Context, service string, country string, operator string) (map[string]ProductInformation, error) {
+	cacheKey := fmt.Sprintf("%s%s:%s:%s", productCacheKeyPrefix, service, country, operator)
 
-	if err == nil {
-		var cacheResult map[string]ProductInformation
-		if err := json.Unmarshal([]byte(cacheData), &cacheResult); err == nil {
-			fmt.Println("cache hit:", cacheKey)
-			return cacheResult, nil
+	result, err := cache.GetOrLoad(ctx, serv.Cache, cacheKey, serv.Cfg.CacheProductTTL, func(ctx context.Context) (map[string]ProductInformation, error) {
+		result, err := serv.fetchProductAvailable(ctx, service, country, operator)
+		if err != nil {
+			return nil, err
 		}
+		serv.recordLiveStock(ctx, country, operator, result)
+		return result, nil
+	})
+	if err != nil {
+		if errors.Is(err, cache.ErrUnavailable) {
+			log.Printf("redis unavailable, using catalog from database: %v", err)
+			return serv.productAvailableFromCatalog(ctx, service, country, operator)
+		}
+		return nil, err
 	}
+	return result, nil
+}
 
+// fetchProductAvailable mengambil data produk langsung dari 5sim
+func (serv *ProductServiceImplementation) fetchProductAvailable(ctx context.Context, service string, country string, operator string) (map[string]ProductInformation, error) {
 	url := fmt.Sprintf("%sguest/products/%s/%s/%s", os.Getenv("SIM_API_URL_SERVICE"), service, country, operator)
 
 	req, err := utils.NewRequestSIM("GET", url, nil)
@@ -70,7 +171,7 @@ func (serv *ProductServiceImplementation) GetProductAvailable(service string, co
 
 	client := http.Client{}
 
-	res, err := client.Do(req)
+	res, err := client.Do(req.WithContext(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -88,35 +189,150 @@ func (serv *ProductServiceImplementation) GetProductAvailable(service string, co
 	if err := json.Unmarshal(bodyBytes, &result); err != nil {
 		return nil, fmt.Errorf("failed to decode product JSON: %w", err)
 	}
+	return result, nil
+}
+
+// recordLiveStock menyimpan stok hasil lookup live ke snapshot dan tabel product.
+// Endpoint lookup publik, jadi penulisan dibatasi sekali per country/operator per
+// LiveStockInterval dan snapshot hanya dicatat untuk produk yang ada di katalog.
+func (serv *ProductServiceImplementation) recordLiveStock(ctx context.Context, country string, operator string, result map[string]ProductInformation) {
+	allowed, err := serv.RateLimiter.Allow(ctx, fmt.Sprintf("live_stock:%s:%s", country, operator), 1, serv.Cfg.LiveStockInterval)
+	if err != nil {
+		log.Printf("failed to check live stock throttle: %v", err)
+		return
+	}
+	if !allowed {
+		return
+	}
+
+	now := time.Now()
+	snapshots := make([]models.ProductStockSnapshot, 0, len(result))
+	for serviceName, info := range result {
+		product, err := serv.Repo.FindByKey(ctx, serviceName, country, operator)
+		if err != nil {
+			log.Printf("failed to find product %s/%s/%s: %v", serviceName, country, operator, err)
+			continue
+		}
+		if product == nil {
+			continue
+		}
+		if err := serv.StockRepo.UpdateStock(ctx, serviceName, country, operator, info.Qty); err != nil {
+			log.Printf("failed to update stock %s/%s/%s: %v", serviceName, country, operator, err)
+			continue
+		}
+		snapshots = append(snapshots, models.ProductStockSnapshot{
+			Service:    serviceName,
+			Country:    country,
+			Operator:   operator,
+			Qty:        info.Qty,
+			Source:     StockSourceLive,
+			RecordedAt: now,
+		})
+	}
+	if err := serv.StockRepo.RecordSnapshots(ctx, snapshots); err != nil {
+		log.Printf("failed to record live stock snapshots: %v", err)
+	}
+}
+
+// productAvailableFromCatalog membentuk response yang sama dari tabel product
+func (serv *ProductServiceImplementation) productAvailableFromCatalog(ctx context.Context, service string, country string, operator string) (map[string]ProductInformation, error) {
+	product, err := serv.Repo.FindByKey(ctx, service, country, operator)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find product: %w", err)
+	}
 
-	serialized, err := json.Marshal(result)
-	if err == nil {
-		_ = redisClient.Set(context.Background(), cacheKey, serialized, 5*time.Minute)
+	result := map[string]ProductInformation{}
+	if product == nil || !product.Available {
+		return result, nil
 	}
 
-	fmt.Println("cache miss: data fetched and cached")
+	result[product.Service] = ProductInformation{
+		Category: "activation",
+		Qty:      product.Stock,
+		Price:    product.PriceDefault,
+	}
 	return result, nil
 }
 
+// GetPriceHistory implements ProductService.
+func (serv *ProductServiceImplementation) GetPriceHistory(ctx context.Context, query models.PriceHistoryQuery) ([]models.ProductPriceHistory, error) {
+	if query.Service == "" || query.Country == "" || query.Operator == "" {
+		return nil, errors.New("service, country, and operator are required")
+	}
+	if query.Limit <= 0 || query.Limit > 500 {
+		query.Limit = 100
+	}
+	return serv.HistoryRepo.Find(ctx, query)
+}
+
+// productKey adalah kunci unik produk di katalog
+func productKey(service, country, operator string) string {
+	return service + "|" + country + "|" + operator
+}
+
+// maksimal pesan error yang dikembalikan di ringkasan sync
+const maxSyncErrorLogs = 50
+
+func addSyncError(summary *models.SyncSummary, format string, args ...any) {
+	message := fmt.Sprintf(format, args...)
+	log.Print(message)
+	summary.Errors++
+	if len(summary.ErrorLogs) < maxSyncErrorLogs {
+		summary.ErrorLogs = append(summary.ErrorLogs, message)
+	}
+}
+
 // SyncFromSimServices implements ProductService.
-func (service *ProductServiceImplementation) SyncFromSimServices(ctx context.Context) error {
+func (service *ProductServiceImplementation) SyncFromSimServices(ctx context.Context) (*models.SyncSummary, error) {
+	summary := &models.SyncSummary{StartedAt: time.Now()}
+
 	url := os.Getenv("SIM_API_URL_SERVICE")
 
 	format := fmt.Sprintf("%s/guest/prices", url)
-	resp, err := http.Get(format)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, format, nil)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("5sim price list returned status %d", resp.StatusCode)
+	}
+
 	var raw map[string]interface{}
 
 	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
-		return err
+		return nil, err
+	}
+
+	rate, err := service.ExchangeRate.CurrentRate(ctx, CurrencyRUB, CurrencyIDR)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get exchange rate: %w", err)
+	}
+	kursRubel := rate.Rate
+
+	rules, err := service.Pricing.ActiveRules(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load pricing rules: %w", err)
 	}
 
-	kursRubel := 208.0
-	markup := 2000.0
+	existingProducts, err := service.Repo.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load current catalog: %w", err)
+	}
+	existing := make(map[string]models.SimProduct, len(existingProducts))
+	for _, product := range existingProducts {
+		existing[productKey(product.Service, product.Country, product.Operator)] = product
+	}
+
+	seen := make(map[string]bool, len(existingProducts))
+	var upserts []models.SimProduct
+	var snapshots []models.ProductStockSnapshot
+	var history []models.ProductPriceHistory
 
 	for country, opsRaw := range raw {
 		opsMap, ok := opsRaw.(map[string]interface{})
@@ -126,29 +342,39 @@ func (service *ProductServiceImplementation) SyncFromSimServices(ctx context.Con
 		for operator, servicesRaw := range opsMap {
 			servicesMap, ok := servicesRaw.(map[string]interface{})
 			if !ok {
-				fmt.Println("gagal konversi ke map[string]interface{}: ", servicesRaw)
+				addSyncError(summary, "gagal konversi operator %s - %s ke map", country, operator)
 				continue
 			}
 			for serviceName, priceRaw := range servicesMap {
 				serviceInfo, ok := priceRaw.(map[string]interface{})
 				if !ok {
-					log.Printf("gagal konversi service menjadi float64")
+					addSyncError(summary, "gagal konversi service %s - %s - %s ke map", country, operator, serviceName)
 					continue
 				}
 
 				// ambil nilai cost dari map
 				priceRubelRaw, ok := serviceInfo["cost"]
 				if !ok {
-					log.Printf("cost tidak ditemukan di %s - %s - %s", country, operator, serviceName)
+					addSyncError(summary, "cost tidak ditemukan di %s - %s - %s", country, operator, serviceName)
 					continue
 				}
 				priceRubel, ok := priceRubelRaw.(float64)
 				if !ok {
-					log.Printf("gagal konversi cost ke float64: %v", priceRubelRaw)
+					addSyncError(summary, "gagal konversi cost ke float64 di %s - %s - %s: %v", country, operator, serviceName, priceRubelRaw)
+					continue
+				}
+
+				// stok dari 5sim; 0 jika tidak ada
+				stock := 0
+				if countRaw, ok := serviceInfo["count"].(float64); ok {
+					stock = int(countRaw)
 				}
 
 				priceIDR := priceRubel * kursRubel
-				priceSell := priceIDR + markup
+				priceSell, rule := CalculatePrice(rules, serviceName, country, operator, priceIDR)
+
+				key := productKey(serviceName, country, operator)
+				seen[key] = true
 
 				product := models.SimProduct{
 					Service:      serviceName,
@@ -156,13 +382,95 @@ func (service *ProductServiceImplementation) SyncFromSimServices(ctx context.Con
 					Operator:     operator,
 					PriceDefault: priceRubel,
 					PriceSell:    priceSell,
+					Available:    true,
+					Stock:        stock,
+				}
+				snapshots = append(snapshots, models.ProductStockSnapshot{
+					Service:    serviceName,
+					Country:    country,
+					Operator:   operator,
+					Qty:        stock,
+					Source:     StockSourceSync,
+					RecordedAt: summary.StartedAt,
+				})
+
+				// added/updated/unchanged dihitung dari harga dan ketersediaan,
+				// tapi semua produk tetap ditulis agar stok ikut diperbarui
+				current, found := existing[key]
+				if !found || !samePrice(current.PriceDefault, product.PriceDefault) || !samePrice(current.PriceSell, product.PriceSell) {
+					change := models.ProductPriceHistory{
+						Service:         serviceName,
+						Country:         country,
+						Operator:        operator,
+						NewPriceDefault: product.PriceDefault,
+						NewPriceSell:    product.PriceSell,
+						ExchangeRate:    rate.Rate,
+						ChangedAt:       summary.StartedAt,
+					}
+					if rate.Id != 0 {
+						change.ExchangeRateId = &rate.Id
+					}
+					if found {
+						change.OldPriceDefault = &current.PriceDefault
+						change.OldPriceSell = &current.PriceSell
+					}
+					if rule != nil {
+						change.PricingRuleId = &rule.Id
+						change.PricingRuleName = &rule.Name
+					}
+					history = append(history, change)
 				}
-				if err := service.Repo.Upsert(ctx, &product); err != nil {
-					log.Printf("gagal menyimpan product")
-					fmt.Printf("gagal menyimpan product %+v: %v\n", product, err)
+
+				switch {
+				case !found:
+					summary.Added++
+				case current.Available && samePrice(current.PriceDefault, product.PriceDefault) && samePrice(current.PriceSell, product.PriceSell):
+					summary.Unchanged++
+				default:
+					summary.Updated++
 				}
+				upserts = append(upserts, product)
 			}
 		}
 	}
-	return nil
+
+	// response kosong hampir pasti error di sisi 5sim, jangan sampai
+	// seluruh katalog ditandai tidak tersedia
+	if len(seen) == 0 {
+		return nil, errors.New("5sim price list contains no products, sync aborted")
+	}
+
+	// produk yang tidak ada lagi di response 5sim ditandai tidak tersedia
+	var unavailableIds []int
+	for key, product := range existing {
+		if !seen[key] && product.Available {
+			unavailableIds = append(unavailableIds, product.Id)
+		}
+	}
+	summary.Removed = len(unavailableIds)
+
+	if err := service.Repo.BulkSync(ctx, upserts, unavailableIds, history); err != nil {
+		return nil, fmt.Errorf("failed to save catalog: %w", err)
+	}
+
+	if err := service.StockRepo.RecordSnapshots(ctx, snapshots); err != nil {
+		addSyncError(summary, "gagal menyimpan snapshot stok: %v", err)
+	}
+
+	// data produk di cache sudah tidak sesuai dengan katalog terbaru
+	if deleted, err := service.Cache.DeleteByPrefix(ctx, productCacheKeyPrefix); err != nil {
+		log.Printf("failed to invalidate product cache: %v", err)
+	} else {
+		log.Printf("product cache invalidated: %d keys", deleted)
+	}
+
+	summary.FinishedAt = time.Now()
+	log.Printf("catalog sync finished: added=%d updated=%d removed=%d unchanged=%d errors=%d",
+		summary.Added, summary.Updated, summary.Removed, summary.Unchanged, summary.Errors)
+	return summary, nil
+}
+
+// samePrice membandingkan harga dengan toleransi pembulatan DECIMAL di database
+func samePrice(a, b float64) bool {
+	return math.Abs(a-b) < 0.005
 }
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/imnzr/sim-service-project/config"
+	"github.com/imnzr/sim-service-project/helper"
+)
+
+// TokenClaims adalah claims JWT access/refresh token. exp, iat, nbf, iss, aud, sub dan jti
+// ada di RegisteredClaims dan divalidasi oleh jwt/v5 saat parsing.
+type TokenClaims struct {
+	UserId   uint   `json:"user_id"`
+	Email    string `json:"email,omitempty"`
+	Type     string `json:"type"`
+	Role     string `json:"role,omitempty"`
+	FamilyId string `json:"fid,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// tokenKeys menyimpan semua key HMAC yang masih diterima, key aktif dipakai untuk sign token baru
+type tokenKeys struct {
+	keys        map[string][]byte
+	activeKeyId string
+	issuer      string
+	audience    string
+}
+
+func newTokenKeys(cfg *config.AppConfig) tokenKeys {
+	keys := make(map[string][]byte, len(cfg.JWTKeys))
+	for kid, secret := range cfg.JWTKeys {
+		keys[kid] = []byte(secret)
+	}
+	return tokenKeys{
+		keys:        keys,
+		activeKeyId: cfg.JWTActiveKeyId,
+		issuer:      cfg.JWTIssuer,
+		audience:    cfg.JWTAudience,
+	}
+}
+
+// newClaims mengisi registered claims standar untuk token baru
+func (k tokenKeys) newClaims(userId uint, tokenType string, duration time.Duration) (TokenClaims, error) {
+	jti, err := helper.RandomToken(16)
+	if err != nil {
+		return TokenClaims{}, err
+	}
+
+	now := time.Now()
+	return TokenClaims{
+		UserId: userId,
+		Type:   tokenType,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    k.issuer,
+			Subject:   strconv.FormatUint(uint64(userId), 10),
+			Audience:  jwt.ClaimStrings{k.audience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(duration)),
+			NotBefore: jwt.NewNumericDate(now),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ID:        jti,
+		},
+	}, nil
+}
+
+// sign menandatangani claims dengan key aktif dan menulis kid di header
+func (k tokenKeys) sign(claims TokenClaims) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token.Header["kid"] = k.activeKeyId
+	return token.SignedString(k.keys[k.activeKeyId])
+}
+
+// parse memverifikasi signature (berdasarkan kid), exp, nbf, iat, iss dan aud
+func (k tokenKeys) parse(tokenString string) (*TokenClaims, error) {
+	claims := &TokenClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := k.keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key: %q", kid)
+		}
+		return key, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(k.issuer),
+		jwt.WithAudience(k.audience),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(30*time.Second),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/imnzr/sim-service-project/models"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrIncorrectPassword dikembalikan jika password konfirmasi tidak cocok
+var ErrIncorrectPassword = errors.New("current password is incorrect")
+
+// activeUser mengambil user yang belum dihapus, error jika tidak ditemukan
+func (service *UserServiceImplementation) activeUser(ctx context.Context, userId uint) (*models.User, error) {
+	user, err := service.UserRepo.GetUserById(ctx, userId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+	return user, nil
+}
+
+func checkPassword(user *models.User, password string) error {
+	if password == "" || bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)) != nil {
+		return ErrIncorrectPassword
+	}
+	return nil
+}
+
+// UpdateProfile implements UserService.
+func (service *UserServiceImplementation) UpdateProfile(ctx context.Context, userId uint, req *models.UpdateProfileRequest) (*models.UserProfileResponse, error) {
+	req.Username = strings.TrimSpace(req.Username)
+	if message := validateUsername(req.Username); message != "" {
+		return nil, &ValidationError{Fields: map[string]string{"username": message}}
+	}
+
+	before, err := service.activeUser(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	if err := service.UserRepo.UpdateUserUsername(ctx, userId, req.Username); err != nil {
+		return nil, err
+	}
+
+	after := *before
+	after.Username = req.Username
+	service.audit(ctx, userId, AuditActionUserProfileUpdate, userId, before, &after, nil)
+	return service.GetUserProfile(ctx, userId)
+}
+
+// ChangeEmail implements UserService.
+// Email baru disimpan sebagai pending_email dan baru menggantikan email lama setelah link verifikasi dibuka.
+func (service *UserServiceImplementation) ChangeEmail(ctx context.Context, userId uint, req *models.ChangeEmailRequest) error {
+	req.Email = normalizeEmail(req.Email)
+	if message := validateEmail(req.Email); message != "" {
+		return &ValidationError{Fields: map[string]string{"email": message}}
+	}
+
+	user, err := service.activeUser(ctx, userId)
+	if err != nil {
+		return err
+	}
+	if err := checkPassword(user, req.Password); err != nil {
+		return err
+	}
+	if req.Email == user.Email {
+		return &ValidationError{Fields: map[string]string{"email": "new email must be different from the current email"}}
+	}
+
+	existing, err := service.UserRepo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		return fmt.Errorf("failed to check email: %w", err)
+	}
+	if existing != nil {
+		return ErrEmailTaken
+	}
+
+	allowed, err := service.rateLimiter.Allow(ctx, fmt.Sprintf("resend_verification:%d", userId), service.verificationLimit, service.verificationWindow)
+	if err != nil {
+		return fmt.Errorf("failed to check rate limit: %w", err)
+	}
+	if !allowed {
+		return ErrTooManyRequests
+	}
+
+	if err := service.UserRepo.SetPendingEmail(ctx, userId, req.Email); err != nil {
+		return err
+	}
+	after := *user
+	after.PendingEmail = &req.Email
+	service.audit(ctx, userId, AuditActionUserEmailChange, userId, user, &after, nil)
+	return service.sendVerificationEmail(ctx, &after)
+}
+
+// ChangePassword implements UserService.
+// Sesi lain dicabut, sesi yang sedang dipakai tetap login.
+func (service *UserServiceImplementation) ChangePassword(ctx context.Context, claims *TokenClaims, req *models.ChangePasswordRequest) error {
+	user, err := service.activeUser(ctx, claims.UserId)
+	if err != nil {
+		return err
+	}
+	if err := checkPassword(user, req.CurrentPassword); err != nil {
+		return err
+	}
+	if message := validatePassword(req.NewPassword); message != "" {
+		return &ValidationError{Fields: map[string]string{"new_password": message}}
+	}
+
+	hashPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		log.Printf("error hashing password user: %v", err)
+		return errors.New("error hashing password")
+	}
+	if err := service.UserRepo.UpdatePassword(ctx, user.ID, string(hashPassword)); err != nil {
+		return err
+	}
+
+	if err := service.RefreshTokenRepo.RevokeAllForUserExcept(ctx, user.ID, claims.FamilyId); err != nil {
+		log.Printf("failed to revoke refresh tokens after password change for user %d: %v", user.ID, err)
+	}
+	if err := service.SessionRepo.RevokeAllForUserExcept(ctx, user.ID, claims.FamilyId); err != nil {
+		log.Printf("failed to revoke sessions after password change for user %d: %v", user.ID, err)
+	}
+	service.audit(ctx, user.ID, AuditActionUserPasswordChange, user.ID, nil, nil, map[string]any{"kept_session_id": claims.FamilyId})
+	return nil
+}
+
+// DeleteAccount implements UserService.
+// Akun di-soft delete dan dianonimkan, riwayat order tetap tersimpan.
+func (service *UserServiceImplementation) DeleteAccount(ctx context.Context, userId uint, req *models.DeleteAccountRequest) error {
+	user, err := service.activeUser(ctx, userId)
+	if err != nil {
+		return err
+	}
+	if err := checkPassword(user, req.Password); err != nil {
+		return err
+	}
+
+	if err := service.UserRepo.SoftDeleteUser(ctx, user.ID); err != nil {
+		return err
+	}
+
+	if err := service.RevokeAllSessions(ctx, user.ID); err != nil {
+		log.Printf("failed to revoke sessions after deleting user %d: %v", user.ID, err)
+	}
+	// snapshot tidak dicatat karena data pribadi user sudah dianonimkan
+	service.audit(ctx, user.ID, AuditActionUserDelete, user.ID, nil, nil, nil)
+	return nil
+}
@@ -5,10 +5,16 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/imnzr/sim-service-project/config"
+	"github.com/imnzr/sim-service-project/helper"
+	"github.com/imnzr/sim-service-project/internal/audit"
+	"github.com/imnzr/sim-service-project/internal/cache"
+	"github.com/imnzr/sim-service-project/internal/mailer"
 	"github.com/imnzr/sim-service-project/internal/repository"
 	"github.com/imnzr/sim-service-project/models"
 	"golang.org/x/crypto/bcrypt"
@@ -16,46 +22,115 @@ import (
 
 type UserService interface {
 	Register(ctx context.Context, req *models.RegisterUser) error
-	Login(ctx context.Context, req *models.LoginRequest) (*models.TokenResponse, error)
+	Login(ctx context.Context, req *models.LoginRequest, client models.ClientInfo) (*models.TokenResponse, error)
 	GetUserProfile(ctx context.Context, userId uint) (*models.UserProfileResponse, error)
-	GenerateAccessToken(userId uint, email string) (string, error)
-	GenerateRefreshToken(userId uint) (string, error)
-	ValidateToken(tokenString string) (jwt.MapClaims, error)
+	GenerateAccessToken(user *models.User, familyId string) (string, error)
+	GenerateRefreshToken(userId uint, familyId string) (string, error)
+	ValidateToken(tokenString string) (*TokenClaims, error)
+	RefreshToken(ctx context.Context, refreshToken string, client models.ClientInfo) (*models.TokenResponse, error)
+	IsTokenRevoked(ctx context.Context, claims *TokenClaims) (bool, error)
+	IsUserSuspended(ctx context.Context, userId uint) (bool, error)
+	Logout(ctx context.Context, claims *TokenClaims) error
+	LogoutAll(ctx context.Context, userId uint) error
+	RevokeAllSessions(ctx context.Context, userId uint) error
+	ListSessions(ctx context.Context, claims *TokenClaims) ([]models.UserSession, error)
+	ForgotPassword(ctx context.Context, req *models.ForgotPasswordRequest) error
+	ResetPassword(ctx context.Context, req *models.ResetPasswordRequest) error
+	VerifyEmail(ctx context.Context, req *models.VerifyEmailRequest) error
+	ResendVerification(ctx context.Context, userId uint) error
+	IsEmailVerified(ctx context.Context, userId uint) (bool, error)
+	UpdateProfile(ctx context.Context, userId uint, req *models.UpdateProfileRequest) (*models.UserProfileResponse, error)
+	ChangeEmail(ctx context.Context, userId uint, req *models.ChangeEmailRequest) error
+	ChangePassword(ctx context.Context, claims *TokenClaims, req *models.ChangePasswordRequest) error
+	DeleteAccount(ctx context.Context, userId uint, req *models.DeleteAccountRequest) error
 }
 
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+)
+
+// Aksi audit log untuk akun user, actor-nya adalah user itu sendiri
+const (
+	AuditActionUserRegister       = "user.register"
+	AuditActionUserLogin          = "user.login"
+	AuditActionUserLogout         = "user.logout"
+	AuditActionUserLogoutAll      = "user.logout_all"
+	AuditActionUserPasswordReset  = "user.password_reset"
+	AuditActionUserPasswordChange = "user.password_change"
+	AuditActionUserEmailChange    = "user.email_change_request"
+	AuditActionUserEmailVerify    = "user.email_verify"
+	AuditActionUserProfileUpdate  = "user.profile_update"
+	AuditActionUserDelete         = "user.delete"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token has already been used, all sessions from this login have been revoked")
+	ErrInvalidResetToken   = errors.New("invalid or expired password reset token")
+	ErrTooManyRequests     = errors.New("too many requests, please try again later")
+	ErrEmailTaken          = errors.New("email is already registered")
+	ErrInvalidCredentials  = errors.New("invalid email or password")
+	ErrAccountSuspended    = errors.New("account has been suspended")
+
+	ErrInvalidVerificationToken = errors.New("invalid or expired email verification token")
+	ErrEmailAlreadyVerified     = errors.New("email is already verified")
+	ErrEmailNotVerified         = errors.New("email is not verified")
+)
+
 type UserServiceImplementation struct {
 	UserRepo             repository.UserRepository
-	jwtSecretKey         []byte
+	RefreshTokenRepo     repository.RefreshTokenRepository
+	SessionRepo          repository.UserSessionRepository
+	denyList             *cache.TokenDenyList
+	rateLimiter          *cache.RateLimiter
+	mailer               mailer.Mailer
+	auditLogger          audit.Logger
+	tokenKeys            tokenKeys
 	accessTokenDuration  time.Duration
 	refreshTokenDuration time.Duration
+	appBaseURL           string
+	passwordResetTTL     time.Duration
+	passwordResetLimit   int
+	passwordResetWindow  time.Duration
+	verificationTTL      time.Duration
+	verificationLimit    int
+	verificationWindow   time.Duration
 }
 
-func NewUserService(userRepo repository.UserRepository, cfg *config.AppConfig) UserService {
+func NewUserService(userRepo repository.UserRepository, refreshTokenRepo repository.RefreshTokenRepository, sessionRepo repository.UserSessionRepository, denyList *cache.TokenDenyList, rateLimiter *cache.RateLimiter, mail mailer.Mailer, auditLogger audit.Logger, cfg *config.AppConfig) UserService {
 	return &UserServiceImplementation{
 		UserRepo:             userRepo,
-		jwtSecretKey:         []byte(cfg.JWTSecretKey),
+		RefreshTokenRepo:     refreshTokenRepo,
+		SessionRepo:          sessionRepo,
+		denyList:             denyList,
+		rateLimiter:          rateLimiter,
+		mailer:               mail,
+		auditLogger:          auditLogger,
+		tokenKeys:            newTokenKeys(cfg),
 		accessTokenDuration:  cfg.AccessTokenDuration,
 		refreshTokenDuration: cfg.RefreshTokenDuration,
+		appBaseURL:           strings.TrimRight(cfg.AppBaseURL, "/"),
+		passwordResetTTL:     cfg.PasswordResetTTL,
+		passwordResetLimit:   cfg.PasswordResetLimit,
+		passwordResetWindow:  cfg.PasswordResetWindow,
+		verificationTTL:      cfg.EmailVerificationTTL,
+		verificationLimit:    cfg.VerificationLimit,
+		verificationWindow:   cfg.VerificationWindow,
 	}
 }
 
 // ValidateToken implements UserService.
-func (service *UserServiceImplementation) ValidateToken(tokenString string) (jwt.MapClaims, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return service.jwtSecretKey, nil
-	})
-
+func (service *UserServiceImplementation) ValidateToken(tokenString string) (*TokenClaims, error) {
+	claims, err := service.tokenKeys.parse(tokenString)
 	if err != nil {
 		log.Printf("token validation: %v", err)
 		return nil, fmt.Errorf("invalid token: %w", err)
 	}
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		return claims, nil
+	if claims.UserId == 0 || claims.Type == "" {
+		return nil, errors.New("invalid token claims")
 	}
-	return nil, errors.New("invalid token claims")
+	return claims, nil
 }
 
 // GetUserProfile implements UserService.
@@ -66,78 +141,392 @@ func (service *UserServiceImplementation) GetUserProfile(ctx context.Context, us
 		return nil, fmt.Errorf("user profile not found")
 	}
 	response := models.UserProfileResponse{
-		ID:        user.ID,
-		Username:  user.Username,
-		Email:     user.Email,
-		CreatedAt: user.CreatedAt,
+		ID:            user.ID,
+		Username:      user.Username,
+		Email:         user.Email,
+		EmailVerified: user.EmailVerifiedAt != nil,
+		PendingEmail:  user.PendingEmail,
+		Role:          user.Role,
+		Balance:       user.Balance,
+		CreatedAt:     user.CreatedAt,
 	}
 
 	return &response, nil
 }
 
 // GenerateAccessToken implements UserService.
-func (service *UserServiceImplementation) GenerateAccessToken(userId uint, email string) (string, error) {
-	claims := jwt.MapClaims{
-		"user_id": userId,
-		"email":   email,
-		"expired": time.Now().Add(service.accessTokenDuration).Unix(),
+// familyId disimpan di access token agar logout bisa mencabut sesi yang sedang dipakai.
+func (service *UserServiceImplementation) GenerateAccessToken(user *models.User, familyId string) (string, error) {
+	claims, err := service.tokenKeys.newClaims(user.ID, TokenTypeAccess, service.accessTokenDuration)
+	if err != nil {
+		return "", err
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(service.jwtSecretKey)
+	claims.Email = user.Email
+	claims.Role = user.Role
+	claims.FamilyId = familyId
+	return service.tokenKeys.sign(claims)
 }
 
 // GenerateRefreshToken implements UserService.
-func (service *UserServiceImplementation) GenerateRefreshToken(userId uint) (string, error) {
-	claims := jwt.MapClaims{
-		"user_id": userId,
-		"expired": time.Now().Add(service.refreshTokenDuration).Unix(),
-		"type":    "refresh",
+// Setiap refresh token punya jti acak agar hash-nya unik walaupun dibuat di detik yang sama.
+func (service *UserServiceImplementation) GenerateRefreshToken(userId uint, familyId string) (string, error) {
+	claims, err := service.tokenKeys.newClaims(userId, TokenTypeRefresh, service.refreshTokenDuration)
+	if err != nil {
+		return "", err
+	}
+	claims.FamilyId = familyId
+	return service.tokenKeys.sign(claims)
+}
+
+// issueTokens membuat access token dan refresh token baru dalam family yang sama, lalu menyimpan hash refresh token
+func (service *UserServiceImplementation) issueTokens(ctx context.Context, user *models.User, familyId string) (*models.TokenResponse, error) {
+	accessToken, err := service.GenerateAccessToken(user, familyId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate access token: %w", err)
+	}
+
+	refreshToken, err := service.GenerateRefreshToken(user.ID, familyId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	err = service.RefreshTokenRepo.Create(ctx, &models.RefreshToken{
+		UserId:    user.ID,
+		FamilyId:  familyId,
+		TokenHash: helper.HashToken(refreshToken),
+		ExpiresAt: time.Now().Add(service.refreshTokenDuration),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to store refresh token: %w", err)
+	}
+
+	return &models.TokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+// RefreshToken implements UserService.
+// Refresh token hanya bisa dipakai sekali. Jika token yang sudah dirotasi dipakai lagi,
+// kemungkinan token dicuri sehingga seluruh family (satu sesi login) dicabut.
+func (service *UserServiceImplementation) RefreshToken(ctx context.Context, refreshToken string, client models.ClientInfo) (*models.TokenResponse, error) {
+	claims, err := service.ValidateToken(refreshToken)
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+	if claims.Type != TokenTypeRefresh {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	stored, err := service.RefreshTokenRepo.GetByHash(ctx, helper.HashToken(refreshToken))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get refresh token: %w", err)
+	}
+	if stored == nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if stored.UsedAt != nil || stored.RevokedAt != nil {
+		return nil, service.revokeReusedFamily(ctx, stored)
+	}
+	if time.Now().After(stored.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	// update atomic, jika gagal berarti ada request lain yang memakai token yang sama
+	ok, err := service.RefreshTokenRepo.MarkUsed(ctx, stored.Id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+	if !ok {
+		return nil, service.revokeReusedFamily(ctx, stored)
+	}
+
+	user, err := service.UserRepo.GetUserById(ctx, stored.UserId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil, ErrInvalidRefreshToken
+	}
+	if user.SuspendedAt != nil {
+		return nil, ErrAccountSuspended
+	}
+
+	if err := service.SessionRepo.Touch(ctx, stored.FamilyId, client); err != nil {
+		log.Printf("failed to update session %s: %v", stored.FamilyId, err)
+	}
+
+	return service.issueTokens(ctx, user, stored.FamilyId)
+}
+
+func (service *UserServiceImplementation) revokeReusedFamily(ctx context.Context, stored *models.RefreshToken) error {
+	log.Printf("refresh token reuse detected for user %d, revoking family %s", stored.UserId, stored.FamilyId)
+	if err := service.RefreshTokenRepo.RevokeFamily(ctx, stored.FamilyId); err != nil {
+		log.Printf("failed to revoke refresh token family %s: %v", stored.FamilyId, err)
+	}
+	if err := service.SessionRepo.Revoke(ctx, stored.FamilyId); err != nil {
+		log.Printf("failed to revoke session %s: %v", stored.FamilyId, err)
+	}
+	return ErrRefreshTokenReused
+}
+
+// IsTokenRevoked implements UserService.
+// Token dicabut jika jti-nya ada di deny list atau diterbitkan sebelum user melakukan logout-all.
+func (service *UserServiceImplementation) IsTokenRevoked(ctx context.Context, claims *TokenClaims) (bool, error) {
+	revoked, err := service.tokenRevokedInCache(ctx, claims)
+	if err == nil {
+		return revoked, nil
+	}
+
+	// redis tidak bisa diakses: logout, logout-all, ganti password dan suspend juga mencabut sesi
+	// (family) di database, jadi sesi token ini yang dicek. Token tanpa sesi tidak bisa dipastikan.
+	if claims.FamilyId == "" {
+		return false, err
+	}
+	log.Printf("token deny list unavailable, checking session %s in database: %v", claims.FamilyId, err)
+	revoked, dbErr := service.SessionRepo.IsRevoked(ctx, claims.FamilyId)
+	if dbErr != nil {
+		return false, fmt.Errorf("failed to check session: %w", dbErr)
+	}
+	return revoked, nil
+}
+
+// tokenRevokedInCache mengecek deny list jti dan batas pencabutan per user di redis
+func (service *UserServiceImplementation) tokenRevokedInCache(ctx context.Context, claims *TokenClaims) (bool, error) {
+	denied, err := service.denyList.IsDenied(ctx, claims.ID)
+	if err != nil {
+		return false, err
+	}
+	if denied {
+		return true, nil
+	}
+
+	revokedBefore, err := service.denyList.UserRevokedBefore(ctx, claims.UserId)
+	if err != nil {
+		return false, err
+	}
+	return claims.IssuedAt != nil && claims.IssuedAt.Time.Before(revokedBefore), nil
+}
+
+// IsUserSuspended implements UserService.
+// Dicek dari redis agar AuthMiddleware tidak perlu query database di setiap request.
+// Jika redis tidak bisa diakses, suspended_at di database yang dipakai.
+func (service *UserServiceImplementation) IsUserSuspended(ctx context.Context, userId uint) (bool, error) {
+	suspended, err := service.denyList.IsUserSuspended(ctx, userId)
+	if err == nil {
+		return suspended, nil
+	}
+	log.Printf("suspension flag unavailable for user %d, checking database: %v", userId, err)
+
+	user, dbErr := service.UserRepo.GetUserById(ctx, userId)
+	if dbErr != nil {
+		return false, fmt.Errorf("failed to check suspension: %w", dbErr)
+	}
+	// user yang sudah dihapus diperlakukan sama seperti suspended
+	return user == nil || user.SuspendedAt != nil, nil
+}
+
+// Logout implements UserService.
+// Mencabut access token yang sedang dipakai beserta sesi (family refresh token)-nya.
+func (service *UserServiceImplementation) Logout(ctx context.Context, claims *TokenClaims) error {
+	if claims.ExpiresAt != nil {
+		if err := service.denyList.Deny(ctx, claims.ID, claims.ExpiresAt.Time); err != nil {
+			return fmt.Errorf("failed to revoke access token: %w", err)
+		}
+	}
+	if claims.FamilyId != "" {
+		if err := service.RefreshTokenRepo.RevokeFamily(ctx, claims.FamilyId); err != nil {
+			return fmt.Errorf("failed to revoke refresh token: %w", err)
+		}
+		if err := service.SessionRepo.Revoke(ctx, claims.FamilyId); err != nil {
+			return fmt.Errorf("failed to revoke session: %w", err)
+		}
+	}
+
+	service.audit(ctx, claims.UserId, AuditActionUserLogout, claims.UserId, nil, nil, map[string]any{"session_id": claims.FamilyId})
+	return nil
+}
+
+// LogoutAll implements UserService.
+// Logout dari semua perangkat atas permintaan user sendiri.
+func (service *UserServiceImplementation) LogoutAll(ctx context.Context, userId uint) error {
+	if err := service.RevokeAllSessions(ctx, userId); err != nil {
+		return err
+	}
+	service.audit(ctx, userId, AuditActionUserLogoutAll, userId, nil, nil, nil)
+	return nil
+}
+
+// RevokeAllSessions implements UserService.
+// Semua refresh token user dicabut dan access token yang terbit sebelum saat ini ditolak.
+// Tidak mencatat audit log, pemanggil (reset password, hapus akun, suspend) mencatat aksinya sendiri.
+func (service *UserServiceImplementation) RevokeAllSessions(ctx context.Context, userId uint) error {
+	// sesi di database dicabut lebih dulu, AuthMiddleware memakainya saat redis tidak bisa diakses
+	revokedAt := time.Now()
+	if err := service.RefreshTokenRepo.RevokeAllForUser(ctx, userId); err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+	if err := service.SessionRepo.RevokeAllForUser(ctx, userId); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	if err := service.denyList.RevokeUserBefore(ctx, userId, revokedAt, service.accessTokenDuration); err != nil {
+		return fmt.Errorf("failed to revoke access tokens: %w", err)
+	}
+	return nil
+}
+
+// ListSessions implements UserService.
+func (service *UserServiceImplementation) ListSessions(ctx context.Context, claims *TokenClaims) ([]models.UserSession, error) {
+	sessions, err := service.SessionRepo.FindActiveByUser(ctx, claims.UserId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sessions: %w", err)
+	}
+	for i := range sessions {
+		sessions[i].Current = sessions[i].FamilyId == claims.FamilyId
+	}
+	return sessions, nil
+}
+
+// ForgotPassword implements UserService.
+// Selalu berhasil walaupun email tidak terdaftar agar endpoint tidak bisa dipakai untuk mengecek email.
+// Limit dihitung per email, bukan per IP, supaya inbox korban tidak dibanjiri.
+func (service *UserServiceImplementation) ForgotPassword(ctx context.Context, req *models.ForgotPasswordRequest) error {
+	email := normalizeEmail(req.Email)
+	if email == "" {
+		return nil
+	}
+
+	allowed, err := service.rateLimiter.Allow(ctx, "forgot_password:"+helper.HashToken(email), service.passwordResetLimit, service.passwordResetWindow)
+	if err != nil {
+		return fmt.Errorf("failed to check rate limit: %w", err)
+	}
+	if !allowed {
+		return ErrTooManyRequests
+	}
+
+	user, err := service.UserRepo.GetUserByEmail(ctx, email)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil
+	}
+
+	token, err := helper.RandomToken(32)
+	if err != nil {
+		return fmt.Errorf("failed to generate reset token: %w", err)
+	}
+	expiresAt := time.Now().Add(service.passwordResetTTL)
+	if err := service.UserRepo.SetPasswordResetToken(ctx, user.ID, helper.HashToken(token), expiresAt); err != nil {
+		return err
+	}
+
+	link := service.appBaseURL + "/reset-password?token=" + url.QueryEscape(token)
+	return service.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nUse the link below to reset your password. The link expires in %s and can only be used once.\n\n%s\n\nIf you did not request this, you can ignore this email.",
+			user.Username, service.passwordResetTTL, link),
+	})
+}
+
+// ResetPassword implements UserService.
+// Setelah password diganti semua sesi user dicabut.
+func (service *UserServiceImplementation) ResetPassword(ctx context.Context, req *models.ResetPasswordRequest) error {
+	if req.Token == "" {
+		return ErrInvalidResetToken
+	}
+	if message := validatePassword(req.Password); message != "" {
+		return &ValidationError{Fields: map[string]string{"password": message}}
+	}
+
+	tokenHash := helper.HashToken(req.Token)
+	user, err := service.UserRepo.GetUserByPasswordResetToken(ctx, tokenHash)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil || user.PasswordResetExpiresAt == nil || time.Now().After(*user.PasswordResetExpiresAt) {
+		return ErrInvalidResetToken
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(service.jwtSecretKey)
+
+	hashPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		log.Printf("error hashing password user: %v", err)
+		return errors.New("error hashing password")
+	}
+
+	ok, err := service.UserRepo.ResetPassword(ctx, user.ID, tokenHash, string(hashPassword))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidResetToken
+	}
+
+	if err := service.RevokeAllSessions(ctx, user.ID); err != nil {
+		log.Printf("failed to revoke sessions after password reset for user %d: %v", user.ID, err)
+	}
+	service.audit(ctx, user.ID, AuditActionUserPasswordReset, user.ID, nil, nil, nil)
+	return nil
 }
 
 // Login implements UserService.
-func (service *UserServiceImplementation) Login(ctx context.Context, req *models.LoginRequest) (*models.TokenResponse, error) {
+func (service *UserServiceImplementation) Login(ctx context.Context, req *models.LoginRequest, client models.ClientInfo) (*models.TokenResponse, error) {
+	if err := ValidateLogin(req); err != nil {
+		return nil, err
+	}
+
 	user, err := service.UserRepo.GetUserByEmail(ctx, req.Email)
 	if err != nil {
-		return nil, errors.New("invalid credentials")
+		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 	if user == nil {
 		log.Printf("no user found for email: %s", req.Email)
-		return nil, fmt.Errorf("invalid email or password")
+		return nil, ErrInvalidCredentials
 	}
 
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password))
 	if err != nil {
-		return nil, errors.New("invalid credentiasl")
+		return nil, ErrInvalidCredentials
+	}
+	// status suspend baru diberitahu setelah password benar agar tidak membocorkan info akun
+	if user.SuspendedAt != nil {
+		return nil, ErrAccountSuspended
 	}
 
-	// generate access token
-	accessToken, err := service.GenerateAccessToken(user.ID, user.Email)
+	// setiap login memulai family refresh token baru
+	familyId, err := helper.RandomToken(16)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate access token: %w", err)
+		return nil, fmt.Errorf("failed to generate token family: %w", err)
 	}
 
-	// generate refresh token
-	refreshToken, err := service.GenerateRefreshToken(user.ID)
+	err = service.SessionRepo.Create(ctx, &models.UserSession{
+		UserId:    user.ID,
+		FamilyId:  familyId,
+		UserAgent: client.UserAgent,
+		IPAddress: client.IPAddress,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+		return nil, fmt.Errorf("failed to create session: %w", err)
 	}
 
-	return &models.TokenResponse{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-	}, nil
+	service.audit(ctx, user.ID, AuditActionUserLogin, user.ID, nil, nil, map[string]any{"session_id": familyId})
+	return service.issueTokens(ctx, user, familyId)
 }
 
 // Register implements UserService.
 func (service *UserServiceImplementation) Register(ctx context.Context, req *models.RegisterUser) error {
+	if err := ValidateRegister(req); err != nil {
+		return err
+	}
 
-	_, err := service.UserRepo.GetUserByEmail(ctx, req.Email)
+	existing, err := service.UserRepo.GetUserByEmail(ctx, req.Email)
 	if err != nil {
-		log.Printf("User already existed")
-		return errors.New("user already existed")
+		return fmt.Errorf("failed to check email: %w", err)
+	}
+	if existing != nil {
+		return ErrEmailTaken
 	}
 
 	hashPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
@@ -150,9 +539,133 @@ func (service *UserServiceImplementation) Register(ctx context.Context, req *mod
 		Username:  req.Username,
 		Email:     req.Email,
 		Password:  string(hashPassword),
+		Role:      models.RoleCustomer,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
 
-	return service.UserRepo.CreateUser(ctx, user)
+	// unique index tetap menjadi penjaga terakhir jika ada dua register bersamaan
+	if err := service.UserRepo.CreateUser(ctx, user); err != nil {
+		if errors.Is(err, repository.ErrDuplicateEntry) {
+			return ErrEmailTaken
+		}
+		return err
+	}
+	service.audit(ctx, user.ID, AuditActionUserRegister, user.ID, nil, user, nil)
+
+	// akun tetap dibuat walaupun email gagal terkirim, user bisa meminta kirim ulang
+	if err := service.sendVerificationEmail(ctx, user); err != nil {
+		log.Printf("failed to send verification email to user %d: %v", user.ID, err)
+	}
+	return nil
+}
+
+// sendVerificationEmail membuat token verifikasi baru (token lama tidak berlaku) dan mengirimkannya
+// ke pending email jika user sedang mengganti email, atau ke email saat ini
+func (service *UserServiceImplementation) sendVerificationEmail(ctx context.Context, user *models.User) error {
+	to := user.Email
+	if user.PendingEmail != nil {
+		to = *user.PendingEmail
+	}
+
+	token, err := helper.RandomToken(32)
+	if err != nil {
+		return fmt.Errorf("failed to generate verification token: %w", err)
+	}
+	expiresAt := time.Now().Add(service.verificationTTL)
+	if err := service.UserRepo.SetEmailVerificationToken(ctx, user.ID, helper.HashToken(token), expiresAt); err != nil {
+		return err
+	}
+
+	link := service.appBaseURL + "/verify-email?token=" + url.QueryEscape(token)
+	return service.mailer.Send(ctx, mailer.Message{
+		To:      to,
+		Subject: "Verify your email",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease verify your email by opening the link below. The link expires in %s.\n\n%s",
+			user.Username, service.verificationTTL, link),
+	})
+}
+
+// VerifyEmail implements UserService.
+func (service *UserServiceImplementation) VerifyEmail(ctx context.Context, req *models.VerifyEmailRequest) error {
+	if req.Token == "" {
+		return ErrInvalidVerificationToken
+	}
+
+	tokenHash := helper.HashToken(req.Token)
+	before, err := service.UserRepo.GetUserByEmailVerificationToken(ctx, tokenHash)
+	if err != nil {
+		return err
+	}
+	if before == nil {
+		return ErrInvalidVerificationToken
+	}
+
+	ok, err := service.UserRepo.VerifyEmail(ctx, tokenHash)
+	if err != nil {
+		// pending email sudah didaftarkan user lain sejak permintaan ganti email
+		if errors.Is(err, repository.ErrDuplicateEntry) {
+			return ErrEmailTaken
+		}
+		return err
+	}
+	if !ok {
+		return ErrInvalidVerificationToken
+	}
+
+	after, err := service.UserRepo.GetUserById(ctx, before.ID)
+	if err != nil {
+		log.Printf("failed to read user %d for audit snapshot: %v", before.ID, err)
+	}
+	service.audit(ctx, before.ID, AuditActionUserEmailVerify, before.ID, before, after, nil)
+	return nil
+}
+
+// ResendVerification implements UserService.
+func (service *UserServiceImplementation) ResendVerification(ctx context.Context, userId uint) error {
+	user, err := service.UserRepo.GetUserById(ctx, userId)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return errors.New("user not found")
+	}
+	if user.EmailVerifiedAt != nil && user.PendingEmail == nil {
+		return ErrEmailAlreadyVerified
+	}
+
+	allowed, err := service.rateLimiter.Allow(ctx, fmt.Sprintf("resend_verification:%d", userId), service.verificationLimit, service.verificationWindow)
+	if err != nil {
+		return fmt.Errorf("failed to check rate limit: %w", err)
+	}
+	if !allowed {
+		return ErrTooManyRequests
+	}
+
+	return service.sendVerificationEmail(ctx, user)
+}
+
+// IsEmailVerified implements UserService.
+func (service *UserServiceImplementation) IsEmailVerified(ctx context.Context, userId uint) (bool, error) {
+	user, err := service.UserRepo.GetUserById(ctx, userId)
+	if err != nil {
+		return false, fmt.Errorf("failed to get user: %w", err)
+	}
+	return user != nil && user.EmailVerifiedAt != nil, nil
+}
+
+// audit mencatat aksi pada akun user. Kegagalan hanya di-log agar login dan alur akun lain tetap jalan.
+func (service *UserServiceImplementation) audit(ctx context.Context, actorId uint, action string, userId uint, before, after *models.User, metadata any) {
+	err := service.auditLogger.Record(ctx, audit.Entry{
+		ActorUserId: actorId,
+		Action:      action,
+		TargetType:  audit.TargetUser,
+		TargetId:    strconv.FormatUint(uint64(userId), 10),
+		Before:      audit.UserSnapshot(before),
+		After:       audit.UserSnapshot(after),
+		Metadata:    metadata,
+	})
+	if err != nil {
+		log.Printf("failed to write audit log %s for user %d: %v", action, userId, err)
+	}
 }
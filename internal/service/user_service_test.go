@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/imnzr/sim-service-project/config"
+	"github.com/imnzr/sim-service-project/internal/repository"
+	"github.com/imnzr/sim-service-project/models"
+)
+
+// fakeRefreshTokenRepo menyimpan refresh token di memori, MarkUsed atomic seperti UPDATE ... WHERE used_at IS NULL
+type fakeRefreshTokenRepo struct {
+	repository.RefreshTokenRepository
+	mu     sync.Mutex
+	tokens []*models.RefreshToken
+}
+
+func (f *fakeRefreshTokenRepo) Create(ctx context.Context, token *models.RefreshToken) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	token.Id = int64(len(f.tokens) + 1)
+	stored := *token
+	f.tokens = append(f.tokens, &stored)
+	return nil
+}
+
+func (f *fakeRefreshTokenRepo) GetByHash(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, token := range f.tokens {
+		if token.TokenHash == tokenHash {
+			copied := *token
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeRefreshTokenRepo) MarkUsed(ctx context.Context, id int64) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, token := range f.tokens {
+		if token.Id == id && token.UsedAt == nil && token.RevokedAt == nil {
+			now := time.Now()
+			token.UsedAt = &now
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakeRefreshTokenRepo) RevokeFamily(ctx context.Context, familyId string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	now := time.Now()
+	for _, token := range f.tokens {
+		if token.FamilyId == familyId && token.RevokedAt == nil {
+			token.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+type fakeSessionRepo struct {
+	repository.UserSessionRepository
+	mu      sync.Mutex
+	revoked map[string]bool
+}
+
+func (f *fakeSessionRepo) Touch(ctx context.Context, familyId string, client models.ClientInfo) error {
+	return nil
+}
+
+func (f *fakeSessionRepo) Revoke(ctx context.Context, familyId string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.revoked[familyId] = true
+	return nil
+}
+
+type fakeUserRepo struct {
+	repository.UserRepository
+	users map[uint]*models.User
+}
+
+func (f *fakeUserRepo) GetUserById(ctx context.Context, id uint) (*models.User, error) {
+	return f.users[id], nil
+}
+
+func newRefreshTestService(t *testing.T) (*UserServiceImplementation, *fakeRefreshTokenRepo, *fakeSessionRepo) {
+	t.Helper()
+	tokens := &fakeRefreshTokenRepo{}
+	sessions := &fakeSessionRepo{revoked: map[string]bool{}}
+	users := &fakeUserRepo{users: map[uint]*models.User{
+		1: {ID: 1, Username: "reseller", Email: "reseller@example.com", Role: models.RoleReseller},
+	}}
+	cfg := &config.AppConfig{
+		JWTKeys:              map[string]string{"test": "test-secret"},
+		JWTActiveKeyId:       "test",
+		JWTIssuer:            "sim-service",
+		JWTAudience:          "sim-service-api",
+		AccessTokenDuration:  15 * time.Minute,
+		RefreshTokenDuration: time.Hour,
+	}
+	service := NewUserService(users, tokens, sessions, nil, nil, nil, nil, cfg).(*UserServiceImplementation)
+	return service, tokens, sessions
+}
+
+func TestRefreshTokenRotation(t *testing.T) {
+	service, tokens, sessions := newRefreshTestService(t)
+	ctx := context.Background()
+
+	issued, err := service.issueTokens(ctx, &models.User{ID: 1, Role: models.RoleReseller}, "family-1")
+	if err != nil {
+		t.Fatalf("issueTokens: %v", err)
+	}
+
+	rotated, err := service.RefreshToken(ctx, issued.RefreshToken, models.ClientInfo{})
+	if err != nil {
+		t.Fatalf("first refresh should succeed: %v", err)
+	}
+	if rotated.RefreshToken == issued.RefreshToken {
+		t.Fatal("refresh must issue a new refresh token")
+	}
+	if len(tokens.tokens) != 2 || tokens.tokens[0].UsedAt == nil {
+		t.Fatal("old refresh token must be marked used and a new one stored")
+	}
+	if tokens.tokens[1].FamilyId != "family-1" {
+		t.Fatalf("rotated token must stay in the same family, got %q", tokens.tokens[1].FamilyId)
+	}
+	if sessions.revoked["family-1"] {
+		t.Fatal("normal rotation must not revoke the session")
+	}
+}
+
+func TestRefreshTokenReuseRevokesFamily(t *testing.T) {
+	service, tokens, sessions := newRefreshTestService(t)
+	ctx := context.Background()
+
+	issued, err := service.issueTokens(ctx, &models.User{ID: 1, Role: models.RoleReseller}, "family-1")
+	if err != nil {
+		t.Fatalf("issueTokens: %v", err)
+	}
+	rotated, err := service.RefreshToken(ctx, issued.RefreshToken, models.ClientInfo{})
+	if err != nil {
+		t.Fatalf("first refresh should succeed: %v", err)
+	}
+
+	// token lama dipakai lagi, misalnya oleh pencuri token
+	if _, err := service.RefreshToken(ctx, issued.RefreshToken, models.ClientInfo{}); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("reused refresh token: expected ErrRefreshTokenReused, got %v", err)
+	}
+	for _, token := range tokens.tokens {
+		if token.RevokedAt == nil {
+			t.Fatalf("refresh token %d in the reused family must be revoked", token.Id)
+		}
+	}
+	if !sessions.revoked["family-1"] {
+		t.Fatal("session of the reused family must be revoked")
+	}
+
+	// token hasil rotasi yang sah ikut tidak bisa dipakai
+	if _, err := service.RefreshToken(ctx, rotated.RefreshToken, models.ClientInfo{}); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("token from a revoked family: expected ErrRefreshTokenReused, got %v", err)
+	}
+}
+
+func TestRefreshTokenConcurrentUseOnlyOneWins(t *testing.T) {
+	service, _, sessions := newRefreshTestService(t)
+	ctx := context.Background()
+
+	issued, err := service.issueTokens(ctx, &models.User{ID: 1, Role: models.RoleReseller}, "family-1")
+	if err != nil {
+		t.Fatalf("issueTokens: %v", err)
+	}
+
+	const attempts = 5
+	results := make(chan error, attempts)
+	var wg sync.WaitGroup
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := service.RefreshToken(ctx, issued.RefreshToken, models.ClientInfo{})
+			results <- err
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	succeeded := 0
+	for err := range results {
+		switch {
+		case err == nil:
+			succeeded++
+		case !errors.Is(err, ErrRefreshTokenReused):
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if succeeded > 1 {
+		t.Fatalf("refresh token used %d times, expected at most once", succeeded)
+	}
+	if !sessions.revoked["family-1"] {
+		t.Fatal("concurrent reuse must revoke the family")
+	}
+}
+
+func TestRefreshTokenRejectsAccessToken(t *testing.T) {
+	service, _, _ := newRefreshTestService(t)
+	ctx := context.Background()
+
+	issued, err := service.issueTokens(ctx, &models.User{ID: 1, Role: models.RoleReseller}, "family-1")
+	if err != nil {
+		t.Fatalf("issueTokens: %v", err)
+	}
+	if _, err := service.RefreshToken(ctx, issued.AccessToken, models.ClientInfo{}); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("access token used as refresh token: expected ErrInvalidRefreshToken, got %v", err)
+	}
+}
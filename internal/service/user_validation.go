@@ -0,0 +1,129 @@
+package service
+
+import (
+	"net/mail"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/imnzr/sim-service-project/models"
+)
+
+const (
+	minUsernameLength = 3
+	maxUsernameLength = 30
+	maxEmailLength    = 254
+	minPasswordLength = 8
+	// bcrypt hanya memakai 72 byte pertama, password yang lebih panjang ditolak agar tidak terpotong diam-diam
+	maxPasswordLength = 72
+)
+
+var usernamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.]*$`)
+
+// ValidationError berisi pesan error per field, dikembalikan controller sebagai 422
+type ValidationError struct {
+	Fields map[string]string
+}
+
+func (v *ValidationError) Error() string {
+	return "validation failed"
+}
+
+// fieldErrors mengumpulkan error per field, hanya error pertama tiap field yang disimpan
+type fieldErrors map[string]string
+
+func (f fieldErrors) add(field, message string) {
+	if _, exists := f[field]; !exists && message != "" {
+		f[field] = message
+	}
+}
+
+func (f fieldErrors) err() error {
+	if len(f) == 0 {
+		return nil
+	}
+	return &ValidationError{Fields: f}
+}
+
+// normalizeEmail dipakai di semua tempat yang mencari user berdasarkan email
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func validateEmail(email string) string {
+	switch {
+	case email == "":
+		return "email is required"
+	case len(email) > maxEmailLength:
+		return "email is too long"
+	}
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email || !strings.Contains(email[strings.LastIndex(email, "@")+1:], ".") {
+		return "email format is invalid"
+	}
+	return ""
+}
+
+func validateUsername(username string) string {
+	switch {
+	case username == "":
+		return "username is required"
+	case len(username) < minUsernameLength || len(username) > maxUsernameLength:
+		return "username must be between 3 and 30 characters"
+	case !usernamePattern.MatchString(username):
+		return "username may only contain letters, numbers, underscores and dots, and must start with a letter or number"
+	}
+	return ""
+}
+
+// validatePassword menerapkan password policy: 8-72 karakter, ada huruf besar, huruf kecil dan angka
+func validatePassword(password string) string {
+	switch {
+	case password == "":
+		return "password is required"
+	case len(password) < minPasswordLength:
+		return "password must be at least 8 characters"
+	case len(password) > maxPasswordLength:
+		return "password must be at most 72 characters"
+	}
+
+	var hasUpper, hasLower, hasDigit bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		}
+	}
+	if !hasUpper || !hasLower || !hasDigit {
+		return "password must contain an uppercase letter, a lowercase letter and a number"
+	}
+	return ""
+}
+
+// ValidateRegister menormalisasi dan memvalidasi payload register
+func ValidateRegister(req *models.RegisterUser) error {
+	req.Email = normalizeEmail(req.Email)
+	req.Username = strings.TrimSpace(req.Username)
+
+	errs := fieldErrors{}
+	errs.add("username", validateUsername(req.Username))
+	errs.add("email", validateEmail(req.Email))
+	errs.add("password", validatePassword(req.Password))
+	return errs.err()
+}
+
+// ValidateLogin hanya mengecek format, password policy tidak diterapkan agar akun lama tetap bisa login
+func ValidateLogin(req *models.LoginRequest) error {
+	req.Email = normalizeEmail(req.Email)
+
+	errs := fieldErrors{}
+	errs.add("email", validateEmail(req.Email))
+	if req.Password == "" {
+		errs.add("password", "password is required")
+	}
+	return errs.err()
+}
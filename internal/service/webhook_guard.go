@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// errWebhookBlockedAddress dikembalikan saat webhook mengarah ke alamat jaringan internal
+var errWebhookBlockedAddress = errors.New("webhook destination resolves to a blocked address")
+
+// carrier-grade NAT (100.64.0.0/10) tidak dianggap private oleh net.IP tapi tetap bukan alamat publik
+var cgnatNetwork = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// isBlockedWebhookIP: loopback, private (termasuk ULA fc00::/7), link-local (termasuk 169.254.169.254),
+// multicast dan unspecified tidak boleh jadi tujuan webhook agar user tidak bisa memanggil layanan internal.
+func isBlockedWebhookIP(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+		if ip4[0] == 0 || cgnatNetwork.Contains(ip4) {
+			return true
+		}
+	}
+	return ip.IsLoopback() ||
+		ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() ||
+		ip.IsUnspecified()
+}
+
+// checkWebhookHost me-resolve host dan menolak jika salah satu alamatnya diblokir
+func checkWebhookHost(ctx context.Context, host string) error {
+	if ip := net.ParseIP(host); ip != nil {
+		if isBlockedWebhookIP(ip) {
+			return errWebhookBlockedAddress
+		}
+		return nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return fmt.Errorf("failed to resolve webhook host: %w", err)
+	}
+	if len(addrs) == 0 {
+		return fmt.Errorf("webhook host %s has no addresses", host)
+	}
+	for _, addr := range addrs {
+		if isBlockedWebhookIP(addr.IP) {
+			return errWebhookBlockedAddress
+		}
+	}
+	return nil
+}
+
+// newWebhookHTTPClient membuat client yang mengecek alamat tujuan saat dial, setelah DNS di-resolve,
+// sehingga DNS rebinding tidak bisa melewati validasi saat endpoint dibuat. Redirect tidak diikuti
+// dan proxy dari environment tidak dipakai karena akan melewati pengecekan alamat.
+func newWebhookHTTPClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || isBlockedWebhookIP(ip) {
+				return errWebhookBlockedAddress
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
@@ -0,0 +1,428 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/imnzr/sim-service-project/config"
+	"github.com/imnzr/sim-service-project/helper"
+	"github.com/imnzr/sim-service-project/internal/repository"
+	"github.com/imnzr/sim-service-project/models"
+)
+
+const (
+	webhookSecretPrefix        = "whsec_"
+	webhookSecretRandomBytes   = 24
+	webhookEventIdPrefix       = "evt_"
+	webhookEventIdRandomBytes  = 12
+	maxWebhookURLLength        = 500
+	maxWebhookDescriptionLen   = 255
+	maxWebhookResponseLength   = 1000
+	maxWebhookErrorLength      = 500
+	maxWebhookBackoff          = 6 * time.Hour
+	webhookDueBatchSize        = 100
+	defaultWebhookDeliveryPage = 20
+	maxWebhookDeliveryPage     = 100
+)
+
+var (
+	ErrWebhookNotFound  = errors.New("webhook endpoint not found")
+	ErrTooManyWebhooks  = errors.New("maximum number of webhook endpoints reached")
+	ErrWebhookPingError = errors.New("webhook endpoint did not accept the ping")
+)
+
+type WebhookService interface {
+	CreateEndpoint(ctx context.Context, userId uint, req *models.CreateWebhookEndpointRequest) (*models.CreateWebhookEndpointResponse, error)
+	ListEndpoints(ctx context.Context, userId uint) ([]models.WebhookEndpoint, error)
+	DeleteEndpoint(ctx context.Context, userId uint, endpointId int64) error
+	ListDeliveries(ctx context.Context, userId uint, endpointId int64, page, limit int) (*models.CatalogPage[models.WebhookDelivery], error)
+	Ping(ctx context.Context, userId uint, endpointId int64) (*models.WebhookDelivery, error)
+	DispatchOrder(ctx context.Context, event string, order *models.SimOrder)
+	ProcessDue(ctx context.Context) (int, error)
+}
+
+type WebhookServiceImplementation struct {
+	Repo        repository.WebhookRepository
+	client      *http.Client
+	maxPerUser  int
+	maxAttempts int
+	backoff     time.Duration
+	allowHTTP   bool
+}
+
+func NewWebhookService(repo repository.WebhookRepository, cfg config.AppConfig) WebhookService {
+	return &WebhookServiceImplementation{
+		Repo:        repo,
+		client:      newWebhookHTTPClient(cfg.WebhookTimeout),
+		maxPerUser:  cfg.WebhookMaxPerUser,
+		maxAttempts: cfg.WebhookMaxAttempts,
+		backoff:     cfg.WebhookRetryBackoff,
+		allowHTTP:   cfg.WebhookAllowHTTP,
+	}
+}
+
+func (w *WebhookServiceImplementation) validateCreate(ctx context.Context, req *models.CreateWebhookEndpointRequest) error {
+	req.URL = strings.TrimSpace(req.URL)
+	req.Description = strings.TrimSpace(req.Description)
+
+	errs := fieldErrors{}
+	errs.add("url", w.validateURL(ctx, req.URL))
+	if len(req.Description) > maxWebhookDescriptionLen {
+		errs.add("description", "description must be at most 255 characters")
+	}
+
+	// events kosong berarti semua event
+	events := []string{}
+	for _, event := range req.Events {
+		event = strings.TrimSpace(event)
+		if !models.ValidWebhookEvent(event) {
+			errs.add("events", fmt.Sprintf("unknown event %q, allowed: %s", event, strings.Join(models.WebhookEvents, ", ")))
+			break
+		}
+		if !slices.Contains(events, event) {
+			events = append(events, event)
+		}
+	}
+	req.Events = events
+	return errs.err()
+}
+
+func (w *WebhookServiceImplementation) validateURL(ctx context.Context, raw string) string {
+	if raw == "" {
+		return "url is required"
+	}
+	if len(raw) > maxWebhookURLLength {
+		return "url must be at most 500 characters"
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Host == "" {
+		return "url must be an absolute URL"
+	}
+	if parsed.User != nil {
+		return "url must not contain credentials"
+	}
+	if parsed.Scheme != "https" && (parsed.Scheme != "http" || !w.allowHTTP) {
+		return "url must use https"
+	}
+	// alamat dicek lagi saat dial, di sini hanya agar user langsung dapat pesan yang jelas
+	if err := checkWebhookHost(ctx, parsed.Hostname()); err != nil {
+		if errors.Is(err, errWebhookBlockedAddress) {
+			return "url must not point to a private or internal address"
+		}
+		return "url host could not be resolved"
+	}
+	return ""
+}
+
+// CreateEndpoint implements WebhookService.
+// Secret hanya dikembalikan sekali, dipakai penerima untuk memverifikasi signature.
+func (w *WebhookServiceImplementation) CreateEndpoint(ctx context.Context, userId uint, req *models.CreateWebhookEndpointRequest) (*models.CreateWebhookEndpointResponse, error) {
+	if err := w.validateCreate(ctx, req); err != nil {
+		return nil, err
+	}
+
+	count, err := w.Repo.CountEndpointsByUser(ctx, userId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count webhook endpoints: %w", err)
+	}
+	if count >= w.maxPerUser {
+		return nil, ErrTooManyWebhooks
+	}
+
+	random, err := helper.RandomToken(webhookSecretRandomBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+
+	endpoint := &models.WebhookEndpoint{
+		UserId: userId,
+		URL:    req.URL,
+		Secret: webhookSecretPrefix + random,
+		Events: req.Events,
+	}
+	if req.Description != "" {
+		endpoint.Description = &req.Description
+	}
+	if err := w.Repo.CreateEndpoint(ctx, endpoint); err != nil {
+		return nil, fmt.Errorf("failed to create webhook endpoint: %w", err)
+	}
+
+	created, err := w.Repo.GetEndpoint(ctx, endpoint.Id)
+	if err != nil || created == nil {
+		return nil, fmt.Errorf("failed to read webhook endpoint: %w", err)
+	}
+	return &models.CreateWebhookEndpointResponse{Endpoint: *created, Secret: endpoint.Secret}, nil
+}
+
+// ListEndpoints implements WebhookService.
+func (w *WebhookServiceImplementation) ListEndpoints(ctx context.Context, userId uint) ([]models.WebhookEndpoint, error) {
+	return w.Repo.FindEndpointsByUser(ctx, userId)
+}
+
+// DeleteEndpoint implements WebhookService.
+func (w *WebhookServiceImplementation) DeleteEndpoint(ctx context.Context, userId uint, endpointId int64) error {
+	deleted, err := w.Repo.DeleteEndpoint(ctx, userId, endpointId)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook endpoint: %w", err)
+	}
+	if !deleted {
+		return ErrWebhookNotFound
+	}
+	return nil
+}
+
+// ListDeliveries implements WebhookService.
+func (w *WebhookServiceImplementation) ListDeliveries(ctx context.Context, userId uint, endpointId int64, page int, limit int) (*models.CatalogPage[models.WebhookDelivery], error) {
+	if _, err := w.getOwnedEndpoint(ctx, userId, endpointId); err != nil {
+		return nil, err
+	}
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = defaultWebhookDeliveryPage
+	}
+	if limit > maxWebhookDeliveryPage {
+		limit = maxWebhookDeliveryPage
+	}
+
+	deliveries, total, err := w.Repo.FindDeliveries(ctx, endpointId, page, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load webhook deliveries: %w", err)
+	}
+	return &models.CatalogPage[models.WebhookDelivery]{Items: deliveries, Page: page, Limit: limit, Total: total}, nil
+}
+
+// Ping implements WebhookService.
+// Ping dikirim langsung sekali tanpa retry, hasilnya tetap masuk log pengiriman.
+func (w *WebhookServiceImplementation) Ping(ctx context.Context, userId uint, endpointId int64) (*models.WebhookDelivery, error) {
+	endpoint, err := w.getOwnedEndpoint(ctx, userId, endpointId)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery, err := w.createDelivery(ctx, endpoint, models.WebhookEventPing, map[string]any{
+		"endpoint_id": endpoint.Id,
+		"message":     "webhook endpoint is reachable",
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if !w.attempt(ctx, endpoint, delivery, false) {
+		return delivery, ErrWebhookPingError
+	}
+	return delivery, nil
+}
+
+// DispatchOrder implements WebhookService.
+// Delivery disimpan dulu lalu dikirim di background, yang gagal akan dicoba lagi oleh scheduler.
+// Kegagalan hanya di-log agar alur order tidak ikut gagal karena webhook.
+func (w *WebhookServiceImplementation) DispatchOrder(ctx context.Context, event string, order *models.SimOrder) {
+	userId := uint(order.UserId)
+	endpoints, err := w.Repo.FindEndpointsByUser(ctx, userId)
+	if err != nil {
+		log.Printf("failed to load webhook endpoints of user %d for %s: %v", userId, event, err)
+		return
+	}
+
+	data := models.NewWebhookOrderData(order)
+	// DATETIME dibulatkan ke detik, tanpa truncate delivery bisa belum "due" saat langsung diklaim
+	now := time.Now().Truncate(time.Second)
+	for i := range endpoints {
+		endpoint := &endpoints[i]
+		if !endpoint.Subscribed(event) {
+			continue
+		}
+		delivery, err := w.createDelivery(ctx, endpoint, event, data, &now)
+		if err != nil {
+			log.Printf("failed to queue webhook %s for order %d to endpoint %d: %v", event, order.Id, endpoint.Id, err)
+			continue
+		}
+		go w.deliverQueued(context.Background(), endpoint, delivery)
+	}
+}
+
+// ProcessDue implements WebhookService.
+// Mengirim ulang delivery yang jadwal retry-nya sudah lewat, dipanggil oleh scheduler.
+func (w *WebhookServiceImplementation) ProcessDue(ctx context.Context) (int, error) {
+	deliveries, err := w.Repo.FindDueDeliveries(ctx, webhookDueBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load due webhook deliveries: %w", err)
+	}
+
+	endpoints := map[int64]*models.WebhookEndpoint{}
+	processed := 0
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		endpoint, ok := endpoints[delivery.EndpointId]
+		if !ok {
+			endpoint, err = w.Repo.GetEndpoint(ctx, delivery.EndpointId)
+			if err != nil {
+				log.Printf("failed to load webhook endpoint %d: %v", delivery.EndpointId, err)
+				continue
+			}
+			endpoints[delivery.EndpointId] = endpoint
+		}
+		// endpoint yang sudah dihapus ikut menghapus delivery-nya
+		if endpoint == nil {
+			continue
+		}
+		if w.deliverQueued(ctx, endpoint, delivery) {
+			processed++
+		}
+	}
+	return processed, nil
+}
+
+func (w *WebhookServiceImplementation) getOwnedEndpoint(ctx context.Context, userId uint, endpointId int64) (*models.WebhookEndpoint, error) {
+	endpoint, err := w.Repo.GetEndpoint(ctx, endpointId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook endpoint: %w", err)
+	}
+	if endpoint == nil || endpoint.UserId != userId {
+		return nil, ErrWebhookNotFound
+	}
+	return endpoint, nil
+}
+
+// createDelivery menyimpan payload yang akan dikirim. nextAttemptAt nil berarti delivery tidak diambil scheduler.
+func (w *WebhookServiceImplementation) createDelivery(ctx context.Context, endpoint *models.WebhookEndpoint, event string, data any, nextAttemptAt *time.Time) (*models.WebhookDelivery, error) {
+	random, err := helper.RandomToken(webhookEventIdRandomBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate webhook event id: %w", err)
+	}
+	payload := models.WebhookPayload{
+		Id:        webhookEventIdPrefix + random,
+		Type:      event,
+		CreatedAt: time.Now().UTC(),
+		Data:      data,
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	delivery := &models.WebhookDelivery{
+		EndpointId:    endpoint.Id,
+		EventId:       payload.Id,
+		EventType:     event,
+		Payload:       string(body),
+		Status:        models.WebhookDeliveryPending,
+		NextAttemptAt: nextAttemptAt,
+	}
+	if err := w.Repo.CreateDelivery(ctx, delivery); err != nil {
+		return nil, fmt.Errorf("failed to save webhook delivery: %w", err)
+	}
+	return delivery, nil
+}
+
+// deliverQueued mengklaim delivery lalu mengirimnya. false jika delivery sudah diambil proses lain.
+func (w *WebhookServiceImplementation) deliverQueued(ctx context.Context, endpoint *models.WebhookEndpoint, delivery *models.WebhookDelivery) bool {
+	// lease lebih lama dari timeout HTTP agar scheduler tidak mengirim ulang selama request berjalan
+	claimed, err := w.Repo.ClaimDelivery(ctx, delivery.Id, time.Now().Add(2*w.client.Timeout))
+	if err != nil {
+		log.Printf("failed to claim webhook delivery %d: %v", delivery.Id, err)
+		return false
+	}
+	if !claimed {
+		return false
+	}
+	w.attempt(ctx, endpoint, delivery, true)
+	return true
+}
+
+// attempt mengirim payload ke endpoint dan menyimpan hasilnya.
+// Jika gagal dan retry true, delivery dijadwalkan ulang dengan backoff eksponensial sampai batas percobaan.
+func (w *WebhookServiceImplementation) attempt(ctx context.Context, endpoint *models.WebhookEndpoint, delivery *models.WebhookDelivery, retry bool) bool {
+	statusCode, sendErr := w.send(ctx, endpoint, delivery)
+
+	now := time.Now()
+	delivery.Attempts++
+	delivery.LastStatusCode = nil
+	delivery.LastError = nil
+	delivery.NextAttemptAt = nil
+	if statusCode != 0 {
+		delivery.LastStatusCode = &statusCode
+	}
+
+	success := sendErr == nil && statusCode >= 200 && statusCode < 300
+	switch {
+	case success:
+		delivery.Status = models.WebhookDeliverySuccess
+		delivery.DeliveredAt = &now
+	case retry && delivery.Attempts < w.maxAttempts:
+		delivery.Status = models.WebhookDeliveryPending
+		next := now.Add(w.backoffFor(delivery.Attempts))
+		delivery.NextAttemptAt = &next
+	default:
+		delivery.Status = models.WebhookDeliveryFailed
+	}
+	if !success {
+		message := fmt.Sprintf("endpoint responded with status %d", statusCode)
+		if sendErr != nil {
+			message = truncate(sendErr.Error(), maxWebhookErrorLength)
+		}
+		delivery.LastError = &message
+	}
+
+	if err := w.Repo.SaveAttempt(ctx, delivery); err != nil {
+		log.Printf("failed to save webhook delivery %d attempt: %v", delivery.Id, err)
+	}
+	return success
+}
+
+// send melakukan POST payload dengan header signature.
+// Body response tidak disimpan maupun dikembalikan ke user, hanya status code.
+func (w *WebhookServiceImplementation) send(ctx context.Context, endpoint *models.WebhookEndpoint, delivery *models.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "sim-service-webhook/1.0")
+	req.Header.Set(models.WebhookHeaderEvent, delivery.EventType)
+	req.Header.Set(models.WebhookHeaderId, delivery.EventId)
+	req.Header.Set(models.WebhookHeaderTimestamp, timestamp)
+	req.Header.Set(models.WebhookHeaderSignature, "sha256="+helper.GenerateWebhookSignature(endpoint.Secret, timestamp, body))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	// body dibaca sebagian dan dibuang agar koneksi bisa dipakai ulang
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxWebhookResponseLength))
+	return resp.StatusCode, nil
+}
+
+// backoffFor: backoff * 2^(attempts-1), dibatasi maxWebhookBackoff
+func (w *WebhookServiceImplementation) backoffFor(attempts int) time.Duration {
+	delay := w.backoff
+	for i := 1; i < attempts && delay < maxWebhookBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, maxWebhookBackoff)
+}
+
+func truncate(value string, length int) string {
+	if len(value) <= length {
+		return value
+	}
+	return value[:length]
+}
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWebhookBackoffFor(t *testing.T) {
+	tests := []struct {
+		name     string
+		backoff  time.Duration
+		attempts int
+		want     time.Duration
+	}{
+		{name: "first attempt", backoff: time.Minute, attempts: 1, want: time.Minute},
+		{name: "zero attempts", backoff: time.Minute, attempts: 0, want: time.Minute},
+		{name: "second attempt doubles", backoff: time.Minute, attempts: 2, want: 2 * time.Minute},
+		{name: "fifth attempt", backoff: time.Minute, attempts: 5, want: 16 * time.Minute},
+		{name: "capped", backoff: time.Minute, attempts: 20, want: maxWebhookBackoff},
+		{name: "large attempts do not overflow", backoff: time.Minute, attempts: 1000, want: maxWebhookBackoff},
+		{name: "base above cap", backoff: 12 * time.Hour, attempts: 1, want: maxWebhookBackoff},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &WebhookServiceImplementation{backoff: tt.backoff}
+			if got := w.backoffFor(tt.attempts); got != tt.want {
+				t.Fatalf("backoffFor(%d) = %v, want %v", tt.attempts, got, tt.want)
+			}
+		})
+	}
+}
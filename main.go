@@ -1,18 +1,27 @@
 package main
 
 import (
+	"context"
 	"log"
 
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/gofiber/fiber/v2"
 	"github.com/imnzr/sim-service-project/config"
 	"github.com/imnzr/sim-service-project/database"
+	"github.com/imnzr/sim-service-project/internal/audit"
+	"github.com/imnzr/sim-service-project/internal/cache"
 	"github.com/imnzr/sim-service-project/internal/controller"
+	exchangerate "github.com/imnzr/sim-service-project/internal/exchange_rate"
+	"github.com/imnzr/sim-service-project/internal/lock"
+	"github.com/imnzr/sim-service-project/internal/mailer"
 	"github.com/imnzr/sim-service-project/internal/middleware"
 	xenditpayment "github.com/imnzr/sim-service-project/internal/payment_gateway/xendit_payment"
 	"github.com/imnzr/sim-service-project/internal/repository"
+	"github.com/imnzr/sim-service-project/internal/scheduler"
 	"github.com/imnzr/sim-service-project/internal/service"
+	"github.com/imnzr/sim-service-project/models"
 	"github.com/imnzr/sim-service-project/routes"
+	"github.com/redis/go-redis/v9"
 )
 
 func main() {
@@ -22,34 +31,121 @@ func main() {
 	db := database.InitDb(cfg.DatabaseURL)
 	defer db.Close()
 
+	// Inisialisasi redis
+	redisClient := redis.NewClient(&redis.Options{
+		Addr:     cfg.RedisURL,
+		Password: cfg.RedisPassword,
+		DB:       0,
+	})
+	defer redisClient.Close()
+	appCache := cache.NewCache(redisClient)
+
 	// Inisialisasi Repository
 	userRepository := repository.NewUserRepository(db)
+	refreshTokenRepository := repository.NewRefreshTokenRepository(db)
+	userSessionRepository := repository.NewUserSessionRepository(db)
 	userProduct := repository.NewProductRepository(db)
 	orderRepository := repository.NewOrderRepository(db)
+	exchangeRateRepository := repository.NewExchangeRateRepository(db)
+	pricingRuleRepository := repository.NewPricingRuleRepository(db)
+	syncRunRepository := repository.NewSyncRunRepository(db)
+	catalogRepository := repository.NewCatalogRepository(db)
+	productStockRepository := repository.NewProductStockRepository(db)
+	priceHistoryRepository := repository.NewPriceHistoryRepository(db)
+	productOverrideRepository := repository.NewProductOverrideRepository(db)
+	auditLogRepository := repository.NewAuditLogRepository(db)
+	balanceRepository := repository.NewBalanceRepository(db)
+	apiKeyRepository := repository.NewAPIKeyRepository(db)
+	priceTierRepository := repository.NewPriceTierRepository(db)
+	webhookRepository := repository.NewWebhookRepository(db)
+	bulkOrderRepository := repository.NewBulkOrderRepository(db)
+
+	// Inisialisasi sumber kurs
+	rateSource, err := exchangerate.NewRateSource(*cfg)
+	if err != nil {
+		log.Printf("exchange rate source not available, only cached/manual rates will be used: %v", err)
+	}
+
+	// Inisialisasi mailer
+	appMailer, err := mailer.NewMailer(*cfg)
+	if err != nil {
+		log.Fatalf("failed to initialize mailer: %v", err)
+	}
 
 	// Inisialisasi Service
-	userService := service.NewUserService(userRepository, cfg)
-	orderService := service.NewOrderService(orderRepository, db)
-	productService := service.NewProductService(userProduct, *cfg)
+	auditLogger := audit.NewLogger(auditLogRepository)
+	tokenDenyList := cache.NewTokenDenyList(appCache)
+	rateLimiter := cache.NewRateLimiter(appCache)
+	userService := service.NewUserService(userRepository, refreshTokenRepository, userSessionRepository, tokenDenyList, rateLimiter, appMailer, auditLogger, cfg)
+	apiKeyService := service.NewAPIKeyService(apiKeyRepository, userRepository, rateLimiter, auditLogger, *cfg)
+	webhookService := service.NewWebhookService(webhookRepository, *cfg)
+	orderService := service.NewOrderService(orderRepository, db, webhookService)
+	exchangeRateService := service.NewExchangeRateService(exchangeRateRepository, rateSource, *cfg)
+	pricingService := service.NewPricingService(pricingRuleRepository, userProduct, productOverrideRepository, exchangeRateService)
+	productOverrideService := service.NewProductOverrideService(productOverrideRepository)
+	productService := service.NewProductService(userProduct, productStockRepository, priceHistoryRepository, exchangeRateService, pricingService, productOverrideService, appCache, rateLimiter, *cfg)
+	redisLock := lock.NewRedisLock(redisClient)
+	catalogSyncService := service.NewCatalogSyncService(productService, syncRunRepository, productStockRepository, redisLock, *cfg)
+	adminOrderService := service.NewAdminOrderService(orderRepository, balanceRepository, orderService, auditLogger, redisLock, webhookService)
+	adminUserService := service.NewAdminUserService(userRepository, balanceRepository, orderRepository, userService, auditLogger, tokenDenyList)
+	priceTierService := service.NewPriceTierService(priceTierRepository, userRepository, orderRepository, auditLogger)
+	bulkOrderService := service.NewBulkOrderService(bulkOrderRepository, orderRepository, balanceRepository, productService, priceTierService, orderService, webhookService, auditLogger, *cfg)
+	catalogService := service.NewCatalogService(catalogRepository, userProduct, productStockRepository, priceTierService)
 	xenditService := xenditpayment.NewXenditPayment(userRepository, orderRepository)
 
 	// Inisialisasi Controller
 	userController := controller.NewUserController(userService)
-	productController := controller.NewProductController(productService)
-	xenditController := controller.NewOrderController(orderRepository, orderService, xenditService)
+	productController := controller.NewProductController(productService, catalogSyncService)
+	xenditController := controller.NewOrderController(orderRepository, orderService, productService, priceTierService, xenditService, auditLogger, redisLock)
+	exchangeRateController := controller.NewExchangeRateController(exchangeRateService)
+	pricingController := controller.NewPricingController(pricingService)
+	catalogController := controller.NewCatalogController(catalogService)
+	productOverrideController := controller.NewProductOverrideController(productOverrideService)
+	adminOrderController := controller.NewAdminOrderController(adminOrderService)
+	adminUserController := controller.NewAdminUserController(adminUserService)
+	auditLogController := controller.NewAuditLogController(auditLogger)
+	apiKeyController := controller.NewAPIKeyController(apiKeyService)
+	priceTierController := controller.NewPriceTierController(priceTierService)
+	webhookController := controller.NewWebhookController(webhookService)
+	bulkOrderController := controller.NewBulkOrderController(bulkOrderService)
 
 	app := fiber.New()
 
 	// Middleware
-	authMiddleware := middleware.AuthMiddleware(userService, *cfg)
+	app.Use(middleware.AuditRequestInfo())
+	// endpoint yang juga bisa dipanggil dengan X-API-Key menyebutkan scope yang dibutuhkan
+	authMiddleware := middleware.AuthMiddleware(userService, apiKeyService, *cfg)
+	orderAuthMiddleware := middleware.AuthMiddleware(userService, apiKeyService, *cfg, models.APIKeyScopeOrdersCreate)
+	profileAuthMiddleware := middleware.AuthMiddleware(userService, apiKeyService, *cfg, models.APIKeyScopeProfileRead)
+	catalogAuthMiddleware := middleware.OptionalAuth(middleware.AuthMiddleware(userService, apiKeyService, *cfg, models.APIKeyScopeCatalogRead))
+	verifiedMiddleware := middleware.RequireVerifiedEmail(userService)
 
 	// Routes
-	routes.SetupUserRoutes(app, userController, authMiddleware)
-	routes.SetupProductRoutes(app, productController, authMiddleware)
-	routes.SetupSimOrderRoutes(app, xenditController, authMiddleware)
+	adminGroup := routes.SetupAdminGroup(app, authMiddleware)
+	routes.SetupUserRoutes(app, userController, authMiddleware, profileAuthMiddleware)
+	routes.SetupProductRoutes(app, adminGroup, productController)
+	routes.SetupCatalogRoutes(app, catalogController, catalogAuthMiddleware)
+	routes.SetupSimOrderRoutes(app, xenditController, orderAuthMiddleware, verifiedMiddleware)
+	routes.SetupBulkOrderRoutes(app, bulkOrderController, orderAuthMiddleware, verifiedMiddleware)
+	routes.SetupAPIKeyRoutes(app, apiKeyController, authMiddleware, verifiedMiddleware)
+	routes.SetupWebhookRoutes(app, webhookController, authMiddleware, verifiedMiddleware)
+	routes.SetupExchangeRateRoutes(app, adminGroup, exchangeRateController)
+	routes.SetupPricingRoutes(adminGroup, pricingController)
+	routes.SetupProductOverrideRoutes(adminGroup, productOverrideController)
+	routes.SetupAdminOrderRoutes(adminGroup, adminOrderController)
+	routes.SetupAdminUserRoutes(adminGroup, adminUserController)
+	routes.SetupAuditLogRoutes(adminGroup, auditLogController)
+	routes.SetupPriceTierRoutes(app, adminGroup, priceTierController, authMiddleware)
+
+	// Scheduler sinkronisasi katalog
+	schedulerCtx, stopScheduler := context.WithCancel(context.Background())
+	defer stopScheduler()
+	go scheduler.NewCatalogSyncScheduler(catalogSyncService, cfg.CatalogSyncInterval).Start(schedulerCtx)
+	go scheduler.NewWebhookDeliveryScheduler(webhookService, cfg.WebhookRetryInterval).Start(schedulerCtx)
+	go scheduler.NewOrderStatusScheduler(orderService, redisLock, cfg.OrderRecheckInterval, cfg.OrderRecheckLockTTL).Start(schedulerCtx)
 
 	log.Printf("Server starting on port %s", cfg.AppPort)
-	err := app.Listen(":" + cfg.AppPort)
+	err = app.Listen(":" + cfg.AppPort)
 	if err != nil {
 		log.Fatal("Server failed to start: %w", err)
 	}
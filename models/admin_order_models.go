@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// OrderStatusHistory adalah satu perubahan status order
+type OrderStatusHistory struct {
+	Id        int64     `json:"id"`
+	OrderId   int       `json:"order_id"`
+	Status    string    `json:"status"`
+	Source    string    `json:"source"`
+	Note      *string   `json:"note"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// OrderProviderResponse adalah response mentah dari provider untuk satu aksi pada order
+type OrderProviderResponse struct {
+	Id        int64     `json:"id"`
+	OrderId   int       `json:"order_id"`
+	Provider  string    `json:"provider"`
+	Action    string    `json:"action"`
+	Success   bool      `json:"success"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AdminOrderQuery adalah filter pencarian order untuk admin/support.
+// Semua filter bersifat exact match dan boleh dikombinasikan.
+type AdminOrderQuery struct {
+	UserId          int
+	Email           string
+	InvoiceId       string
+	PhoneNumber     string
+	ProviderOrderId int
+	Status          string
+	Page            int
+	Limit           int
+}
+
+// AdminOrderDetail berisi order beserta riwayat status dan response provider
+type AdminOrderDetail struct {
+	Order             SimOrder                `json:"order"`
+	StatusHistory     []OrderStatusHistory    `json:"status_history"`
+	ProviderResponses []OrderProviderResponse `json:"provider_responses"`
+	AuditLogs         []AuditLog              `json:"audit_logs"`
+}
+
+// RefundOrderRequest adalah payload refund manual, reason wajib diisi
+type RefundOrderRequest struct {
+	Reason string `json:"reason"`
+}
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// AdminUserQuery adalah filter pencarian user untuk admin/support.
+// Search dicocokkan sebagian pada email dan username.
+type AdminUserQuery struct {
+	Search    string
+	Role      string
+	Suspended *bool
+	Page      int
+	Limit     int
+}
+
+// UserPayment adalah invoice order milik user beserta waktu pembayarannya
+type UserPayment struct {
+	OrderId    int        `json:"order_id"`
+	InvoiceId  string     `json:"invoice_id"`
+	Amount     float64    `json:"amount"`
+	Status     string     `json:"status"`
+	PaidAt     *time.Time `json:"paid_at"`
+	RefundedAt *time.Time `json:"refunded_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// AdminUserDetail berisi data user beserta riwayat saldo dan audit log
+type AdminUserDetail struct {
+	User                User                 `json:"user"`
+	BalanceTransactions []BalanceTransaction `json:"balance_transactions"`
+	AuditLogs           []AuditLog           `json:"audit_logs"`
+}
+
+// SuspendUserRequest adalah payload suspend/unsuspend, reason wajib diisi
+type SuspendUserRequest struct {
+	Reason string `json:"reason"`
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// Scope API key, endpoint yang bisa diakses dengan X-API-Key harus mensyaratkan salah satu scope ini
+const (
+	APIKeyScopeOrdersCreate = "orders:create"
+	APIKeyScopeProfileRead  = "profile:read"
+	APIKeyScopeCatalogRead  = "catalog:read"
+)
+
+// APIKeyScopes adalah semua scope yang bisa diberikan ke API key
+var APIKeyScopes = []string{APIKeyScopeOrdersCreate, APIKeyScopeProfileRead, APIKeyScopeCatalogRead}
+
+// ValidAPIKeyScope mengecek apakah scope dikenal
+func ValidAPIKeyScope(scope string) bool {
+	return slices.Contains(APIKeyScopes, scope)
+}
+
+// APIKey adalah key milik user untuk mengakses API tanpa JWT
+type APIKey struct {
+	Id         int64      `json:"id"`
+	UserId     uint       `json:"user_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	KeyHash    string     `json:"-"`
+	Scopes     []string   `json:"scopes"`
+	RateLimit  int        `json:"rate_limit"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	LastUsedIP *string    `json:"last_used_ip"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// HasScope mengecek apakah key punya scope tertentu
+func (k *APIKey) HasScope(scope string) bool {
+	return slices.Contains(k.Scopes, scope)
+}
+
+// CreateAPIKeyRequest adalah payload pembuatan API key.
+// RateLimit adalah jumlah request per menit, 0 memakai default.
+type CreateAPIKeyRequest struct {
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+	RateLimit     int      `json:"rate_limit"`
+	ExpiresInDays int      `json:"expires_in_days"`
+}
+
+// CreateAPIKeyResponse berisi key asli yang hanya ditampilkan sekali saat dibuat
+type CreateAPIKeyResponse struct {
+	APIKey
+	Key string `json:"key"`
+}
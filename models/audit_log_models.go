@@ -0,0 +1,91 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+// AuditLog mencatat siapa melakukan apa terhadap data apa.
+// Tabelnya append-only dan setiap baris terhubung ke baris sebelumnya lewat hash chain.
+type AuditLog struct {
+	Id          int64     `json:"id"`
+	ActorUserId *uint     `json:"actor_user_id"`
+	Action      string    `json:"action"`
+	TargetType  string    `json:"target_type"`
+	TargetId    string    `json:"target_id"`
+	Reason      *string   `json:"reason"`
+	Before      *string   `json:"before"`
+	After       *string   `json:"after"`
+	Metadata    *string   `json:"metadata"`
+	IPAddress   *string   `json:"ip_address"`
+	UserAgent   *string   `json:"user_agent"`
+	PrevHash    *string   `json:"prev_hash"`
+	Hash        *string   `json:"hash"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// auditLogChainPayload adalah isi audit log yang di-hash, urutan field tidak boleh diubah
+type auditLogChainPayload struct {
+	PrevHash    string  `json:"prev_hash"`
+	ActorUserId *uint   `json:"actor_user_id"`
+	Action      string  `json:"action"`
+	TargetType  string  `json:"target_type"`
+	TargetId    string  `json:"target_id"`
+	Reason      *string `json:"reason"`
+	Before      *string `json:"before"`
+	After       *string `json:"after"`
+	Metadata    *string `json:"metadata"`
+	IPAddress   *string `json:"ip_address"`
+	UserAgent   *string `json:"user_agent"`
+	CreatedAt   string  `json:"created_at"`
+}
+
+// ChainHash menghitung hash sha256 audit log berdasarkan isi dan prev_hash-nya.
+// CreatedAt harus sudah dibulatkan ke detik karena kolom DATETIME tidak menyimpan nanodetik.
+func (a *AuditLog) ChainHash() string {
+	payload := auditLogChainPayload{
+		ActorUserId: a.ActorUserId,
+		Action:      a.Action,
+		TargetType:  a.TargetType,
+		TargetId:    a.TargetId,
+		Reason:      a.Reason,
+		Before:      a.Before,
+		After:       a.After,
+		Metadata:    a.Metadata,
+		IPAddress:   a.IPAddress,
+		UserAgent:   a.UserAgent,
+		CreatedAt:   a.CreatedAt.UTC().Format(time.RFC3339),
+	}
+	if a.PrevHash != nil {
+		payload.PrevHash = *a.PrevHash
+	}
+
+	// struct tanpa map selalu menghasilkan JSON yang sama
+	data, _ := json.Marshal(payload)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// AuditLogQuery adalah filter pencarian audit log untuk admin
+type AuditLogQuery struct {
+	ActorUserId uint
+	Action      string
+	TargetType  string
+	TargetId    string
+	From        *time.Time
+	To          *time.Time
+	Page        int
+	Limit       int
+}
+
+// AuditChainReport adalah hasil verifikasi hash chain audit log
+type AuditChainReport struct {
+	Valid      bool   `json:"valid"`
+	Checked    int    `json:"checked"`
+	Unchained  int    `json:"unchained"`
+	LastId     int64  `json:"last_id"`
+	BrokenAtId *int64 `json:"broken_at_id,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+}
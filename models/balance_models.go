@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// Tipe transaksi saldo
+const (
+	BalanceTxAdjustment  = "adjustment"
+	BalanceTxOrderCharge = "order_charge"
+	BalanceTxOrderRefund = "order_refund"
+)
+
+// Reason code untuk penyesuaian saldo manual oleh admin
+const (
+	BalanceReasonCompensation = "compensation"
+	BalanceReasonRefund       = "refund"
+	BalanceReasonCorrection   = "correction"
+	BalanceReasonPromotion    = "promotion"
+	BalanceReasonChargeback   = "chargeback"
+)
+
+// ValidBalanceReason mengecek apakah reason code dikenal
+func ValidBalanceReason(code string) bool {
+	switch code {
+	case BalanceReasonCompensation, BalanceReasonRefund, BalanceReasonCorrection, BalanceReasonPromotion, BalanceReasonChargeback:
+		return true
+	default:
+		return false
+	}
+}
+
+// BalanceTransaction adalah satu baris ledger saldo user
+type BalanceTransaction struct {
+	Id           int64     `json:"id"`
+	UserId       uint      `json:"user_id"`
+	Type         string    `json:"type"`
+	Amount       float64   `json:"amount"`
+	BalanceAfter float64   `json:"balance_after"`
+	ReasonCode   *string   `json:"reason_code"`
+	Note         *string   `json:"note"`
+	Reference    *string   `json:"reference"`
+	ActorUserId  *uint     `json:"actor_user_id"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// BalanceAdjustmentRequest adalah payload penyesuaian saldo manual, amount negatif untuk mengurangi saldo.
+// IdempotencyKey dibuat oleh client per penyesuaian, request yang diulang dengan key sama tidak dicatat dua kali.
+type BalanceAdjustmentRequest struct {
+	Amount         float64 `json:"amount"`
+	ReasonCode     string  `json:"reason_code"`
+	Note           string  `json:"note"`
+	IdempotencyKey string  `json:"idempotency_key"`
+}
@@ -0,0 +1,64 @@
+package models
+
+import "time"
+
+// Status bulk order
+const (
+	BulkOrderProcessing = "processing"
+	BulkOrderCompleted  = "completed"
+	BulkOrderPartial    = "partial"
+	BulkOrderFailed     = "failed"
+)
+
+// Status per nomor di hasil bulk order
+const (
+	BulkItemFulfilled = "fulfilled"
+	BulkItemRefunded  = "refunded"
+	// BulkItemFailed: pembelian gagal dan refund otomatis juga gagal, perlu refund manual
+	BulkItemFailed = "failed"
+)
+
+// BulkOrder mengelompokkan order yang dibayar dengan satu kali potong saldo
+type BulkOrder struct {
+	Id             int        `json:"id"`
+	UserId         uint       `json:"user_id"`
+	Status         string     `json:"status"`
+	Quantity       int        `json:"quantity"`
+	TotalAmount    float64    `json:"total_amount"`
+	RefundedAmount float64    `json:"refunded_amount"`
+	CreatedAt      time.Time  `json:"created_at"`
+	CompletedAt    *time.Time `json:"completed_at"`
+}
+
+type BulkOrderItemRequest struct {
+	Service  string `json:"service"`
+	Country  string `json:"country"`
+	Operator string `json:"operator"`
+	Quantity int    `json:"quantity"`
+}
+
+type CreateBulkOrderRequest struct {
+	Items []BulkOrderItemRequest `json:"items"`
+}
+
+// BulkOrderItemResult adalah hasil satu nomor. ItemIndex menunjuk ke posisi item di request.
+type BulkOrderItemResult struct {
+	ItemIndex   int     `json:"item_index"`
+	OrderId     int     `json:"order_id"`
+	InvoiceId   string  `json:"invoice_id"`
+	Service     string  `json:"service"`
+	Country     string  `json:"country"`
+	Operator    string  `json:"operator"`
+	Price       float64 `json:"price"`
+	Status      string  `json:"status"`
+	PhoneNumber *string `json:"phone_number,omitempty"`
+	Error       string  `json:"error,omitempty"`
+}
+
+type BulkOrderResult struct {
+	BulkOrder    BulkOrder             `json:"bulk_order"`
+	Fulfilled    int                   `json:"fulfilled"`
+	Refunded     int                   `json:"refunded"`
+	BalanceAfter float64               `json:"balance_after"`
+	Items        []BulkOrderItemResult `json:"items"`
+}
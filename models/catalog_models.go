@@ -0,0 +1,58 @@
+package models
+
+// CatalogQuery adalah parameter pencarian, sorting dan pagination katalog
+type CatalogQuery struct {
+	Search string
+	Sort   string
+	Page   int
+	Limit  int
+	// UserId diisi jika caller terautentikasi, dipakai untuk harga efektif tier
+	UserId uint
+}
+
+// CatalogPage membungkus hasil katalog dengan info pagination
+type CatalogPage[T any] struct {
+	Items []T `json:"items"`
+	Page  int `json:"page"`
+	Limit int `json:"limit"`
+	Total int `json:"total"`
+}
+
+type CatalogCountry struct {
+	Country      string  `json:"country"`
+	ServiceCount int     `json:"service_count"`
+	MinPrice     float64 `json:"min_price"`
+	// harga setelah diskon tier caller, kosong untuk caller tanpa tier
+	EffectiveMinPrice *float64 `json:"effective_min_price,omitempty"`
+}
+
+type CatalogService struct {
+	Service       string  `json:"service"`
+	DisplayName   string  `json:"display_name"`
+	IconURL       *string `json:"icon_url"`
+	OperatorCount int     `json:"operator_count"`
+	MinPrice      float64 `json:"min_price"`
+	// harga setelah diskon tier caller, kosong untuk caller tanpa tier
+	EffectiveMinPrice *float64 `json:"effective_min_price,omitempty"`
+}
+
+type CatalogOperator struct {
+	Operator  string  `json:"operator"`
+	PriceSell float64 `json:"price_sell"`
+	Available bool    `json:"available"`
+	Stock     int     `json:"stock"`
+	InStock   bool    `json:"in_stock"`
+	// harga setelah diskon tier caller, kosong untuk caller tanpa tier
+	EffectivePrice *float64 `json:"effective_price,omitempty"`
+}
+
+type CatalogProduct struct {
+	Service     string  `json:"service"`
+	DisplayName string  `json:"display_name"`
+	IconURL     *string `json:"icon_url"`
+	Country     string  `json:"country"`
+	Operator    string  `json:"operator"`
+	PriceSell   float64 `json:"price_sell"`
+	// harga setelah diskon tier caller, kosong untuk caller tanpa tier
+	EffectivePrice *float64 `json:"effective_price,omitempty"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// ExchangeRate merepresentasikan kurs yang tersimpan di database
+type ExchangeRate struct {
+	Id             int       `json:"id"`
+	BaseCurrency   string    `json:"base_currency"`
+	QuoteCurrency  string    `json:"quote_currency"`
+	Rate           float64   `json:"rate"`
+	Source         string    `json:"source"`
+	ActiveOverride bool      `json:"active_override"`
+	FetchedAt      time.Time `json:"fetched_at"`
+}
+
+// Manual exchange rate payload untuk override kurs oleh admin
+type ManualExchangeRateRequest struct {
+	BaseCurrency  string  `json:"base_currency"`
+	QuoteCurrency string  `json:"quote_currency"`
+	Rate          float64 `json:"rate"`
+}
+
+// PriceQuote adalah harga jual yang dihitung dengan kurs saat ini
+type PriceQuote struct {
+	Service        string  `json:"service"`
+	Country        string  `json:"country"`
+	Operator       string  `json:"operator"`
+	PriceDefault   float64 `json:"price_default"`
+	PriceSell      float64 `json:"price_sell"`
+	ExchangeRate   float64 `json:"exchange_rate"`
+	ExchangeRateId int     `json:"exchange_rate_id"`
+	PricingRuleId  *int    `json:"pricing_rule_id"`
+	PricePinned    bool    `json:"price_pinned"`
+	// diisi jika user punya tier harga, PriceSell sudah termasuk diskon tier
+	RetailPrice     *float64 `json:"retail_price,omitempty"`
+	PriceTierId     *int     `json:"price_tier_id,omitempty"`
+	DiscountPercent float64  `json:"discount_percent,omitempty"`
+}
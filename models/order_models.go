@@ -2,22 +2,45 @@ package models
 
 import "time"
 
+// Status order milik aplikasi. Setelah nomor dibeli, status mengikuti status dari 5sim
+// (PENDING, RECEIVED, CANCELED, TIMEOUT, FINISHED, BANNED).
+const (
+	OrderStatusPending  = "PENDING"
+	OrderStatusPaid     = "PAID"
+	OrderStatusFailed   = "FAILED"
+	OrderStatusRefunded = "REFUNDED"
+)
+
+// Sumber perubahan status yang dicatat di sim_order_status_history
+const (
+	OrderSourceCheckout = "checkout"
+	OrderSourcePayment  = "payment"
+	OrderSourceProvider = "5sim"
+	OrderSourceAdmin    = "admin"
+	OrderSourceSystem   = "system"
+)
+
 type SimOrder struct {
-	Id                int       `json:"id"`
-	UserId            int       `json:"user_id"`
-	Email             string    `json:"email"`
-	Service           string    `json:"service"`
-	Country           string    `json:"country"`
-	Operator          string    `json:"operator"`
-	PriceSell         float64   `json:"price_sell"`
-	InvoiceId         string    `json:"invoice_id"`
-	SimOrderServiceId *int      `json:"sim_order_service_id"`
-	PhoneNumber       *string   `json:"phone_number"`
-	OTP               *string   `json:"otp"`
-	Status            string    `json:"status"`
-	ErrorMessage      *string   `json:"error_message"`
-	CreatedAt         time.Time `json:"created_at"`
-	UpdatedAt         time.Time `json:"updated_at"`
+	Id                int        `json:"id"`
+	UserId            int        `json:"user_id"`
+	Email             string     `json:"email"`
+	Service           string     `json:"service"`
+	Country           string     `json:"country"`
+	Operator          string     `json:"operator"`
+	PriceSell         float64    `json:"price_sell"`
+	ExchangeRate      *float64   `json:"exchange_rate"`
+	ExchangeRateId    *int       `json:"exchange_rate_id"`
+	InvoiceId         string     `json:"invoice_id"`
+	SimOrderServiceId *int       `json:"sim_order_service_id"`
+	PhoneNumber       *string    `json:"phone_number"`
+	OTP               *string    `json:"otp"`
+	Status            string     `json:"status"`
+	ErrorMessage      *string    `json:"error_message"`
+	RefundedAt        *time.Time `json:"refunded_at"`
+	RefundReason      *string    `json:"refund_reason"`
+	BulkOrderId       *int       `json:"bulk_order_id"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
 }
 
 type CreateSimOrderRequest struct {
@@ -40,16 +63,26 @@ type OrderNumberFromService struct {
 }
 
 type ResponsOrderFromService struct {
-	Id        int     `json:"id"`
-	Phone     string  `json:"phone"`
-	Operator  string  `json:"operator"`
-	Product   string  `json:"product"`
-	Price     float64 `json:"price"`
-	Status    string  `json:"status"`
-	Expires   string  `json:"expires"`    // atau time.Time jika pakai parsing waktu
-	SMS       any     `json:"sms"`        // bisa pakai []SMS jika detailnya tahu
-	CreatedAt string  `json:"created_at"` // atau time.Time
-	Country   string  `json:"country"`
+	Id        int           `json:"id"`
+	Phone     string        `json:"phone"`
+	Operator  string        `json:"operator"`
+	Product   string        `json:"product"`
+	Price     float64       `json:"price"`
+	Status    string        `json:"status"`
+	Expires   string        `json:"expires"` // atau time.Time jika pakai parsing waktu
+	SMS       []ProviderSMS `json:"sms"`
+	CreatedAt string        `json:"created_at"` // atau time.Time
+	Country   string        `json:"country"`
+
+	// body mentah dari 5sim, disimpan di sim_order_provider_responses
+	RawBody string `json:"-"`
+}
+
+// ProviderSMS adalah SMS yang diterima nomor dari 5sim
+type ProviderSMS struct {
+	Sender string `json:"sender"`
+	Text   string `json:"text"`
+	Code   string `json:"code"`
 }
 
 // type ResponsOrderFromService struct {
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// ProductPriceHistory mencatat perubahan harga produk saat sync katalog
+type ProductPriceHistory struct {
+	Id              int64     `json:"id"`
+	Service         string    `json:"service"`
+	Country         string    `json:"country"`
+	Operator        string    `json:"operator"`
+	OldPriceDefault *float64  `json:"old_price_default"`
+	NewPriceDefault float64   `json:"new_price_default"`
+	OldPriceSell    *float64  `json:"old_price_sell"`
+	NewPriceSell    float64   `json:"new_price_sell"`
+	ExchangeRate    float64   `json:"exchange_rate"`
+	ExchangeRateId  *int      `json:"exchange_rate_id"`
+	PricingRuleId   *int      `json:"pricing_rule_id"`
+	PricingRuleName *string   `json:"pricing_rule_name"`
+	ChangedAt       time.Time `json:"changed_at"`
+}
+
+// PriceHistoryQuery adalah filter riwayat harga untuk admin
+type PriceHistoryQuery struct {
+	Service  string
+	Country  string
+	Operator string
+	From     *time.Time
+	To       *time.Time
+	Limit    int
+}
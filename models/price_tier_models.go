@@ -0,0 +1,47 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// PriceTier adalah tier harga reseller. Diskon diterapkan di atas harga jual katalog.
+// MonthlyVolumeThreshold adalah total belanja bulan berjalan (IDR) untuk naik ke tier ini secara otomatis,
+// nil berarti tier hanya bisa di-assign manual.
+type PriceTier struct {
+	Id                     int       `json:"id"`
+	Name                   string    `json:"name"`
+	DiscountPercent        float64   `json:"discount_percent"`
+	MonthlyVolumeThreshold *float64  `json:"monthly_volume_threshold"`
+	Active                 bool      `json:"active"`
+	CreatedAt              time.Time `json:"created_at"`
+	UpdatedAt              time.Time `json:"updated_at"`
+}
+
+// ApplyDiscount menghitung harga efektif setelah diskon tier, dibulatkan ke 2 desimal
+func (t *PriceTier) ApplyDiscount(price float64) float64 {
+	if t == nil || t.DiscountPercent <= 0 {
+		return price
+	}
+	return math.Round(price*(1-t.DiscountPercent/100)*100) / 100
+}
+
+// AssignPriceTierRequest adalah payload admin untuk mengubah tier user, null untuk menghapus tier
+type AssignPriceTierRequest struct {
+	TierId *int `json:"tier_id"`
+}
+
+// UserPriceTierSummary menunjukkan tier user saat ini dan progres volume bulan berjalan
+type UserPriceTierSummary struct {
+	Tier          *PriceTier `json:"tier"`
+	MonthlyVolume float64    `json:"monthly_volume"`
+	NextTier      *PriceTier `json:"next_tier"`
+}
+
+// PriceTierRequest adalah payload admin untuk membuat atau mengubah tier
+type PriceTierRequest struct {
+	Name                   string   `json:"name"`
+	DiscountPercent        float64  `json:"discount_percent"`
+	MonthlyVolumeThreshold *float64 `json:"monthly_volume_threshold"`
+	Active                 *bool    `json:"active"`
+}
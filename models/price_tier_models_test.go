@@ -0,0 +1,28 @@
+package models
+
+import "testing"
+
+func TestPriceTierApplyDiscount(t *testing.T) {
+	tests := []struct {
+		name  string
+		tier  *PriceTier
+		price float64
+		want  float64
+	}{
+		{name: "nil tier", tier: nil, price: 12500, want: 12500},
+		{name: "zero discount", tier: &PriceTier{DiscountPercent: 0}, price: 12500, want: 12500},
+		{name: "negative discount", tier: &PriceTier{DiscountPercent: -5}, price: 12500, want: 12500},
+		{name: "ten percent", tier: &PriceTier{DiscountPercent: 10}, price: 12500, want: 11250},
+		{name: "rounded to two decimals", tier: &PriceTier{DiscountPercent: 7.5}, price: 999.99, want: 924.99},
+		{name: "full discount", tier: &PriceTier{DiscountPercent: 100}, price: 12500, want: 0},
+		{name: "zero price", tier: &PriceTier{DiscountPercent: 15}, price: 0, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.tier.ApplyDiscount(tt.price); got != tt.want {
+				t.Fatalf("ApplyDiscount(%v) = %v, want %v", tt.price, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// PricingRule menentukan markup harga jual. Service, Country dan Operator kosong berarti berlaku untuk semua.
+type PricingRule struct {
+	Id            int       `json:"id"`
+	Name          string    `json:"name"`
+	Service       string    `json:"service"`
+	Country       string    `json:"country"`
+	Operator      string    `json:"operator"`
+	Priority      int       `json:"priority"`
+	MarkupPercent float64   `json:"markup_percent"`
+	MarkupFixed   float64   `json:"markup_fixed"`
+	MinMargin     float64   `json:"min_margin"`
+	RoundTo       float64   `json:"round_to"`
+	Active        bool      `json:"active"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// PricingDryRunResponse menunjukkan efek perubahan rule terhadap harga katalog
+type PricingDryRunResponse struct {
+	ExchangeRate   float64               `json:"exchange_rate"`
+	TotalProducts  int                   `json:"total_products"`
+	ChangedCount   int                   `json:"changed_count"`
+	IncreasedCount int                   `json:"increased_count"`
+	DecreasedCount int                   `json:"decreased_count"`
+	PinnedCount    int                   `json:"pinned_count"`
+	DisabledCount  int                   `json:"disabled_count"`
+	Changes        []PricingDryRunChange `json:"changes"`
+}
+
+type PricingDryRunChange struct {
+	Service      string  `json:"service"`
+	Country      string  `json:"country"`
+	Operator     string  `json:"operator"`
+	PriceDefault float64 `json:"price_default"`
+	CurrentPrice float64 `json:"current_price"`
+	NewPrice     float64 `json:"new_price"`
+	RuleId       *int    `json:"rule_id"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// ProductOverride adalah kurasi admin di atas SimProduct. Country/Operator kosong berarti wildcard,
+// field nil berarti tidak di-override.
+type ProductOverride struct {
+	Id          int       `json:"id"`
+	Service     string    `json:"service"`
+	Country     string    `json:"country"`
+	Operator    string    `json:"operator"`
+	Enabled     *bool     `json:"enabled"`
+	PinnedPrice *float64  `json:"pinned_price"`
+	DisplayName *string   `json:"display_name"`
+	IconURL     *string   `json:"icon_url"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// ResolvedOverride adalah gabungan override yang berlaku untuk satu produk
+type ResolvedOverride struct {
+	Enabled     bool     `json:"enabled"`
+	PinnedPrice *float64 `json:"pinned_price"`
+	DisplayName string   `json:"display_name"`
+	IconURL     *string  `json:"icon_url"`
+}
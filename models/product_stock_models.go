@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// ProductStockSnapshot adalah stok satu produk pada satu waktu (dari sync atau live lookup)
+type ProductStockSnapshot struct {
+	Service    string    `json:"service"`
+	Country    string    `json:"country"`
+	Operator   string    `json:"operator"`
+	Qty        int       `json:"qty"`
+	Source     string    `json:"source"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+// AvailabilityPoint adalah ringkasan stok per jam
+type AvailabilityPoint struct {
+	Hour    time.Time `json:"hour"`
+	MinQty  int       `json:"min_qty"`
+	MaxQty  int       `json:"max_qty"`
+	AvgQty  float64   `json:"avg_qty"`
+	Samples int       `json:"samples"`
+}
+
+// AvailabilityTrend adalah tren stok produk dalam rentang waktu tertentu
+type AvailabilityTrend struct {
+	Service      string              `json:"service"`
+	Country      string              `json:"country"`
+	Operator     string              `json:"operator"`
+	CurrentStock int                 `json:"current_stock"`
+	Since        time.Time           `json:"since"`
+	InStockRatio float64             `json:"in_stock_ratio"`
+	Points       []AvailabilityPoint `json:"points"`
+}
@@ -0,0 +1,19 @@
+package models
+
+// Role user, disimpan di kolom users.role dan ikut di claims JWT
+const (
+	RoleCustomer = "customer"
+	RoleReseller = "reseller"
+	RoleSupport  = "support"
+	RoleAdmin    = "admin"
+)
+
+// ValidRole mengecek apakah role dikenal
+func ValidRole(role string) bool {
+	switch role {
+	case RoleCustomer, RoleReseller, RoleSupport, RoleAdmin:
+		return true
+	default:
+		return false
+	}
+}
@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 type SimProduct struct {
 	Id           int     `json:"id"`
 	Country      string  `json:"country"`
@@ -7,4 +9,33 @@ type SimProduct struct {
 	Operator     string  `json:"operator"`
 	PriceDefault float64 `json:"price_default"`
 	PriceSell    float64 `json:"price_sell"`
+	Available    bool    `json:"available"`
+	Stock        int     `json:"stock"`
+}
+
+// SyncSummary adalah ringkasan hasil sinkronisasi katalog dari 5sim
+type SyncSummary struct {
+	Added      int       `json:"added"`
+	Updated    int       `json:"updated"`
+	Removed    int       `json:"removed"`
+	Unchanged  int       `json:"unchanged"`
+	Errors     int       `json:"errors"`
+	ErrorLogs  []string  `json:"error_logs,omitempty"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+}
+
+// SyncRun mencatat satu kali eksekusi sinkronisasi katalog
+type SyncRun struct {
+	Id           int        `json:"id"`
+	Trigger      string     `json:"trigger"`
+	Status       string     `json:"status"`
+	Added        int        `json:"added"`
+	Updated      int        `json:"updated"`
+	Removed      int        `json:"removed"`
+	Unchanged    int        `json:"unchanged"`
+	Errors       int        `json:"errors"`
+	ErrorMessage *string    `json:"error_message"`
+	StartedAt    time.Time  `json:"started_at"`
+	FinishedAt   *time.Time `json:"finished_at"`
 }
@@ -7,9 +7,28 @@ type User struct {
 	ID        uint      `json:"id"`
 	Username  string    `json:"username"`
 	Email     string    `json:"email"`
-	Password  string    `json:"password"`
+	Password  string    `json:"-"`
+	Role      string    `json:"role"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	// hanya hash token reset password yang disimpan, token aslinya dikirim lewat email
+	PasswordResetTokenHash *string    `json:"-"`
+	PasswordResetExpiresAt *time.Time `json:"-"`
+
+	// user belum boleh order sebelum email diverifikasi
+	EmailVerifiedAt            *time.Time `json:"email_verified_at"`
+	EmailVerificationTokenHash *string    `json:"-"`
+	EmailVerificationExpiresAt *time.Time `json:"-"`
+
+	// email baru yang menunggu verifikasi, email lama tetap dipakai sampai diverifikasi
+	PendingEmail *string    `json:"pending_email,omitempty"`
+	DeletedAt    *time.Time `json:"-"`
+
+	Balance         float64    `json:"balance"`
+	SuspendedAt     *time.Time `json:"suspended_at"`
+	SuspendedReason *string    `json:"suspended_reason,omitempty"`
+	PriceTierId     *int       `json:"price_tier_id"`
 }
 
 // Register user payload untuk pendaftaran pengguna baru
@@ -33,8 +52,87 @@ type TokenResponse struct {
 
 // User profile resposne untuk detail profile pengguna
 type UserProfileResponse struct {
-	ID        uint      `json:"id"`
-	Username  string    `json:"username"`
-	Email     string    `json:"email"`
-	CreatedAt time.Time `json:"created_at"`
+	ID            uint      `json:"id"`
+	Username      string    `json:"username"`
+	Email         string    `json:"email"`
+	EmailVerified bool      `json:"email_verified"`
+	PendingEmail  *string   `json:"pending_email,omitempty"`
+	Role          string    `json:"role"`
+	Balance       float64   `json:"balance"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// Refresh token request payload untuk endpoint /auth/refresh
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// RefreshToken adalah refresh token yang tersimpan (hanya hash-nya) per user.
+// Token dalam satu family berasal dari satu login dan saling menggantikan saat rotasi.
+type RefreshToken struct {
+	Id        int64      `json:"id"`
+	UserId    uint       `json:"user_id"`
+	FamilyId  string     `json:"family_id"`
+	TokenHash string     `json:"-"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// ClientInfo adalah informasi perangkat yang dicatat untuk setiap sesi login
+type ClientInfo struct {
+	IPAddress string
+	UserAgent string
+}
+
+// UserSession adalah satu sesi login (satu family refresh token) milik user
+type UserSession struct {
+	Id         int64      `json:"id"`
+	UserId     uint       `json:"-"`
+	FamilyId   string     `json:"-"`
+	UserAgent  string     `json:"user_agent"`
+	IPAddress  string     `json:"ip_address"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt time.Time  `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"-"`
+	Current    bool       `json:"current"`
+}
+
+// Forgot password request payload untuk endpoint /auth/forgot-password
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+// Reset password request payload untuk endpoint /auth/reset-password
+type ResetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+// Verify email request payload untuk endpoint /auth/verify-email
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
+// Update profile request payload untuk endpoint PATCH /auth/profile
+type UpdateProfileRequest struct {
+	Username string `json:"username"`
+}
+
+// Change email request payload, email baru baru aktif setelah diverifikasi
+type ChangeEmailRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// Change password request payload untuk endpoint /auth/change-password
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+// Delete account request payload, password dibutuhkan sebagai konfirmasi
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
 }
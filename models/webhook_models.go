@@ -0,0 +1,131 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// Event webhook keluar untuk reseller
+const (
+	WebhookEventOrderNumberAssigned = "order.number_assigned"
+	WebhookEventOrderSMSReceived    = "order.sms_received"
+	WebhookEventOrderCancelled      = "order.cancelled"
+	WebhookEventOrderRefunded       = "order.refunded"
+	// WebhookEventPing hanya dikirim lewat endpoint test-ping, tidak bisa di-subscribe
+	WebhookEventPing = "ping"
+)
+
+// WebhookEvents adalah semua event yang bisa di-subscribe endpoint
+var WebhookEvents = []string{
+	WebhookEventOrderNumberAssigned,
+	WebhookEventOrderSMSReceived,
+	WebhookEventOrderCancelled,
+	WebhookEventOrderRefunded,
+}
+
+// ValidWebhookEvent mengecek apakah event dikenal
+func ValidWebhookEvent(event string) bool {
+	return slices.Contains(WebhookEvents, event)
+}
+
+// Status pengiriman webhook
+const (
+	WebhookDeliveryPending = "pending"
+	WebhookDeliverySuccess = "success"
+	WebhookDeliveryFailed  = "failed"
+)
+
+// Header yang dikirim bersama payload webhook
+const (
+	WebhookHeaderEvent     = "X-Webhook-Event"
+	WebhookHeaderId        = "X-Webhook-Id"
+	WebhookHeaderTimestamp = "X-Webhook-Timestamp"
+	WebhookHeaderSignature = "X-Webhook-Signature"
+)
+
+// WebhookEndpoint adalah URL milik user yang menerima event order.
+// Events kosong berarti menerima semua event.
+type WebhookEndpoint struct {
+	Id          int64     `json:"id"`
+	UserId      uint      `json:"user_id"`
+	URL         string    `json:"url"`
+	Description *string   `json:"description"`
+	Secret      string    `json:"-"`
+	Events      []string  `json:"events"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Subscribed mengecek apakah endpoint menerima event tertentu
+func (w *WebhookEndpoint) Subscribed(event string) bool {
+	return len(w.Events) == 0 || slices.Contains(w.Events, event)
+}
+
+// WebhookDelivery adalah satu event yang dikirim ke satu endpoint beserta hasil percobaan terakhir
+type WebhookDelivery struct {
+	Id             int64      `json:"id"`
+	EndpointId     int64      `json:"endpoint_id"`
+	EventId        string     `json:"event_id"`
+	EventType      string     `json:"event_type"`
+	Payload        string     `json:"payload"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at"`
+	LastStatusCode *int       `json:"last_status_code"`
+	LastError      *string    `json:"last_error"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// WebhookPayload adalah body JSON yang dikirim ke endpoint
+type WebhookPayload struct {
+	Id        string    `json:"id"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      any       `json:"data"`
+}
+
+// WebhookOrderData adalah data order yang dikirim di event order
+type WebhookOrderData struct {
+	OrderId      int        `json:"order_id"`
+	InvoiceId    string     `json:"invoice_id"`
+	Service      string     `json:"service"`
+	Country      string     `json:"country"`
+	Operator     string     `json:"operator"`
+	Price        float64    `json:"price"`
+	Status       string     `json:"status"`
+	PhoneNumber  *string    `json:"phone_number"`
+	OTP          *string    `json:"otp"`
+	RefundedAt   *time.Time `json:"refunded_at,omitempty"`
+	RefundReason *string    `json:"refund_reason,omitempty"`
+}
+
+// NewWebhookOrderData membangun data event dari order
+func NewWebhookOrderData(order *SimOrder) WebhookOrderData {
+	return WebhookOrderData{
+		OrderId:      order.Id,
+		InvoiceId:    order.InvoiceId,
+		Service:      order.Service,
+		Country:      order.Country,
+		Operator:     order.Operator,
+		Price:        order.PriceSell,
+		Status:       order.Status,
+		PhoneNumber:  order.PhoneNumber,
+		OTP:          order.OTP,
+		RefundedAt:   order.RefundedAt,
+		RefundReason: order.RefundReason,
+	}
+}
+
+type CreateWebhookEndpointRequest struct {
+	URL         string   `json:"url"`
+	Description string   `json:"description"`
+	Events      []string `json:"events"`
+}
+
+// CreateWebhookEndpointResponse berisi secret untuk verifikasi signature, hanya dikembalikan sekali
+type CreateWebhookEndpointResponse struct {
+	Endpoint WebhookEndpoint `json:"endpoint"`
+	Secret   string          `json:"secret"`
+}
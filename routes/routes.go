@@ -3,31 +3,187 @@ package routes
 import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/imnzr/sim-service-project/internal/controller"
+	"github.com/imnzr/sim-service-project/internal/middleware"
+	"github.com/imnzr/sim-service-project/models"
 )
 
-func SetupUserRoutes(app *fiber.App, userControlller controller.UserController, authMiddleware fiber.Handler) {
+// SetupAdminGroup membuat group /admin untuk route operasional.
+// Support bisa membaca data, perubahan hanya untuk admin (lihat adminOnly).
+func SetupAdminGroup(app *fiber.App, authMiddleware fiber.Handler) fiber.Router {
+	return app.Group("/admin", authMiddleware, middleware.RequireRole(models.RoleAdmin, models.RoleSupport))
+}
+
+// adminOnly dipasang pada route di group /admin yang mengubah data
+var adminOnly = middleware.RequireRole(models.RoleAdmin)
+
+// profileReadMiddleware sama dengan authMiddleware tetapi juga menerima API key dengan scope profile:read
+func SetupUserRoutes(app *fiber.App, userControlller controller.UserController, authMiddleware, profileReadMiddleware fiber.Handler) {
 	authGroup := app.Group("/auth")
 	{
 		authGroup.Post("/register", userControlller.Register)
 		authGroup.Post("/login", userControlller.Login)
-		authGroup.Get("/profile", authMiddleware, userControlller.GetProfile)
+		authGroup.Post("/refresh", userControlller.RefreshToken)
+		authGroup.Post("/forgot-password", userControlller.ForgotPassword)
+		authGroup.Post("/reset-password", userControlller.ResetPassword)
+		authGroup.Post("/verify-email", userControlller.VerifyEmail)
+		authGroup.Post("/resend-verification", authMiddleware, userControlller.ResendVerification)
+		authGroup.Get("/profile", profileReadMiddleware, userControlller.GetProfile)
+		authGroup.Patch("/profile", authMiddleware, userControlller.UpdateProfile)
+		authGroup.Post("/change-email", authMiddleware, userControlller.ChangeEmail)
+		authGroup.Post("/change-password", authMiddleware, userControlller.ChangePassword)
+		authGroup.Delete("/account", authMiddleware, userControlller.DeleteAccount)
+		authGroup.Post("/logout", authMiddleware, userControlller.Logout)
+		authGroup.Post("/logout-all", authMiddleware, userControlller.LogoutAll)
+		authGroup.Get("/sessions", authMiddleware, userControlller.ListSessions)
 	}
 }
 
-func SetupProductRoutes(app *fiber.App, productController controller.ProductController, authMiddleware fiber.Handler) {
+func SetupProductRoutes(app *fiber.App, adminGroup fiber.Router, productController controller.ProductController) {
 	productGroup := app.Group("/product")
 	{
 		productGroup.Get("/services", productController.GetProductAvailable)
-		productGroup.Post("/sync-services", productController.SyncFromSimServices)
 		// purchase
 		// status order
 		// order otp
 	}
+
+	adminProductGroup := adminGroup.Group("/product")
+	{
+		adminProductGroup.Post("/sync-services", adminOnly, productController.SyncFromSimServices)
+		adminProductGroup.Get("/sync-runs/latest", productController.GetLatestSyncRun)
+		adminProductGroup.Get("/price-history", productController.GetPriceHistory)
+	}
 }
 
-func SetupSimOrderRoutes(app *fiber.App, controller controller.OrderController, authMiddleware fiber.Handler) {
+func SetupSimOrderRoutes(app *fiber.App, controller controller.OrderController, authMiddleware, verifiedMiddleware fiber.Handler) {
 	orderGroup := app.Group("/sim-order")
-	orderGroup.Post("/create", authMiddleware, controller.CreateOrder)
+	orderGroup.Post("/create", authMiddleware, verifiedMiddleware, controller.CreateOrder)
 	// orderGroup.Get("/status/:orderId", authMiddleware, controller.CheckOrderServiceStatus)
 	orderGroup.Post("/webhook", controller.HandleWebhook)
 }
+
+func SetupExchangeRateRoutes(app *fiber.App, adminGroup fiber.Router, exchangeRateController controller.ExchangeRateController) {
+	rateGroup := app.Group("/exchange-rate")
+	{
+		rateGroup.Get("/current", exchangeRateController.GetCurrentRate)
+	}
+
+	adminRateGroup := adminGroup.Group("/exchange-rate", adminOnly)
+	{
+		adminRateGroup.Post("/refresh", exchangeRateController.RefreshRate)
+		adminRateGroup.Post("/manual", exchangeRateController.SetManualRate)
+		adminRateGroup.Delete("/manual", exchangeRateController.ClearManualRate)
+	}
+}
+
+func SetupPricingRoutes(adminGroup fiber.Router, pricingController controller.PricingController) {
+	pricingGroup := adminGroup.Group("/pricing-rules")
+	{
+		pricingGroup.Get("/", pricingController.ListRules)
+		pricingGroup.Post("/", adminOnly, pricingController.CreateRule)
+		pricingGroup.Post("/dry-run", pricingController.DryRun)
+		pricingGroup.Get("/:id", pricingController.GetRule)
+		pricingGroup.Put("/:id", adminOnly, pricingController.UpdateRule)
+		pricingGroup.Delete("/:id", adminOnly, pricingController.DeleteRule)
+	}
+}
+
+// SetupCatalogRoutes: katalog publik, caller yang login juga melihat harga efektif tier-nya
+func SetupCatalogRoutes(app *fiber.App, catalogController controller.CatalogController, optionalAuthMiddleware fiber.Handler) {
+	catalogGroup := app.Group("/product/catalog", optionalAuthMiddleware)
+	{
+		catalogGroup.Get("/search", catalogController.SearchProducts)
+		catalogGroup.Get("/countries", catalogController.ListCountries)
+		catalogGroup.Get("/countries/:country/services", catalogController.ListServices)
+		catalogGroup.Get("/countries/:country/services/:service/operators", catalogController.ListOperators)
+		catalogGroup.Get("/countries/:country/services/:service/operators/:operator/availability", catalogController.AvailabilityTrend)
+	}
+}
+
+func SetupProductOverrideRoutes(adminGroup fiber.Router, overrideController controller.ProductOverrideController) {
+	overrideGroup := adminGroup.Group("/product-overrides")
+	{
+		overrideGroup.Get("/", overrideController.ListOverrides)
+		overrideGroup.Put("/", adminOnly, overrideController.UpsertOverride)
+		overrideGroup.Delete("/:id", adminOnly, overrideController.DeleteOverride)
+	}
+}
+
+// SetupAdminOrderRoutes: support boleh melihat order dan recheck status ke 5sim (hanya menyamakan status),
+// retry pembelian dan refund hanya untuk admin
+func SetupAdminOrderRoutes(adminGroup fiber.Router, adminOrderController controller.AdminOrderController) {
+	orderGroup := adminGroup.Group("/orders")
+	{
+		orderGroup.Get("/", adminOrderController.SearchOrders)
+		orderGroup.Get("/:id", adminOrderController.GetOrder)
+		orderGroup.Post("/:id/recheck", adminOrderController.RecheckOrder)
+		orderGroup.Post("/:id/retry", adminOnly, adminOrderController.RetryFulfilment)
+		orderGroup.Post("/:id/refund", adminOnly, adminOrderController.RefundOrder)
+	}
+}
+
+// SetupAdminUserRoutes: support boleh melihat data user, perubahan hanya untuk admin
+func SetupAdminUserRoutes(adminGroup fiber.Router, adminUserController controller.AdminUserController) {
+	userGroup := adminGroup.Group("/users")
+	{
+		userGroup.Get("/", adminUserController.SearchUsers)
+		userGroup.Get("/:id", adminUserController.GetUser)
+		userGroup.Get("/:id/orders", adminUserController.GetUserOrders)
+		userGroup.Get("/:id/payments", adminUserController.GetUserPayments)
+		userGroup.Post("/:id/suspend", adminOnly, adminUserController.SuspendUser)
+		userGroup.Post("/:id/unsuspend", adminOnly, adminUserController.UnsuspendUser)
+		userGroup.Post("/:id/balance-adjustments", adminOnly, adminUserController.AdjustBalance)
+	}
+}
+
+// SetupAuditLogRoutes: audit log berisi data pribadi dan aksi sensitif, hanya untuk admin
+func SetupAuditLogRoutes(adminGroup fiber.Router, auditLogController controller.AuditLogController) {
+	auditGroup := adminGroup.Group("/audit-logs", adminOnly)
+	{
+		auditGroup.Get("/", auditLogController.SearchAuditLogs)
+		auditGroup.Get("/verify", auditLogController.VerifyAuditChain)
+	}
+}
+
+// SetupAPIKeyRoutes: API key dikelola dengan JWT, API key tidak bisa membuat API key baru
+func SetupAPIKeyRoutes(app *fiber.App, apiKeyController controller.APIKeyController, authMiddleware, verifiedMiddleware fiber.Handler) {
+	apiKeyGroup := app.Group("/api-keys", authMiddleware, verifiedMiddleware, middleware.RequireRole(models.RoleReseller, models.RoleAdmin))
+	{
+		apiKeyGroup.Get("/", apiKeyController.ListAPIKeys)
+		apiKeyGroup.Post("/", apiKeyController.CreateAPIKey)
+		apiKeyGroup.Delete("/:id", apiKeyController.RevokeAPIKey)
+	}
+}
+
+// SetupPriceTierRoutes: support boleh melihat tier, perubahan tier dan assign ke user hanya untuk admin
+func SetupPriceTierRoutes(app *fiber.App, adminGroup fiber.Router, priceTierController controller.PriceTierController, authMiddleware fiber.Handler) {
+	app.Get("/auth/price-tier", authMiddleware, priceTierController.GetMyTier)
+
+	tierGroup := adminGroup.Group("/price-tiers")
+	{
+		tierGroup.Get("/", priceTierController.ListTiers)
+		tierGroup.Post("/", adminOnly, priceTierController.CreateTier)
+		tierGroup.Get("/:id", priceTierController.GetTier)
+		tierGroup.Put("/:id", adminOnly, priceTierController.UpdateTier)
+		tierGroup.Delete("/:id", adminOnly, priceTierController.DeleteTier)
+	}
+	adminGroup.Put("/users/:id/price-tier", adminOnly, priceTierController.AssignUserTier)
+}
+
+// SetupWebhookRoutes: webhook dikelola dengan JWT seperti API key, hanya untuk reseller dan admin
+func SetupWebhookRoutes(app *fiber.App, webhookController controller.WebhookController, authMiddleware, verifiedMiddleware fiber.Handler) {
+	webhookGroup := app.Group("/webhooks", authMiddleware, verifiedMiddleware, middleware.RequireRole(models.RoleReseller, models.RoleAdmin))
+	{
+		webhookGroup.Get("/", webhookController.ListEndpoints)
+		webhookGroup.Post("/", webhookController.CreateEndpoint)
+		webhookGroup.Delete("/:id", webhookController.DeleteEndpoint)
+		webhookGroup.Get("/:id/deliveries", webhookController.ListDeliveries)
+		webhookGroup.Post("/:id/ping", webhookController.PingEndpoint)
+	}
+}
+
+// SetupBulkOrderRoutes: bulk order dibayar dengan saldo, hanya untuk reseller dan admin.
+// authMiddleware menerima API key dengan scope orders:create.
+func SetupBulkOrderRoutes(app *fiber.App, bulkOrderController controller.BulkOrderController, authMiddleware, verifiedMiddleware fiber.Handler) {
+	app.Post("/sim-order/bulk", authMiddleware, verifiedMiddleware, middleware.RequireRole(models.RoleReseller, models.RoleAdmin), bulkOrderController.CreateBulkOrder)
+}